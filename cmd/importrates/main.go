@@ -0,0 +1,48 @@
+// Command importrates loads exchange rates into the local rate store.
+//
+// It accepts the ECB eurofxref XML files, the wide ECB CSV layout
+// (Date,USD,JPY,...) and a long CSV layout with a date,currency,rate header.
+// All rates are units of currency per one EUR.
+//
+//	go run ./cmd/importrates -file eurofxref-hist.xml
+package main
+
+import (
+	"flag"
+	"log"
+	"os"
+
+	"statistic_service/internal/config"
+	"statistic_service/internal/db"
+	"statistic_service/internal/repository"
+	"statistic_service/internal/service"
+)
+
+func main() {
+	file := flag.String("file", "", "path to a CSV or ECB XML rates file")
+	flag.Parse()
+	if *file == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := config.LoadConfig()
+	database := db.Connect(cfg.DBURL)
+
+	f, err := os.Open(*file)
+	if err != nil {
+		log.Fatalf("Failed to open rates file: %v", err)
+	}
+	defer f.Close()
+
+	currencyService := service.NewCurrencyService(
+		repository.NewRateRepository(database),
+		repository.NewUserRepository(database),
+		repository.NewTransactionRepository(database),
+	)
+	n, err := currencyService.ImportRates(f)
+	if err != nil {
+		log.Fatalf("Failed to import rates: %v", err)
+	}
+	log.Printf("Imported %d exchange rates from %s", n, *file)
+}
@@ -1,6 +1,9 @@
 package main
 
 import (
+	"context"
+	"log"
+
 	_ "statistic_service/docs" // Import the generated docs
 	"statistic_service/internal/config"
 	"statistic_service/internal/db"
@@ -9,6 +12,8 @@ import (
 	"statistic_service/internal/middleware"
 	"statistic_service/internal/repository"
 	"statistic_service/internal/service"
+	"statistic_service/pkg/mail"
+	"statistic_service/pkg/storage"
 
 	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
@@ -43,13 +48,51 @@ func main() {
 	// Initialize repositories, services, handlers, and middleware
 	userRepo := repository.NewUserRepository(database)
 	txRepo := repository.NewTransactionRepository(database)
+	categoryRepo := repository.NewCategoryRepository(database)
+	rateRepo := repository.NewRateRepository(database)
+	accountRepo := repository.NewAccountRepository(database)
+	recurringRepo := repository.NewRecurringRepository(database)
+	budgetRepo := repository.NewBudgetRepository(database)
+	ruleRepo := repository.NewRuleRepository(database)
+	tagRepo := repository.NewTagRepository(database)
+	attachmentRepo := repository.NewAttachmentRepository(database)
+	idempotencyRepo := repository.NewIdempotencyRepository(database)
+	passwordResetRepo := repository.NewPasswordResetRepository(database)
+	verificationRepo := repository.NewVerificationRepository(database)
+
+	blobs := newStorage(cfg)
+	mailer := newMailer(cfg)
 
 	authService := service.NewAuthService(userRepo, cfg.JWTSecret, logger.SetupLogger(cfg.ServiceLogFile))
-	txService := service.NewTransactionService(txRepo)
+	verificationService := service.NewVerificationService(userRepo, verificationRepo, mailer, cfg.JWTSecret, cfg.EmailVerificationTTL, cfg.VerificationResendInterval, cfg.VerifyEmailURL, logger.SetupLogger(cfg.ServiceLogFile))
+	authService.SetVerification(verificationService)
+	passwordService := service.NewPasswordService(userRepo, passwordResetRepo, mailer, cfg.JWTSecret, cfg.PasswordResetTTL, cfg.PasswordResetInterval, cfg.PasswordResetURL, logger.SetupLogger(cfg.ServiceLogFile))
+	currencyService := service.NewCurrencyService(rateRepo, userRepo, txRepo)
+	txService := service.NewTransactionService(txRepo, categoryRepo, userRepo, currencyService, accountRepo, ruleRepo, tagRepo, blobs, cfg.DuplicateWindow)
+	accountService := service.NewAccountService(accountRepo, txRepo, userRepo, currencyService)
+	categoryService := service.NewCategoryService(categoryRepo)
+	tagService := service.NewTagService(tagRepo)
+	attachmentService := service.NewAttachmentService(attachmentRepo, txRepo, blobs, cfg.MaxAttachmentSize)
+	importService := service.NewImportService(txRepo, txService, categoryRepo, ruleRepo)
+	budgetService := service.NewBudgetService(budgetRepo, categoryRepo, txService)
+	ruleService := service.NewRuleService(ruleRepo, categoryRepo, accountRepo, tagRepo, txRepo, txService)
+	recurringService := service.NewRecurringService(recurringRepo, txRepo, txService, logger.SetupLogger(cfg.ServiceLogFile))
+	idempotencyService := service.NewIdempotencyService(idempotencyRepo, cfg.IdempotencyTTL, logger.SetupLogger(cfg.ServiceLogFile))
 
 	authHandler := handler.NewAuthHandler(authService, logger.SetupLogger(cfg.HandlerLogFile))
+	passwordHandler := handler.NewPasswordHandler(passwordService, logger.SetupLogger(cfg.HandlerLogFile))
+	verificationHandler := handler.NewVerificationHandler(verificationService, logger.SetupLogger(cfg.HandlerLogFile))
 
-	authMiddleware := middleware.JWTAuth(cfg.JWTSecret)
+	authMiddleware := middleware.JWTAuth(cfg.JWTSecret, authService)
+	for _, f := range cfg.RestrictUnverified {
+		switch f {
+		case middleware.FeatureExport, middleware.FeatureImport, middleware.FeatureAttachments:
+		default:
+			log.Fatalf("Unknown feature %q in RESTRICT_UNVERIFIED, use export, import, attachments or none", f)
+		}
+	}
+	verified := middleware.VerificationPolicy(verificationService, cfg.RestrictUnverified)
+	idempotent := middleware.Idempotency(idempotencyService, logger.SetupLogger(cfg.HandlerLogFile))
 
 	txHandler := handler.NewTransactionHandler(txService, logger.SetupLogger(cfg.HandlerLogFile))
 
@@ -59,34 +102,185 @@ func main() {
 
 	timelineHandler := handler.NewTimelineHandler(txService, logger.SetupLogger(cfg.HandlerLogFile))
 
+	categoryHandler := handler.NewCategoryHandler(categoryService, logger.SetupLogger(cfg.HandlerLogFile))
+
+	currencyHandler := handler.NewCurrencyHandler(currencyService, logger.SetupLogger(cfg.HandlerLogFile))
+
+	accountHandler := handler.NewAccountHandler(accountService, logger.SetupLogger(cfg.HandlerLogFile))
+
+	recurringHandler := handler.NewRecurringHandler(recurringService, logger.SetupLogger(cfg.HandlerLogFile))
+
+	budgetHandler := handler.NewBudgetHandler(budgetService, logger.SetupLogger(cfg.HandlerLogFile))
+
+	importHandler := handler.NewImportHandler(importService, logger.SetupLogger(cfg.HandlerLogFile))
+
+	exportHandler := handler.NewExportHandler(txService, logger.SetupLogger(cfg.HandlerLogFile))
+
+	ruleHandler := handler.NewRuleHandler(ruleService, logger.SetupLogger(cfg.HandlerLogFile))
+
+	tagHandler := handler.NewTagHandler(tagService, logger.SetupLogger(cfg.HandlerLogFile))
+
+	attachmentHandler := handler.NewAttachmentHandler(attachmentService, cfg.MaxAttachmentSize, logger.SetupLogger(cfg.HandlerLogFile))
+
+	// Create due recurring transactions in the background
+	recurringService.Start(context.Background(), cfg.RecurringInterval)
+	// Purge transactions that have been in the trash longer than the retention
+	service.StartPurge(context.Background(), txService, cfg.TrashRetention, cfg.PurgeInterval, logger.SetupLogger(cfg.ServiceLogFile))
+	// Remove idempotency keys past their TTL
+	idempotencyService.Start(context.Background(), cfg.PurgeInterval)
+
 	// Set up Gin router
 	r := gin.Default()
+	r.Use(middleware.RequestID())
 
 	// Auth
 	r.POST("/register", authHandler.Register)
 	r.POST("/login", authHandler.Login)
 	r.POST("/refresh", authHandler.Refresh)
+	r.POST("/password/forgot", passwordHandler.Forgot)
+	r.POST("/password/reset", passwordHandler.Reset)
+	r.GET("/verify-email", verificationHandler.Verify)
+	r.POST("/verify-email/resend", authMiddleware, verificationHandler.Resend)
 	// Protected
+	r.POST("/logout", authMiddleware, authHandler.Logout)
+	r.GET("/sessions", authMiddleware, authHandler.Sessions)
+	r.DELETE("/sessions", authMiddleware, authHandler.RevokeAllSessions)
+	r.DELETE("/sessions/:id", authMiddleware, authHandler.RevokeSession)
 	r.GET("/me", authMiddleware, authHandler.GetProfile)
+	r.PUT("/me/currency", authMiddleware, currencyHandler.SetBaseCurrency)
 
 	// Swagger routes
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
 	// Transactions
-	r.POST("/transactions", authMiddleware, txHandler.Create)
+	r.POST("/transactions", authMiddleware, idempotent, txHandler.Create)
 	r.GET("/transactions", authMiddleware, txHandler.List)
 	r.PUT("/transactions/:id", authMiddleware, txHandler.Update)
 	r.DELETE("/transactions/:id", authMiddleware, txHandler.Delete)
+	r.GET("/transactions/trash", authMiddleware, txHandler.Trash)
+	r.POST("/transactions/:id/restore", authMiddleware, txHandler.Restore)
+	r.GET("/transactions/:id/history", authMiddleware, txHandler.History)
+	r.GET("/transactions/duplicates", authMiddleware, txHandler.Duplicates)
+	r.POST("/transactions/:id/merge", authMiddleware, txHandler.Merge)
+	r.POST("/transactions/:id/attachments", authMiddleware, verified(middleware.FeatureAttachments), attachmentHandler.Upload)
+	r.GET("/transactions/:id/attachments", authMiddleware, attachmentHandler.List)
+	r.GET("/transactions/:id/attachments/:attachment_id", authMiddleware, attachmentHandler.Download)
+	r.DELETE("/transactions/:id/attachments/:attachment_id", authMiddleware, attachmentHandler.Delete)
+	r.DELETE("/transactions/:id/duplicate", authMiddleware, txHandler.Dismiss)
+	r.POST("/transactions/import/csv", authMiddleware, verified(middleware.FeatureImport), idempotent, importHandler.ImportCSV)
+	r.POST("/transactions/import/statement", authMiddleware, verified(middleware.FeatureImport), idempotent, importHandler.ImportStatement)
+
+	// Categories
+	r.POST("/categories", authMiddleware, categoryHandler.Create)
+	r.GET("/categories", authMiddleware, categoryHandler.List)
+	r.PUT("/categories/:id", authMiddleware, categoryHandler.Rename)
+	r.PUT("/categories/:id/parent", authMiddleware, categoryHandler.Move)
+	r.DELETE("/categories/:id", authMiddleware, categoryHandler.Delete)
+
+	// Tags
+	r.POST("/tags", authMiddleware, tagHandler.Create)
+	r.GET("/tags", authMiddleware, tagHandler.List)
+	r.PUT("/tags/:id", authMiddleware, tagHandler.Rename)
+	r.DELETE("/tags/:id", authMiddleware, tagHandler.Delete)
+
+	// Accounts
+	r.POST("/accounts", authMiddleware, accountHandler.Create)
+	r.GET("/accounts", authMiddleware, accountHandler.List)
+	r.PUT("/accounts/:id", authMiddleware, accountHandler.Update)
+	r.DELETE("/accounts/:id", authMiddleware, accountHandler.Delete)
+	r.GET("/accounts/:id/balance", authMiddleware, accountHandler.Balance)
+	r.POST("/transfers", authMiddleware, idempotent, accountHandler.Transfer)
+
+	// Recurring transactions
+	r.POST("/recurring", authMiddleware, recurringHandler.Create)
+	r.GET("/recurring", authMiddleware, recurringHandler.List)
+	r.PUT("/recurring/:id", authMiddleware, recurringHandler.Update)
+	r.DELETE("/recurring/:id", authMiddleware, recurringHandler.Delete)
+
+	// Budgets
+	r.POST("/budgets", authMiddleware, budgetHandler.Create)
+	r.GET("/budgets", authMiddleware, budgetHandler.List)
+	r.GET("/budgets/progress", authMiddleware, budgetHandler.ProgressAll)
+	r.PUT("/budgets/:id", authMiddleware, budgetHandler.Update)
+	r.DELETE("/budgets/:id", authMiddleware, budgetHandler.Delete)
+	r.GET("/budgets/:id/progress", authMiddleware, budgetHandler.Progress)
+
+	// Rules
+	r.POST("/rules", authMiddleware, ruleHandler.Create)
+	r.GET("/rules", authMiddleware, ruleHandler.List)
+	r.PUT("/rules/order", authMiddleware, ruleHandler.Reorder)
+	r.POST("/rules/preview", authMiddleware, ruleHandler.Preview)
+	r.POST("/rules/apply", authMiddleware, ruleHandler.Apply)
+	r.PUT("/rules/:id", authMiddleware, ruleHandler.Update)
+	r.DELETE("/rules/:id", authMiddleware, ruleHandler.Delete)
 
 	// Statistics
 	r.GET("/stats/summary", authMiddleware, statsHandler.Summary)
 	r.GET("/stats/categories", authMiddleware, statsHandler.ByCategory)
+	r.GET("/stats/tags", authMiddleware, statsHandler.ByTag)
 	r.GET("/predict", authMiddleware, predictHandler.Predict)
 
 	r.GET("/stats/timeline", authMiddleware, timelineHandler.Timeline)
 
+	// Export
+	r.GET("/export/transactions", authMiddleware, verified(middleware.FeatureExport), exportHandler.Transactions)
+	r.GET("/export/summary", authMiddleware, verified(middleware.FeatureExport), exportHandler.Summary)
+	r.GET("/export/categories", authMiddleware, verified(middleware.FeatureExport), exportHandler.Categories)
+
 	//Start the server
 	if err := r.Run(":" + cfg.Port); err != nil {
 		appLogger.Fatalf("Failed to start server: %v", err)
 	}
 }
+
+// newStorage opens the configured blob storage for attachments.
+func newStorage(cfg *config.Config) storage.Storage {
+	switch cfg.StorageBackend {
+	case "local":
+		s, err := storage.NewLocal(cfg.StorageDir)
+		if err != nil {
+			log.Fatalf("Could not open attachment storage: %v", err)
+		}
+		return s
+	case "s3":
+		s, err := storage.NewS3(storage.S3Config{
+			Endpoint:  cfg.S3Endpoint,
+			Bucket:    cfg.S3Bucket,
+			Region:    cfg.S3Region,
+			AccessKey: cfg.S3AccessKey,
+			SecretKey: cfg.S3SecretKey,
+		})
+		if err != nil {
+			log.Fatalf("Could not open attachment storage: %v", err)
+		}
+		return s
+	}
+	log.Fatalf("Unknown STORAGE_BACKEND %q, use local or s3", cfg.StorageBackend)
+	return nil
+}
+
+// newMailer opens the configured mail sender.
+func newMailer(cfg *config.Config) mail.Mailer {
+	switch cfg.MailBackend {
+	case "file":
+		m, err := mail.NewFile(cfg.MailFile, cfg.MailFrom)
+		if err != nil {
+			log.Fatalf("Could not open mail file: %v", err)
+		}
+		return m
+	case "smtp":
+		m, err := mail.NewSMTP(mail.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.MailFrom,
+		})
+		if err != nil {
+			log.Fatalf("Could not set up SMTP: %v", err)
+		}
+		return m
+	}
+	log.Fatalf("Unknown MAIL_BACKEND %q, use file or smtp", cfg.MailBackend)
+	return nil
+}
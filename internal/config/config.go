@@ -2,6 +2,9 @@ package config
 
 import (
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,6 +16,61 @@ type Config struct {
 	AppLogFile     string
 	ServiceLogFile string
 	HandlerLogFile string
+	// RecurringInterval is how often the scheduler checks for due recurring transactions.
+	RecurringInterval time.Duration
+	// DuplicateWindow is how far apart two transactions may be created and
+	// still be detected as duplicates.
+	DuplicateWindow time.Duration
+	// TrashRetention is how long deleted transactions stay restorable.
+	TrashRetention time.Duration
+	// PurgeInterval is how often transactions past the retention and expired
+	// idempotency keys are purged.
+	PurgeInterval time.Duration
+	// IdempotencyTTL is how long the response to a request with an
+	// Idempotency-Key is kept for retries.
+	IdempotencyTTL time.Duration
+	// StorageBackend selects where attachments are kept: "local" (default)
+	// or "s3" for an S3-compatible service such as MinIO.
+	StorageBackend string
+	// StorageDir is the root directory of the local backend.
+	StorageDir  string
+	S3Endpoint  string
+	S3Bucket    string
+	S3Region    string
+	S3AccessKey string
+	S3SecretKey string
+	// MaxAttachmentSize is the largest accepted attachment in bytes.
+	MaxAttachmentSize int64
+	// MailBackend selects how emails are sent: "file" (default) appends them
+	// to MailFile, "smtp" sends them through the SMTP server, e.g. MailHog.
+	MailBackend  string
+	MailFile     string
+	MailFrom     string
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	SMTPPassword string
+	// PasswordResetTTL is how long a password reset token can be used.
+	PasswordResetTTL time.Duration
+	// PasswordResetURL is the page that sets a new password; the emailed
+	// link adds the reset token as the token query parameter.
+	PasswordResetURL string
+	// PasswordResetInterval is the least time between two password reset
+	// emails to a user.
+	PasswordResetInterval time.Duration
+	// EmailVerificationTTL is how long the link emailed on registration can
+	// be used.
+	EmailVerificationTTL time.Duration
+	// VerificationResendInterval is the least time between two verification
+	// emails to a user.
+	VerificationResendInterval time.Duration
+	// VerifyEmailURL is the public URL of the verification endpoint used in
+	// the emailed link.
+	VerifyEmailURL string
+	// RestrictUnverified lists the features unavailable to users whose email
+	// is not verified: export, import and attachments. "none" restricts
+	// nothing.
+	RestrictUnverified []string
 }
 
 func LoadConfig() *Config {
@@ -22,11 +80,83 @@ func LoadConfig() *Config {
 	}
 
 	return &Config{
-		DBURL:          os.Getenv("DB_URL"),
-		JWTSecret:      os.Getenv("JWT_SECRET"),
-		Port:           os.Getenv("PORT"),
-		AppLogFile:     os.Getenv("APP_LOG_FILE"),
-		ServiceLogFile: os.Getenv("SERVICE_LOG_FILE"),
-		HandlerLogFile: os.Getenv("HANDLER_LOG_FILE"),
+		DBURL:             os.Getenv("DB_URL"),
+		JWTSecret:         os.Getenv("JWT_SECRET"),
+		Port:              os.Getenv("PORT"),
+		AppLogFile:        os.Getenv("APP_LOG_FILE"),
+		ServiceLogFile:    os.Getenv("SERVICE_LOG_FILE"),
+		HandlerLogFile:    os.Getenv("HANDLER_LOG_FILE"),
+		RecurringInterval: durationEnv("RECURRING_INTERVAL", time.Minute),
+		DuplicateWindow:   durationEnv("DUPLICATE_WINDOW", 24*time.Hour),
+		TrashRetention:    durationEnv("TRASH_RETENTION", 30*24*time.Hour),
+		PurgeInterval:     durationEnv("PURGE_INTERVAL", time.Hour),
+		IdempotencyTTL:    durationEnv("IDEMPOTENCY_TTL", 24*time.Hour),
+		StorageBackend:    stringEnv("STORAGE_BACKEND", "local"),
+		StorageDir:        stringEnv("STORAGE_DIR", "data/attachments"),
+		S3Endpoint:        os.Getenv("S3_ENDPOINT"),
+		S3Bucket:          os.Getenv("S3_BUCKET"),
+		S3Region:          os.Getenv("S3_REGION"),
+		S3AccessKey:       os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey:       os.Getenv("S3_SECRET_KEY"),
+		MaxAttachmentSize: sizeEnv("ATTACHMENT_MAX_SIZE", 10<<20),
+		MailBackend:       stringEnv("MAIL_BACKEND", "file"),
+		MailFile:          stringEnv("MAIL_FILE", "logs/mail.log"),
+		MailFrom:          stringEnv("MAIL_FROM", "Statistic Service <no-reply@localhost>"),
+		SMTPHost:          os.Getenv("SMTP_HOST"),
+		SMTPPort:          int(sizeEnv("SMTP_PORT", 587)),
+		SMTPUsername:      os.Getenv("SMTP_USERNAME"),
+		SMTPPassword:      os.Getenv("SMTP_PASSWORD"),
+		PasswordResetTTL:  durationEnv("PASSWORD_RESET_TTL", time.Hour),
+		PasswordResetURL:  os.Getenv("PASSWORD_RESET_URL"),
+
+		PasswordResetInterval:      durationEnv("PASSWORD_RESET_INTERVAL", time.Minute),
+		EmailVerificationTTL:       durationEnv("EMAIL_VERIFICATION_TTL", 48*time.Hour),
+		VerificationResendInterval: durationEnv("VERIFICATION_RESEND_INTERVAL", time.Minute),
+		VerifyEmailURL:             stringEnv("VERIFY_EMAIL_URL", "http://localhost:8080/verify-email"),
+		RestrictUnverified:         listEnv("RESTRICT_UNVERIFIED", "export"),
+	}
+}
+
+// durationEnv reads a duration such as "30s" or "5m", falling back to def
+// when the variable is unset or invalid.
+func durationEnv(key string, def time.Duration) time.Duration {
+	d, err := time.ParseDuration(os.Getenv(key))
+	if err != nil || d <= 0 {
+		return def
+	}
+	return d
+}
+
+// stringEnv reads a variable, falling back to def when it is unset or empty.
+func stringEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// listEnv reads a comma-separated list, falling back to def when the
+// variable is unset or empty. "none" is an empty list.
+func listEnv(key, def string) []string {
+	v := stringEnv(key, def)
+	if v == "none" {
+		return nil
+	}
+	var list []string
+	for _, item := range strings.Split(v, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// sizeEnv reads a size in bytes, falling back to def when the variable is
+// unset or invalid.
+func sizeEnv(key string, def int64) int64 {
+	n, err := strconv.ParseInt(os.Getenv(key), 10, 64)
+	if err != nil || n <= 0 {
+		return def
 	}
+	return n
 }
@@ -27,7 +27,7 @@ func Connect(url string) *gorm.DB {
 		log.Fatalf("Could not connect to DB: %v", err)
 	}
 
-	err = database.AutoMigrate(&model.User{}, &model.Transaction{}, &model.Category{}, &model.RefreshToken{})
+	err = database.AutoMigrate(&model.User{}, &model.Tag{}, &model.Transaction{}, &model.TransactionSplit{}, &model.Category{}, &model.RefreshToken{}, &model.RevokedSession{}, &model.SecurityEvent{}, &model.PasswordResetToken{}, &model.EmailVerificationToken{}, &model.ExchangeRate{}, &model.Account{}, &model.RecurringTransaction{}, &model.Budget{}, &model.Rule{}, &model.Attachment{}, &model.TransactionVersion{}, &model.IdempotencyKey{})
 	if err != nil {
 		log.Fatalf("Failed to migrate database: %v", err)
 	}
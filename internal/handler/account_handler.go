@@ -0,0 +1,222 @@
+package handler
+
+import (
+	"net/http"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/service"
+	"statistic_service/pkg/money"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+// AccountHandler handles accounts, balances and transfers.
+type AccountHandler struct {
+	svc      service.AccountService
+	validate *validator.Validate
+	logger   *logrus.Logger
+}
+
+// NewAccountHandler creates a new AccountHandler instance.
+func NewAccountHandler(s service.AccountService, logger *logrus.Logger) *AccountHandler {
+	return &AccountHandler{svc: s, validate: validator.New(), logger: logger}
+}
+
+type accountRequest struct {
+	Name           string       `json:"name" validate:"required,max=100"`
+	Type           string       `json:"type" validate:"required,oneof=cash card savings credit"`
+	Currency       string       `json:"currency" validate:"omitempty,len=3"`
+	OpeningBalance money.Amount `json:"opening_balance" swaggertype:"string" example:"100.00"`
+}
+
+type updateAccountRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+	Type string `json:"type" validate:"required,oneof=cash card savings credit"`
+}
+
+type transferRequest struct {
+	FromAccountID string       `json:"from_account_id" validate:"required,uuid"`
+	ToAccountID   string       `json:"to_account_id" validate:"required,uuid"`
+	Amount        money.Amount `json:"amount" swaggertype:"string" example:"50.00"`
+	ToAmount      money.Amount `json:"to_amount" swaggertype:"string" example:"45.90"`
+	Comment       string       `json:"comment"`
+}
+
+// Create godoc
+// @Summary Create an account
+// @Description Creates a cash, card, savings or credit account. The currency defaults to the user's base currency
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param account body accountRequest true "Account details"
+// @Success 201 {object} model.Account
+// @Failure 400 {object} map[string]interface{} "error: validation failed, details: list of errors"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /accounts [post]
+func (h *AccountHandler) Create(c *gin.Context) {
+	var req accountRequest
+	if !bindJSON(c, h.validate, h.logger, &req) {
+		return
+	}
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"userID": userID, "name": req.Name, "type": req.Type}).Info("Creating account")
+	account := &model.Account{Name: req.Name, Type: req.Type, Currency: req.Currency, OpeningBalance: req.OpeningBalance}
+	if err := h.svc.Create(userID, account); err != nil {
+		h.logger.WithError(err).Warn("Failed to create account")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Account created successfully")
+	c.JSON(http.StatusCreated, account)
+}
+
+// List godoc
+// @Summary List accounts
+// @Description Retrieves the accounts of the authenticated user
+// @Tags Accounts
+// @Produce json
+// @Success 200 {array} model.Account
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /accounts [get]
+func (h *AccountHandler) List(c *gin.Context) {
+	userID := c.GetString("userID")
+	h.logger.WithField("userID", userID).Info("Listing accounts")
+	list, err := h.svc.List(userID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list accounts")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithField("count", len(list)).Info("Accounts listed successfully")
+	c.JSON(http.StatusOK, list)
+}
+
+// Update godoc
+// @Summary Update an account
+// @Description Changes the name and type of an account. Currency and opening balance are fixed
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param account body updateAccountRequest true "Account details"
+// @Success 200 {object} model.Account
+// @Failure 400 {object} map[string]interface{} "error: validation failed, details: list of errors"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: account not found"
+// @Security BearerAuth
+// @Router /accounts/{id} [put]
+func (h *AccountHandler) Update(c *gin.Context) {
+	var req updateAccountRequest
+	if !bindJSON(c, h.validate, h.logger, &req) {
+		return
+	}
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"accountID": id, "userID": userID}).Info("Updating account")
+	account, err := h.svc.Update(id, userID, req.Name, req.Type)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to update account")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Account updated successfully")
+	c.JSON(http.StatusOK, account)
+}
+
+// Delete godoc
+// @Summary Delete an account
+// @Description Deletes an account that has no transactions
+// @Tags Accounts
+// @Produce json
+// @Param id path string true "Account ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: account not found"
+// @Failure 409 {object} map[string]string "error: account has transactions"
+// @Security BearerAuth
+// @Router /accounts/{id} [delete]
+func (h *AccountHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"accountID": id, "userID": userID}).Info("Deleting account")
+	if err := h.svc.Delete(id, userID); err != nil {
+		h.logger.WithError(err).Warn("Failed to delete account")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Account deleted successfully")
+	c.Status(http.StatusNoContent)
+}
+
+// Balance godoc
+// @Summary Get account balance
+// @Description Returns the opening balance plus all income minus all expenses of the account up to the given time, transfers included
+// @Tags Accounts
+// @Produce json
+// @Param id path string true "Account ID"
+// @Param at query string false "Point in time in RFC3339 format, defaults to now"
+// @Success 200 {object} service.AccountBalance
+// @Failure 400 {object} map[string]string "error: invalid at"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: account not found"
+// @Security BearerAuth
+// @Router /accounts/{id}/balance [get]
+func (h *AccountHandler) Balance(c *gin.Context) {
+	at, ok := parseAt(c)
+	if !ok {
+		return
+	}
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"accountID": id, "userID": userID, "at": at}).Info("Fetching account balance")
+	balance, err := h.svc.Balance(id, userID, at)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to fetch account balance")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Account balance retrieved successfully")
+	c.JSON(http.StatusOK, balance)
+}
+
+// Transfer godoc
+// @Summary Transfer between accounts
+// @Description Atomically records an expense on the source account and an income on the destination account.
+// @Description Both legs share a transfer ID and are excluded from income and expense statistics. to_amount is required when the accounts have different currencies
+// @Tags Accounts
+// @Accept json
+// @Produce json
+// @Param transfer body transferRequest true "Transfer details"
+// @Param Idempotency-Key header string false "Key that makes retries return the original response; reusing it with a different request gives 422"
+// @Success 201 {object} service.Transfer
+// @Failure 400 {object} map[string]interface{} "error: validation failed"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 422 {object} map[string]string "error: idempotency key was already used with a different request"
+// @Security BearerAuth
+// @Router /transfers [post]
+func (h *AccountHandler) Transfer(c *gin.Context) {
+	var req transferRequest
+	if !bindJSON(c, h.validate, h.logger, &req) {
+		return
+	}
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"userID": userID, "from": req.FromAccountID, "to": req.ToAccountID, "amount": req.Amount}).Info("Creating transfer")
+	transfer, err := h.svc.Transfer(c.Request.Context(), userID, service.TransferInput{
+		FromAccountID: req.FromAccountID,
+		ToAccountID:   req.ToAccountID,
+		Amount:        req.Amount,
+		ToAmount:      req.ToAmount,
+		Comment:       req.Comment,
+	})
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to create transfer")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Transfer created successfully")
+	c.JSON(http.StatusCreated, transfer)
+}
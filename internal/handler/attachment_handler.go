@@ -0,0 +1,151 @@
+package handler
+
+import (
+	"mime"
+	"net/http"
+
+	"statistic_service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// multipartOverhead allows for the form fields and boundaries around an
+// uploaded file.
+const multipartOverhead = 1 << 20
+
+// AttachmentHandler handles files attached to transactions.
+type AttachmentHandler struct {
+	svc service.AttachmentService
+	// maxSize is the largest accepted file in bytes.
+	maxSize int64
+	logger  *logrus.Logger
+}
+
+// NewAttachmentHandler creates a new AttachmentHandler instance.
+func NewAttachmentHandler(s service.AttachmentService, maxSize int64, logger *logrus.Logger) *AttachmentHandler {
+	return &AttachmentHandler{svc: s, maxSize: maxSize, logger: logger}
+}
+
+// Upload godoc
+// @Summary Attach a file to a transaction
+// @Description Stores a receipt or document with an income or expense transaction. JPEG, PNG, GIF and WebP images and PDF documents are accepted, detected from the content.
+// @Description The size limit is configured with ATTACHMENT_MAX_SIZE (10 MB by default); a transaction can have at most 10 attachments
+// @Tags Attachments
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Param file formData file true "File"
+// @Success 201 {object} model.Attachment
+// @Failure 400 {object} map[string]string "error: invalid file"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: transaction not found"
+// @Security BearerAuth
+// @Router /transactions/{id}/attachments [post]
+func (h *AttachmentHandler) Upload(c *gin.Context) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxSize+multipartOverhead)
+	fh, err := c.FormFile("file")
+	if err != nil {
+		h.logger.WithError(err).Warn("Invalid attachment upload")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required and must not be too large"})
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to open attachment upload")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+
+	txID := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"transactionID": txID, "userID": userID, "file": fh.Filename, "size": fh.Size}).Info("Uploading attachment")
+	a, err := h.svc.Upload(txID, userID, fh.Filename, f, fh.Size)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to upload attachment")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithField("attachmentID", a.ID).Info("Attachment uploaded successfully")
+	c.JSON(http.StatusCreated, a)
+}
+
+// List godoc
+// @Summary List attachments of a transaction
+// @Description Retrieves the files attached to a transaction of the authenticated user, oldest first
+// @Tags Attachments
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Success 200 {array} model.Attachment
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: transaction not found"
+// @Security BearerAuth
+// @Router /transactions/{id}/attachments [get]
+func (h *AttachmentHandler) List(c *gin.Context) {
+	txID := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"transactionID": txID, "userID": userID}).Info("Listing attachments")
+	list, err := h.svc.List(txID, userID)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to list attachments")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithField("count", len(list)).Info("Attachments listed successfully")
+	c.JSON(http.StatusOK, list)
+}
+
+// Download godoc
+// @Summary Download an attachment
+// @Description Returns the content of an attachment with its content type, as a download with the original file name
+// @Tags Attachments
+// @Produce octet-stream
+// @Param id path string true "Transaction ID"
+// @Param attachment_id path string true "Attachment ID"
+// @Success 200 {file} file
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: attachment not found"
+// @Security BearerAuth
+// @Router /transactions/{id}/attachments/{attachment_id} [get]
+func (h *AttachmentHandler) Download(c *gin.Context) {
+	id := c.Param("attachment_id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"attachmentID": id, "userID": userID}).Info("Downloading attachment")
+	a, body, err := h.svc.Open(id, c.Param("id"), userID)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to open attachment")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	defer body.Close()
+	c.DataFromReader(http.StatusOK, a.Size, a.ContentType, body, map[string]string{
+		"Content-Disposition":    mime.FormatMediaType("attachment", map[string]string{"filename": a.FileName}),
+		"X-Content-Type-Options": "nosniff",
+	})
+	h.logger.WithField("size", a.Size).Info("Attachment downloaded successfully")
+}
+
+// Delete godoc
+// @Summary Delete an attachment
+// @Description Deletes an attachment and its stored content
+// @Tags Attachments
+// @Param id path string true "Transaction ID"
+// @Param attachment_id path string true "Attachment ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: attachment not found"
+// @Security BearerAuth
+// @Router /transactions/{id}/attachments/{attachment_id} [delete]
+func (h *AttachmentHandler) Delete(c *gin.Context) {
+	id := c.Param("attachment_id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"attachmentID": id, "userID": userID}).Info("Deleting attachment")
+	if err := h.svc.Delete(id, c.Param("id"), userID); err != nil {
+		h.logger.WithError(err).Warn("Failed to delete attachment")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Attachment deleted successfully")
+	c.Status(http.StatusNoContent)
+}
@@ -36,7 +36,7 @@ type refreshRequest struct {
 
 // Register godoc
 // @Summary Register a new user
-// @Description Creates a new user account with the provided email and password
+// @Description Creates a new user account with the provided email and password and emails a link to verify the address
 // @Tags Auth
 // @Accept json
 // @Produce json
@@ -63,7 +63,7 @@ func (h *AuthHandler) Register(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed", "details": err.Error()})
 		return
 	}
-	if err := h.service.Register(req.Email, req.Password); err != nil {
+	if err := h.service.Register(c.Request.Context(), req.Email, req.Password); err != nil {
 		h.logger.WithError(err).Warn("Registration failed")
 		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
 		return
@@ -101,7 +101,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed", "details": err.Error()})
 		return
 	}
-	accessToken, refreshToken, err := h.service.Login(req.Email, req.Password)
+	accessToken, refreshToken, err := h.service.Login(req.Email, req.Password, clientOf(c))
 	if err != nil {
 		h.logger.WithError(err).Warn("Login failed")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
@@ -113,14 +113,14 @@ func (h *AuthHandler) Login(c *gin.Context) {
 
 // Refresh godoc
 // @Summary Refresh access token
-// @Description Generates a new access token and refresh token using a valid refresh token
+// @Description Generates a new access token and refresh token using a valid refresh token. A refresh token can be used once; presenting it again revokes its session
 // @Tags Auth
 // @Accept json
 // @Produce json
 // @Param request body refreshRequest true "Refresh token"
 // @Success 200 {object} map[string]string "access_token: new JWT token, refresh_token: new refresh token"
 // @Failure 400 {object} map[string]interface{} "error: validation failed, details: list of errors"
-// @Failure 401 {object} map[string]interface{} "error: invalid or expired refresh token, type: error type"
+// @Failure 401 {object} map[string]interface{} "error: invalid, expired or reused refresh token, type: error type"
 // @Router /refresh [post]
 func (h *AuthHandler) Refresh(c *gin.Context) {
 	var req refreshRequest
@@ -140,7 +140,7 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed", "details": err.Error()})
 		return
 	}
-	accessToken, newRefreshToken, err := h.service.RefreshToken(req.RefreshToken)
+	accessToken, newRefreshToken, err := h.service.RefreshToken(req.RefreshToken, clientOf(c))
 	if err != nil {
 		h.logger.WithError(err).Warn("Token refresh failed")
 		if appErr, ok := err.(*utils.AppError); ok {
@@ -160,7 +160,7 @@ func (h *AuthHandler) Refresh(c *gin.Context) {
 // @Tags Auth
 // @Produce json
 // @Security BearerAuth
-// @Success 200 {object} map[string]string "id: user ID, email: user email"
+// @Success 200 {object} map[string]interface{} "id: user ID, email: user email, base_currency: reporting currency, email_verified: whether the email is verified"
 // @Failure 401 {object} map[string]string "error: User not authenticated"
 // @Failure 404 {object} map[string]string "error: User not found"
 // @Router /me [get]
@@ -178,5 +178,85 @@ func (h *AuthHandler) GetProfile(c *gin.Context) {
 		return
 	}
 	h.logger.Info("User profile retrieved successfully")
-	c.JSON(http.StatusOK, gin.H{"id": user.ID, "email": user.Email})
+	c.JSON(http.StatusOK, gin.H{"id": user.ID, "email": user.Email, "base_currency": user.BaseCurrency, "email_verified": user.EmailVerified})
+}
+
+// Logout godoc
+// @Summary Log out
+// @Description Ends the session of the access token: its refresh token is revoked and the access token is rejected from now on
+// @Tags Auth
+// @Security BearerAuth
+// @Success 204
+// @Failure 400 {object} map[string]string "error: access token has no session"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Router /logout [post]
+func (h *AuthHandler) Logout(c *gin.Context) {
+	if err := h.service.Logout(c.GetString("userID"), c.GetString("sessionID")); err != nil {
+		h.logger.WithError(err).Warn("Logout failed")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("User logged out")
+	c.Status(http.StatusNoContent)
+}
+
+// Sessions godoc
+// @Summary List sessions
+// @Description Lists the user's active sessions with the device they were started from; the session of the request is marked current
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {array} service.Session
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Router /sessions [get]
+func (h *AuthHandler) Sessions(c *gin.Context) {
+	sessions, err := h.service.Sessions(c.GetString("userID"), c.GetString("sessionID"))
+	if err != nil {
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, sessions)
+}
+
+// RevokeSession godoc
+// @Summary Revoke a session
+// @Description Ends one session of the user, e.g. on a lost device
+// @Tags Auth
+// @Security BearerAuth
+// @Param id path string true "Session ID"
+// @Success 204
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: session not found"
+// @Router /sessions/{id} [delete]
+func (h *AuthHandler) RevokeSession(c *gin.Context) {
+	if err := h.service.RevokeSession(c.Param("id"), c.GetString("userID")); err != nil {
+		h.logger.WithError(err).Warn("Failed to revoke session")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// RevokeAllSessions godoc
+// @Summary Revoke all sessions
+// @Description Ends every session of the user, including the current one
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]int "revoked: number of ended sessions"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Router /sessions [delete]
+func (h *AuthHandler) RevokeAllSessions(c *gin.Context) {
+	n, err := h.service.RevokeAllSessions(c.GetString("userID"))
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to revoke sessions")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"revoked": n})
+}
+
+// clientOf describes the client that sent the request.
+func clientOf(c *gin.Context) service.Client {
+	return service.Client{UserAgent: c.Request.UserAgent(), IP: c.ClientIP()}
 }
\ No newline at end of file
@@ -0,0 +1,220 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/service"
+	"statistic_service/pkg/money"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+// BudgetHandler handles category budgets and their progress.
+type BudgetHandler struct {
+	svc      service.BudgetService
+	validate *validator.Validate
+	logger   *logrus.Logger
+}
+
+// NewBudgetHandler creates a new BudgetHandler instance.
+func NewBudgetHandler(s service.BudgetService, logger *logrus.Logger) *BudgetHandler {
+	return &BudgetHandler{svc: s, validate: validator.New(), logger: logger}
+}
+
+type budgetRequest struct {
+	CategoryID string       `json:"category_id" validate:"required,uuid"`
+	Period     string       `json:"period" validate:"required,oneof=weekly monthly"`
+	Limit      money.Amount `json:"limit" swaggertype:"string" example:"300.00"`
+	Rollover   bool         `json:"rollover"`
+}
+
+type updateBudgetRequest struct {
+	Limit    money.Amount `json:"limit" swaggertype:"string" example:"300.00"`
+	Rollover bool         `json:"rollover"`
+}
+
+// Create godoc
+// @Summary Create a budget
+// @Description Sets a weekly or monthly spending limit, in the base currency, for an expense category including its subcategories
+// @Tags Budgets
+// @Accept json
+// @Produce json
+// @Param budget body budgetRequest true "Budget details"
+// @Success 201 {object} model.Budget
+// @Failure 400 {object} map[string]interface{} "error: validation failed, details: list of errors"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 409 {object} map[string]string "error: category already has a budget for this period"
+// @Security BearerAuth
+// @Router /budgets [post]
+func (h *BudgetHandler) Create(c *gin.Context) {
+	var req budgetRequest
+	if !bindJSON(c, h.validate, h.logger, &req) {
+		return
+	}
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"userID": userID, "categoryID": req.CategoryID, "period": req.Period}).Info("Creating budget")
+	budget := &model.Budget{CategoryID: req.CategoryID, Period: req.Period, Limit: req.Limit, Rollover: req.Rollover}
+	if err := h.svc.Create(userID, budget); err != nil {
+		h.logger.WithError(err).Warn("Failed to create budget")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Budget created successfully")
+	c.JSON(http.StatusCreated, budget)
+}
+
+// List godoc
+// @Summary List budgets
+// @Description Retrieves the budgets of the authenticated user
+// @Tags Budgets
+// @Produce json
+// @Success 200 {array} model.Budget
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /budgets [get]
+func (h *BudgetHandler) List(c *gin.Context) {
+	userID := c.GetString("userID")
+	h.logger.WithField("userID", userID).Info("Listing budgets")
+	list, err := h.svc.List(userID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list budgets")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithField("count", len(list)).Info("Budgets listed successfully")
+	c.JSON(http.StatusOK, list)
+}
+
+// Update godoc
+// @Summary Update a budget
+// @Description Changes the limit and rollover of a budget
+// @Tags Budgets
+// @Accept json
+// @Produce json
+// @Param id path string true "Budget ID"
+// @Param budget body updateBudgetRequest true "Budget details"
+// @Success 200 {object} model.Budget
+// @Failure 400 {object} map[string]interface{} "error: validation failed"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: budget not found"
+// @Security BearerAuth
+// @Router /budgets/{id} [put]
+func (h *BudgetHandler) Update(c *gin.Context) {
+	var req updateBudgetRequest
+	if !bindJSON(c, h.validate, h.logger, &req) {
+		return
+	}
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"budgetID": id, "userID": userID}).Info("Updating budget")
+	budget, err := h.svc.Update(id, userID, req.Limit, req.Rollover)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to update budget")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Budget updated successfully")
+	c.JSON(http.StatusOK, budget)
+}
+
+// Delete godoc
+// @Summary Delete a budget
+// @Description Deletes a budget of the authenticated user
+// @Tags Budgets
+// @Produce json
+// @Param id path string true "Budget ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: budget not found"
+// @Security BearerAuth
+// @Router /budgets/{id} [delete]
+func (h *BudgetHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"budgetID": id, "userID": userID}).Info("Deleting budget")
+	if err := h.svc.Delete(id, userID); err != nil {
+		h.logger.WithError(err).Warn("Failed to delete budget")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Budget deleted successfully")
+	c.Status(http.StatusNoContent)
+}
+
+// Progress godoc
+// @Summary Get budget progress
+// @Description Returns spent, remaining, percent used and projected end-of-period spend for the period containing at
+// @Tags Budgets
+// @Produce json
+// @Param id path string true "Budget ID"
+// @Param at query string false "Point in time in RFC3339 format, defaults to now"
+// @Success 200 {object} service.BudgetProgress
+// @Failure 400 {object} map[string]string "error: invalid at, or at more than one period ahead"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: budget not found"
+// @Security BearerAuth
+// @Router /budgets/{id}/progress [get]
+func (h *BudgetHandler) Progress(c *gin.Context) {
+	at, ok := parseAt(c)
+	if !ok {
+		return
+	}
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"budgetID": id, "userID": userID, "at": at}).Info("Fetching budget progress")
+	progress, err := h.svc.Progress(id, userID, at)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to fetch budget progress")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithField("status", progress.Status).Info("Budget progress retrieved successfully")
+	c.JSON(http.StatusOK, progress)
+}
+
+// ProgressAll godoc
+// @Summary Get progress of all budgets
+// @Description Returns the progress of every budget of the authenticated user for the period containing at
+// @Tags Budgets
+// @Produce json
+// @Param at query string false "Point in time in RFC3339 format, defaults to now"
+// @Success 200 {array} service.BudgetProgress
+// @Failure 400 {object} map[string]string "error: invalid at, or at more than one period ahead"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /budgets/progress [get]
+func (h *BudgetHandler) ProgressAll(c *gin.Context) {
+	at, ok := parseAt(c)
+	if !ok {
+		return
+	}
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"userID": userID, "at": at}).Info("Fetching progress of all budgets")
+	list, err := h.svc.ProgressAll(userID, at)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to fetch budget progress")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithField("count", len(list)).Info("Budget progress retrieved successfully")
+	c.JSON(http.StatusOK, list)
+}
+
+// parseAt reads the optional at query parameter, defaulting to now. It writes
+// a 400 response and returns false when the value is not RFC3339.
+func parseAt(c *gin.Context) (time.Time, bool) {
+	v := c.Query("at")
+	if v == "" {
+		return time.Now(), true
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid at"})
+		return time.Time{}, false
+	}
+	return t, true
+}
@@ -0,0 +1,184 @@
+package handler
+
+import (
+	"net/http"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+// CategoryHandler handles category management HTTP requests.
+type CategoryHandler struct {
+	svc      service.CategoryService
+	validate *validator.Validate
+	logger   *logrus.Logger
+}
+
+// NewCategoryHandler creates a new CategoryHandler instance.
+func NewCategoryHandler(s service.CategoryService, logger *logrus.Logger) *CategoryHandler {
+	return &CategoryHandler{svc: s, validate: validator.New(), logger: logger}
+}
+
+type categoryRequest struct {
+	Name     string  `json:"name" validate:"required,max=100"`
+	Type     string  `json:"type" validate:"required,oneof=income expense"`
+	ParentID *string `json:"parent_id" validate:"omitempty,uuid"`
+}
+
+type renameCategoryRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+type moveCategoryRequest struct {
+	ParentID *string `json:"parent_id" validate:"omitempty,uuid"`
+}
+
+// Create godoc
+// @Summary Create a category
+// @Description Creates an income or expense category for the authenticated user, optionally as a subcategory of parent_id
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Param category body categoryRequest true "Category details"
+// @Success 201 {object} model.Category
+// @Failure 400 {object} map[string]interface{} "error: validation failed, details: list of errors"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 409 {object} map[string]string "error: category with this name already exists"
+// @Security BearerAuth
+// @Router /categories [post]
+func (h *CategoryHandler) Create(c *gin.Context) {
+	var req categoryRequest
+	if !bindJSON(c, h.validate, h.logger, &req) {
+		return
+	}
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"userID": userID, "name": req.Name, "type": req.Type}).Info("Creating category")
+	category := &model.Category{Name: req.Name, Type: req.Type, ParentID: req.ParentID}
+	if err := h.svc.Create(userID, category); err != nil {
+		h.logger.WithError(err).Warn("Failed to create category")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Category created successfully")
+	c.JSON(http.StatusCreated, category)
+}
+
+// List godoc
+// @Summary List categories
+// @Description Retrieves categories of the authenticated user, optionally filtered by type
+// @Tags Categories
+// @Produce json
+// @Param type query string false "Category type: income or expense"
+// @Success 200 {array} model.Category
+// @Failure 400 {object} map[string]string "error: invalid type"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /categories [get]
+func (h *CategoryHandler) List(c *gin.Context) {
+	userID := c.GetString("userID")
+	catType := c.Query("type")
+	h.logger.WithFields(logrus.Fields{"userID": userID, "type": catType}).Info("Listing categories")
+	list, err := h.svc.List(userID, catType)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list categories")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithField("count", len(list)).Info("Categories listed successfully")
+	c.JSON(http.StatusOK, list)
+}
+
+// Rename godoc
+// @Summary Rename a category
+// @Description Changes the name of a category owned by the authenticated user
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Param category body renameCategoryRequest true "New name"
+// @Success 200 {object} model.Category
+// @Failure 400 {object} map[string]interface{} "error: validation failed, details: list of errors"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: category not found"
+// @Failure 409 {object} map[string]string "error: category with this name already exists"
+// @Security BearerAuth
+// @Router /categories/{id} [put]
+func (h *CategoryHandler) Rename(c *gin.Context) {
+	var req renameCategoryRequest
+	if !bindJSON(c, h.validate, h.logger, &req) {
+		return
+	}
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"categoryID": id, "userID": userID}).Info("Renaming category")
+	category, err := h.svc.Rename(id, userID, req.Name)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to rename category")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Category renamed successfully")
+	c.JSON(http.StatusOK, category)
+}
+
+// Move godoc
+// @Summary Move a category
+// @Description Moves a category under another parent of the same type, or to the root when parent_id is null
+// @Tags Categories
+// @Accept json
+// @Produce json
+// @Param id path string true "Category ID"
+// @Param category body moveCategoryRequest true "New parent"
+// @Success 200 {object} model.Category
+// @Failure 400 {object} map[string]interface{} "error: cycle, depth limit or type mismatch"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: category not found"
+// @Failure 409 {object} map[string]string "error: category with this name already exists"
+// @Security BearerAuth
+// @Router /categories/{id}/parent [put]
+func (h *CategoryHandler) Move(c *gin.Context) {
+	var req moveCategoryRequest
+	if !bindJSON(c, h.validate, h.logger, &req) {
+		return
+	}
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"categoryID": id, "userID": userID, "parentID": req.ParentID}).Info("Moving category")
+	category, err := h.svc.Move(id, userID, req.ParentID)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to move category")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Category moved successfully")
+	c.JSON(http.StatusOK, category)
+}
+
+// Delete godoc
+// @Summary Delete a category
+// @Description Deletes a category owned by the authenticated user
+// @Tags Categories
+// @Produce json
+// @Param id path string true "Category ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: category not found"
+// @Failure 409 {object} map[string]string "error: category has subcategories"
+// @Security BearerAuth
+// @Router /categories/{id} [delete]
+func (h *CategoryHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"categoryID": id, "userID": userID}).Info("Deleting category")
+	if err := h.svc.Delete(id, userID); err != nil {
+		h.logger.WithError(err).Warn("Failed to delete category")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Category deleted successfully")
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"net/http"
+	"strings"
+
+	"statistic_service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+// CurrencyHandler handles currency settings of the authenticated user.
+type CurrencyHandler struct {
+	svc      service.CurrencyService
+	validate *validator.Validate
+	logger   *logrus.Logger
+}
+
+// NewCurrencyHandler creates a new CurrencyHandler instance.
+func NewCurrencyHandler(s service.CurrencyService, logger *logrus.Logger) *CurrencyHandler {
+	return &CurrencyHandler{svc: s, validate: validator.New(), logger: logger}
+}
+
+type baseCurrencyRequest struct {
+	BaseCurrency string `json:"base_currency" validate:"required,len=3"`
+}
+
+// SetBaseCurrency godoc
+// @Summary Set base currency
+// @Description Changes the currency all statistics of the authenticated user are reported in. Exchange rates must be known for every currency the user has transactions in
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body baseCurrencyRequest true "ISO 4217 currency code"
+// @Success 200 {object} map[string]string "base_currency: new base currency"
+// @Failure 400 {object} map[string]interface{} "error: invalid currency or missing exchange rates"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /me/currency [put]
+func (h *CurrencyHandler) SetBaseCurrency(c *gin.Context) {
+	var req baseCurrencyRequest
+	if !bindJSON(c, h.validate, h.logger, &req) {
+		return
+	}
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"userID": userID, "currency": req.BaseCurrency}).Info("Setting base currency")
+	if err := h.svc.SetBaseCurrency(userID, req.BaseCurrency); err != nil {
+		h.logger.WithError(err).Warn("Failed to set base currency")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Base currency set successfully")
+	c.JSON(http.StatusOK, gin.H{"base_currency": strings.ToUpper(req.BaseCurrency)})
+}
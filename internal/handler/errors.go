@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"statistic_service/pkg/utils"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+// statusForError maps service errors to HTTP status codes.
+func statusForError(err error) int {
+	var appErr *utils.AppError
+	if errors.As(err, &appErr) {
+		switch appErr.Type {
+		case utils.ErrValidation:
+			return http.StatusBadRequest
+		case utils.ErrNotFound:
+			return http.StatusNotFound
+		case utils.ErrConflict:
+			return http.StatusConflict
+		case utils.ErrTooManyRequests:
+			return http.StatusTooManyRequests
+		}
+	}
+	return http.StatusInternalServerError
+}
+
+// bindJSON decodes and validates the JSON body, writing a 400 response on failure.
+func bindJSON(c *gin.Context, validate *validator.Validate, logger *logrus.Logger, req interface{}) bool {
+	if err := c.ShouldBindJSON(req); err != nil {
+		logger.WithError(err).Warn("Invalid request format")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
+		return false
+	}
+	if err := validate.Struct(req); err != nil {
+		logger.WithError(err).Warn("Validation failed")
+		if validationErrs, ok := err.(validator.ValidationErrors); ok {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed", "details": utils.CustomValidationErrors(validationErrs)})
+			return false
+		}
+		c.JSON(http.StatusBadRequest, gin.H{"error": "validation failed", "details": err.Error()})
+		return false
+	}
+	return true
+}
@@ -0,0 +1,261 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/service"
+	"statistic_service/pkg/export"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	transactionColumns = []export.Column{
+		{Name: "id"}, {Name: "created_at"}, {Name: "type"},
+		{Name: "amount", Numeric: true}, {Name: "currency"}, {Name: "base_amount", Numeric: true},
+		{Name: "category_id"}, {Name: "account_id"}, {Name: "transfer_id"}, {Name: "comment"}, {Name: "tags"},
+	}
+	summaryColumns = []export.Column{
+		{Name: "date_from"}, {Name: "date_to"},
+		{Name: "income", Numeric: true}, {Name: "expense", Numeric: true}, {Name: "net", Numeric: true},
+	}
+	categoryColumns = []export.Column{
+		{Name: "category_id"}, {Name: "parent_id"}, {Name: "name"}, {Name: "type"}, {Name: "total", Numeric: true},
+	}
+)
+
+// ExportHandler streams transactions and reports as downloadable files.
+type ExportHandler struct {
+	svc    service.TransactionService
+	logger *logrus.Logger
+}
+
+// NewExportHandler creates a new ExportHandler instance.
+func NewExportHandler(s service.TransactionService, logger *logrus.Logger) *ExportHandler {
+	return &ExportHandler{svc: s, logger: logger}
+}
+
+// Transactions godoc
+// @Summary Export transactions
+// @Description Streams all transactions of the authenticated user matching the filters of GET /transactions, oldest first, as CSV, XLSX or JSON Lines
+// @Tags Export
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/x-ndjson
+// @Param format query string false "csv, xlsx or ndjson" default(csv)
+// @Param date_from query string false "Start date in RFC3339 format"
+// @Param date_to query string false "End date in RFC3339 format"
+// @Param type query string false "Transaction type: income or expense"
+// @Param category_id query string false "Category ID"
+// @Param account_id query string false "Account ID"
+// @Param amount_min query string false "Minimum amount, decimal with up to 2 places"
+// @Param amount_max query string false "Maximum amount, decimal with up to 2 places"
+// @Param q query string false "Text to search for in the comment"
+// @Success 200 {file} file "Transactions file"
+// @Failure 400 {object} map[string]string "error: invalid format or filter"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /export/transactions [get]
+func (h *ExportHandler) Transactions(c *gin.Context) {
+	userID := c.GetString("userID")
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+	f, err := parseTransactionFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithFields(logrus.Fields{"userID": userID, "format": format, "filter": f}).Info("Exporting transactions")
+
+	// The file is started with the first row, so errors before it still get a JSON response.
+	var w export.Writer
+	open := func() error {
+		if w != nil {
+			return nil
+		}
+		var err error
+		w, err = startExport(c, format, "transactions", transactionColumns)
+		return err
+	}
+	count := 0
+	err = h.svc.Each(userID, f, func(tx *model.Transaction) error {
+		if err := open(); err != nil {
+			return err
+		}
+		count++
+		return w.WriteRow([]string{
+			tx.ID, tx.CreatedAt.UTC().Format(time.RFC3339), tx.Type,
+			tx.Amount.String(), tx.Currency, tx.BaseAmount.String(),
+			deref(tx.CategoryID), deref(tx.AccountID), deref(tx.TransferID), tx.Comment,
+			strings.Join(tx.TagNames, ","),
+		})
+	})
+	if err == nil {
+		err = open()
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to export transactions")
+		if w == nil {
+			c.JSON(statusForError(err), gin.H{"error": err.Error()})
+			return
+		}
+		// Headers are sent; the truncated file is all the client gets.
+		c.Abort()
+		return
+	}
+	if err := w.Close(); err != nil {
+		h.logger.WithError(err).Error("Failed to finish transactions export")
+		return
+	}
+	h.logger.WithField("count", count).Info("Transactions exported successfully")
+}
+
+// Summary godoc
+// @Summary Export transactions summary
+// @Description Returns the income and expense totals of GET /stats/summary, in the base currency, as a CSV, XLSX or JSON Lines file
+// @Tags Export
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/x-ndjson
+// @Param format query string false "csv, xlsx or ndjson" default(csv)
+// @Param date_from query string false "Start date in RFC3339 format"
+// @Param date_to query string false "End date in RFC3339 format"
+// @Success 200 {file} file "Summary file"
+// @Failure 400 {object} map[string]string "error: invalid format or date"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /export/summary [get]
+func (h *ExportHandler) Summary(c *gin.Context) {
+	userID := c.GetString("userID")
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+	f, err := parseTransactionFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithFields(logrus.Fields{"userID": userID, "format": format, "from": f.From, "to": f.To}).Info("Exporting summary")
+	inc, exp, err := h.svc.Summary(userID, f.From, f.To)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to export summary")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.writeReport(c, format, "summary", summaryColumns, [][]string{
+		{formatTime(f.From), formatTime(f.To), inc.String(), exp.String(), inc.Sub(exp).String()},
+	})
+}
+
+// Categories godoc
+// @Summary Export category totals
+// @Description Returns the totals of GET /stats/categories, in the base currency, as a CSV, XLSX or JSON Lines file
+// @Tags Export
+// @Produce text/csv
+// @Produce application/vnd.openxmlformats-officedocument.spreadsheetml.sheet
+// @Produce application/x-ndjson
+// @Param format query string false "csv, xlsx or ndjson" default(csv)
+// @Param date_from query string false "Start date in RFC3339 format"
+// @Param date_to query string false "End date in RFC3339 format"
+// @Param depth query int false "Roll up subcategories into categories at this tree level (1 = root categories)"
+// @Success 200 {file} file "Category totals file"
+// @Failure 400 {object} map[string]string "error: invalid format, date or depth"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /export/categories [get]
+func (h *ExportHandler) Categories(c *gin.Context) {
+	userID := c.GetString("userID")
+	format, ok := exportFormat(c)
+	if !ok {
+		return
+	}
+	f, err := parseTransactionFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	depth := 0
+	if d := c.Query("depth"); d != "" {
+		n, err := strconv.Atoi(d)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid depth"})
+			return
+		}
+		depth = n
+	}
+	h.logger.WithFields(logrus.Fields{"userID": userID, "format": format, "from": f.From, "to": f.To, "depth": depth}).Info("Exporting category totals")
+	totals, err := h.svc.ByCategory(userID, f.From, f.To, depth)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to export category totals")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	rows := make([][]string, len(totals))
+	for i, t := range totals {
+		rows[i] = []string{deref(t.CategoryID), deref(t.ParentID), t.Name, t.Type, t.Total.String()}
+	}
+	h.writeReport(c, format, "categories", categoryColumns, rows)
+}
+
+// writeReport writes a small report that is already in memory.
+func (h *ExportHandler) writeReport(c *gin.Context, format, name string, columns []export.Column, rows [][]string) {
+	w, err := startExport(c, format, name, columns)
+	if err == nil {
+		for _, row := range rows {
+			if err = w.WriteRow(row); err != nil {
+				break
+			}
+		}
+	}
+	if err == nil {
+		err = w.Close()
+	}
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to write export")
+		c.Abort()
+		return
+	}
+	h.logger.WithField("rows", len(rows)).Info("Report exported successfully")
+}
+
+// exportFormat reads the format query parameter, writing a 400 response when
+// it is not supported.
+func exportFormat(c *gin.Context) (string, bool) {
+	format := c.DefaultQuery("format", export.CSV)
+	if format != export.CSV && format != export.XLSX && format != export.NDJSON {
+		c.JSON(http.StatusBadRequest, gin.H{"error": export.ErrUnknownFormat.Error()})
+		return "", false
+	}
+	return format, true
+}
+
+// startExport sends the download headers and returns a writer for the body.
+func startExport(c *gin.Context, format, name string, columns []export.Column) (export.Writer, error) {
+	c.Header("Content-Type", export.ContentType(format))
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, name, format))
+	c.Status(http.StatusOK)
+	return export.NewWriter(format, c.Writer, columns)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func formatTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.UTC().Format(time.RFC3339)
+}
@@ -0,0 +1,180 @@
+package handler
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"statistic_service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// maxImportSize bounds the size of an uploaded import file.
+const maxImportSize = 5 << 20
+
+// ImportHandler handles bulk imports of transactions from files.
+type ImportHandler struct {
+	svc    service.ImportService
+	logger *logrus.Logger
+}
+
+// NewImportHandler creates a new ImportHandler instance.
+func NewImportHandler(s service.ImportService, logger *logrus.Logger) *ImportHandler {
+	return &ImportHandler{svc: s, logger: logger}
+}
+
+// ImportCSV godoc
+// @Summary Import transactions from CSV
+// @Description Imports transactions from a CSV file with a header line. Either all rows are imported in one DB transaction or none.
+// @Description mapping maps the fields date, amount, type, category, comment, currency and account_id to header names; unmapped fields use the header of the same name.
+// @Description Without a type column negative amounts are expenses and positive amounts income. category matches a category name or a path such as "Transport > Fuel".
+// @Description The delimiter, the date format and the decimal separator are detected when not given. With dry_run nothing is stored and the parsed transactions are returned.
+// @Description Rows that look like existing transactions are flagged with duplicate_of, or reported as row errors with on_duplicate=reject
+// @Tags Import
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "CSV file"
+// @Param mapping formData string false "JSON object mapping fields to header names, e.g. {\"date\":\"Booking date\"}"
+// @Param delimiter formData string false "Column delimiter, e.g. ; or tab"
+// @Param date_format formData string false "Date format, e.g. YYYY-MM-DD, DD.MM.YYYY or MM/DD/YYYY"
+// @Param on_duplicate formData string false "Rows that look like existing transactions: flag, reject or ignore" default(flag)
+// @Param dry_run formData bool false "Validate without storing"
+// @Param Idempotency-Key header string false "Key that makes retries return the original response; reusing it with a different request gives 422"
+// @Success 200 {object} service.ImportReport "dry run"
+// @Success 201 {object} service.ImportReport "imported"
+// @Failure 400 {object} service.ImportReport "rows with errors, nothing imported"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 422 {object} map[string]string "error: idempotency key was already used with a different request"
+// @Security BearerAuth
+// @Router /transactions/import/csv [post]
+func (h *ImportHandler) ImportCSV(c *gin.Context) {
+	userID := c.GetString("userID")
+	fh, dryRun, ok := h.parseUpload(c)
+	if !ok {
+		return
+	}
+
+	var opts service.CSVOptions
+	if m := c.PostForm("mapping"); m != "" {
+		if err := json.Unmarshal([]byte(m), &opts.Mapping); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid mapping"})
+			return
+		}
+	}
+	switch d := []rune(c.PostForm("delimiter")); {
+	case string(d) == "tab" || string(d) == `\t`:
+		opts.Delimiter = '\t'
+	case len(d) == 1:
+		opts.Delimiter = d[0]
+	case len(d) > 1:
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delimiter"})
+		return
+	}
+	opts.DateFormat = c.PostForm("date_format")
+
+	f, err := fh.Open()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to open import upload")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+
+	h.logger.WithFields(logrus.Fields{"userID": userID, "file": fh.Filename, "size": fh.Size, "dryRun": dryRun}).Info("Importing transactions from CSV")
+	onDuplicate := service.DuplicatePolicy(c.DefaultPostForm("on_duplicate", string(service.DuplicateFlag)))
+	report, err := h.svc.ImportCSV(c.Request.Context(), userID, f, opts, onDuplicate, dryRun)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to import transactions")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.respondImport(c, report)
+}
+
+// ImportStatement godoc
+// @Summary Import a bank statement
+// @Description Imports the booked entries of an OFX/QFX, QIF or ISO 20022 camt.053 statement into an optional account. Either all new entries are imported or none.
+// @Description Debits become expenses and credits income, the booking date becomes created_at and the memo the comment.
+// @Description Entries whose bank reference (FITID, AcctSvcrRef) was imported into the account before and entries with a zero amount are skipped. With dry_run nothing is stored and the new transactions are returned as a preview.
+// @Description Entries that look like existing transactions are flagged with duplicate_of, or reported as entry errors with on_duplicate=reject
+// @Tags Import
+// @Accept multipart/form-data
+// @Produce json
+// @Param file formData file true "Statement file"
+// @Param format formData string false "ofx, qfx, qif or camt053, detected when empty"
+// @Param account_id formData string false "Account to import into"
+// @Param on_duplicate formData string false "Entries that look like existing transactions: flag, reject or ignore" default(flag)
+// @Param dry_run formData bool false "Preview without storing"
+// @Param Idempotency-Key header string false "Key that makes retries return the original response; reusing it with a different request gives 422"
+// @Success 200 {object} service.ImportReport "preview"
+// @Success 201 {object} service.ImportReport "imported"
+// @Failure 400 {object} service.ImportReport "entries with errors, nothing imported"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 422 {object} map[string]string "error: idempotency key was already used with a different request"
+// @Security BearerAuth
+// @Router /transactions/import/statement [post]
+func (h *ImportHandler) ImportStatement(c *gin.Context) {
+	userID := c.GetString("userID")
+	fh, dryRun, ok := h.parseUpload(c)
+	if !ok {
+		return
+	}
+	f, err := fh.Open()
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to open import upload")
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+	defer f.Close()
+
+	format := c.PostForm("format")
+	accountID := c.PostForm("account_id")
+	h.logger.WithFields(logrus.Fields{"userID": userID, "file": fh.Filename, "format": format, "accountID": accountID, "dryRun": dryRun}).Info("Importing bank statement")
+	onDuplicate := service.DuplicatePolicy(c.DefaultPostForm("on_duplicate", string(service.DuplicateFlag)))
+	report, err := h.svc.ImportStatement(c.Request.Context(), userID, f, format, accountID, onDuplicate, dryRun)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to import bank statement")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.respondImport(c, report)
+}
+
+// parseUpload limits the request size and reads the uploaded file and the
+// dry_run flag, writing a 400 response on failure.
+func (h *ImportHandler) parseUpload(c *gin.Context) (*multipart.FileHeader, bool, bool) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxImportSize)
+	fh, err := c.FormFile("file")
+	if err != nil {
+		h.logger.WithError(err).Warn("Invalid import upload")
+		c.JSON(http.StatusBadRequest, gin.H{"error": "file is required and must be at most 5 MB"})
+		return nil, false, false
+	}
+	dryRun := false
+	if v := c.PostForm("dry_run"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid dry_run"})
+			return nil, false, false
+		}
+	}
+	return fh, dryRun, true
+}
+
+// respondImport writes an import report with the status matching its outcome.
+func (h *ImportHandler) respondImport(c *gin.Context, report *service.ImportReport) {
+	fields := logrus.Fields{"rows": report.Rows, "imported": report.Imported, "errors": len(report.Errors)}
+	switch {
+	case len(report.Errors) > 0:
+		h.logger.WithFields(fields).Warn("Import has invalid rows")
+		c.JSON(http.StatusBadRequest, report)
+	case report.DryRun:
+		h.logger.WithFields(fields).Info("Import dry run completed")
+		c.JSON(http.StatusOK, report)
+	default:
+		h.logger.WithFields(fields).Info("Transactions imported successfully")
+		c.JSON(http.StatusCreated, report)
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"net/http"
+
+	"statistic_service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+// PasswordHandler handles password reset HTTP requests.
+type PasswordHandler struct {
+	svc      service.PasswordService
+	validate *validator.Validate
+	logger   *logrus.Logger
+}
+
+// NewPasswordHandler creates a new PasswordHandler instance.
+func NewPasswordHandler(s service.PasswordService, logger *logrus.Logger) *PasswordHandler {
+	return &PasswordHandler{svc: s, validate: validator.New(), logger: logger}
+}
+
+type forgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type resetPasswordRequest struct {
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+// Forgot godoc
+// @Summary Request a password reset
+// @Description Emails a single-use password reset token to the address if it belongs to a user, at most once per reset interval. The email is sent in the background and the response is the same for unknown addresses and repeated requests
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param request body forgotPasswordRequest true "Email of the account"
+// @Success 202 {object} map[string]string "status: accepted"
+// @Failure 400 {object} map[string]interface{} "error: validation failed, details: list of errors"
+// @Router /password/forgot [post]
+func (h *PasswordHandler) Forgot(c *gin.Context) {
+	var req forgotPasswordRequest
+	if !bindJSON(c, h.validate, h.logger, &req) {
+		return
+	}
+	if err := h.svc.Forgot(c.Request.Context(), req.Email); err != nil {
+		h.logger.WithError(err).Error("Failed to request password reset")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"status": "accepted"})
+}
+
+// Reset godoc
+// @Summary Reset the password
+// @Description Sets a new password with a token from the reset email. The token can be used once, and all sessions of the user are ended
+// @Tags Auth
+// @Accept json
+// @Param request body resetPasswordRequest true "Reset token and new password"
+// @Success 204
+// @Failure 400 {object} map[string]interface{} "error: invalid or expired reset token, or a weak password"
+// @Router /password/reset [post]
+func (h *PasswordHandler) Reset(c *gin.Context) {
+	var req resetPasswordRequest
+	if !bindJSON(c, h.validate, h.logger, &req) {
+		return
+	}
+	if err := h.svc.Reset(req.Token, req.Password); err != nil {
+		h.logger.WithError(err).Warn("Password reset failed")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Password reset successfully")
+	c.Status(http.StatusNoContent)
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"net/http"
 	"statistic_service/internal/service"
+	"statistic_service/pkg/money"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -20,13 +21,13 @@ func NewPredictHandler(s service.TransactionService, logger *logrus.Logger) *Pre
 
 // Predict godoc
 // @Summary Predict next month's expenses or income
-// @Description Calculates the expected total expenses or income for the next month based on the current month's average
+// @Description Calculates the expected total expenses or income for the next month, in the user's base currency, based on the current month's average
 // @Tags Statistics
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param type query string true "Transaction type: expense or income"
-// @Success 200 {object} map[string]float64
+// @Success 200 {object} map[string]interface{} "average_per_day, predicted_total: decimal strings, days_next_month: number"
 // @Failure 400 {object} map[string]string "Invalid type"
 // @Failure 500 {object} map[string]string "Internal error"
 // @Router /predict [get]
@@ -43,25 +44,19 @@ func (h *PredictHandler) Predict(c *gin.Context) {
 	nextMonthStart := currentMonthStart.AddDate(0, 1, 0)
 	nextMonthEnd := nextMonthStart.AddDate(0, 1, -1)
 
-	list, err := h.svc.List(userID, &currentMonthStart, &now, txType) // this mounth
+	list, err := h.svc.List(userID, &currentMonthStart, &now, txType, "") // this mounth
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
 
-	total := 0.0
+	var total money.Amount
 	for _, tx := range list {
-		total += tx.Amount
+		total = total.Add(tx.BaseAmount)
 	}
 
-	daysPassed := now.Sub(currentMonthStart).Hours() / 24
-	if daysPassed == 0 {
-		daysPassed = 1
-	}
-
-	average := total / daysPassed
-	daysNext := float64(nextMonthEnd.Day())
-	predicted := average * daysNext
+	daysNext := int64(nextMonthEnd.Day())
+	average, predicted := service.Forecast(total, currentMonthStart, now, daysNext)
 
 	c.JSON(http.StatusOK, gin.H{
 		"average_per_day": average,
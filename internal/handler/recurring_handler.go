@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"net/http"
+	"time"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/service"
+	"statistic_service/pkg/money"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+// RecurringHandler handles recurring transaction templates.
+type RecurringHandler struct {
+	svc      service.RecurringService
+	validate *validator.Validate
+	logger   *logrus.Logger
+}
+
+// NewRecurringHandler creates a new RecurringHandler instance.
+func NewRecurringHandler(s service.RecurringService, logger *logrus.Logger) *RecurringHandler {
+	return &RecurringHandler{svc: s, validate: validator.New(), logger: logger}
+}
+
+type recurringRequest struct {
+	Amount     money.Amount `json:"amount" swaggertype:"string" example:"1200.00"`
+	Currency   string       `json:"currency" validate:"omitempty,len=3"`
+	Type       string       `json:"type" validate:"required,oneof=income expense"`
+	CategoryID *string      `json:"category_id"`
+	AccountID  *string      `json:"account_id"`
+	Comment    string       `json:"comment"`
+	Frequency  string       `json:"frequency" validate:"required,oneof=daily weekly monthly yearly"`
+	Interval   int          `json:"interval" example:"1"`
+	DayOfMonth int          `json:"day_of_month" example:"25"`
+	StartAt    time.Time    `json:"start_at" validate:"required"`
+	EndAt      *time.Time   `json:"end_at"`
+	Count      int          `json:"count"`
+}
+
+type updateRecurringRequest struct {
+	Amount     money.Amount `json:"amount" swaggertype:"string" example:"1200.00"`
+	Currency   string       `json:"currency" validate:"omitempty,len=3"`
+	Type       string       `json:"type" validate:"required,oneof=income expense"`
+	CategoryID *string      `json:"category_id"`
+	AccountID  *string      `json:"account_id"`
+	Comment    string       `json:"comment"`
+	EndAt      *time.Time   `json:"end_at"`
+	Count      int          `json:"count"`
+}
+
+// Create godoc
+// @Summary Create a recurring transaction
+// @Description Creates a template that adds a transaction on every occurrence of its schedule, starting at start_at, which may be at most a year ago.
+// @Description Monthly schedules run on day_of_month, or on the day of start_at, using the last day of shorter months.
+// @Description The schedule ends at end_at or after count occurrences when either is set
+// @Tags Recurring
+// @Accept json
+// @Produce json
+// @Param recurring body recurringRequest true "Template and schedule"
+// @Success 201 {object} model.RecurringTransaction
+// @Failure 400 {object} map[string]interface{} "error: validation failed, details: list of errors"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /recurring [post]
+func (h *RecurringHandler) Create(c *gin.Context) {
+	var req recurringRequest
+	if !bindJSON(c, h.validate, h.logger, &req) {
+		return
+	}
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"userID": userID, "frequency": req.Frequency, "startAt": req.StartAt}).Info("Creating recurring transaction")
+	rec := &model.RecurringTransaction{
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+		Type:       req.Type,
+		CategoryID: req.CategoryID,
+		AccountID:  req.AccountID,
+		Comment:    req.Comment,
+		Frequency:  req.Frequency,
+		Interval:   req.Interval,
+		DayOfMonth: req.DayOfMonth,
+		StartAt:    req.StartAt,
+		EndAt:      req.EndAt,
+		Count:      req.Count,
+	}
+	if err := h.svc.Create(userID, rec); err != nil {
+		h.logger.WithError(err).Warn("Failed to create recurring transaction")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Recurring transaction created successfully")
+	c.JSON(http.StatusCreated, rec)
+}
+
+// List godoc
+// @Summary List recurring transactions
+// @Description Retrieves the recurring transaction templates of the authenticated user
+// @Tags Recurring
+// @Produce json
+// @Success 200 {array} model.RecurringTransaction
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /recurring [get]
+func (h *RecurringHandler) List(c *gin.Context) {
+	userID := c.GetString("userID")
+	h.logger.WithField("userID", userID).Info("Listing recurring transactions")
+	list, err := h.svc.List(userID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list recurring transactions")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithField("count", len(list)).Info("Recurring transactions listed successfully")
+	c.JSON(http.StatusOK, list)
+}
+
+// Update godoc
+// @Summary Update a recurring transaction
+// @Description Changes the transaction created by future occurrences and when the schedule ends. Frequency and start are fixed
+// @Tags Recurring
+// @Accept json
+// @Produce json
+// @Param id path string true "Recurring transaction ID"
+// @Param recurring body updateRecurringRequest true "Template details"
+// @Success 200 {object} model.RecurringTransaction
+// @Failure 400 {object} map[string]interface{} "error: validation failed, details: list of errors"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: recurring transaction not found"
+// @Security BearerAuth
+// @Router /recurring/{id} [put]
+func (h *RecurringHandler) Update(c *gin.Context) {
+	var req updateRecurringRequest
+	if !bindJSON(c, h.validate, h.logger, &req) {
+		return
+	}
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"recurringID": id, "userID": userID}).Info("Updating recurring transaction")
+	rec, err := h.svc.Update(id, userID, &model.RecurringTransaction{
+		Amount:     req.Amount,
+		Currency:   req.Currency,
+		Type:       req.Type,
+		CategoryID: req.CategoryID,
+		AccountID:  req.AccountID,
+		Comment:    req.Comment,
+		EndAt:      req.EndAt,
+		Count:      req.Count,
+	})
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to update recurring transaction")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Recurring transaction updated successfully")
+	c.JSON(http.StatusOK, rec)
+}
+
+// Delete godoc
+// @Summary Delete a recurring transaction
+// @Description Stops the schedule. Transactions it already created are kept
+// @Tags Recurring
+// @Produce json
+// @Param id path string true "Recurring transaction ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: recurring transaction not found"
+// @Security BearerAuth
+// @Router /recurring/{id} [delete]
+func (h *RecurringHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"recurringID": id, "userID": userID}).Info("Deleting recurring transaction")
+	if err := h.svc.Delete(id, userID); err != nil {
+		h.logger.WithError(err).Warn("Failed to delete recurring transaction")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Recurring transaction deleted successfully")
+	c.Status(http.StatusNoContent)
+}
@@ -0,0 +1,264 @@
+package handler
+
+import (
+	"net/http"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/service"
+	"statistic_service/pkg/money"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+// RuleHandler handles categorization rules.
+type RuleHandler struct {
+	svc      service.RuleService
+	validate *validator.Validate
+	logger   *logrus.Logger
+}
+
+// NewRuleHandler creates a new RuleHandler instance.
+func NewRuleHandler(s service.RuleService, logger *logrus.Logger) *RuleHandler {
+	return &RuleHandler{svc: s, validate: validator.New(), logger: logger}
+}
+
+type ruleRequest struct {
+	Name            string        `json:"name" validate:"required,max=100"`
+	CommentContains string        `json:"comment_contains"`
+	CommentPattern  string        `json:"comment_pattern"`
+	MinAmount       *money.Amount `json:"min_amount" swaggertype:"string" example:"10.00"`
+	MaxAmount       *money.Amount `json:"max_amount" swaggertype:"string" example:"100.00"`
+	Type            string        `json:"type" validate:"omitempty,oneof=income expense"`
+	AccountID       *string       `json:"account_id"`
+	CategoryID      *string       `json:"category_id"`
+	SetComment      *string       `json:"set_comment"`
+	AddTags         []string      `json:"add_tags"`
+}
+
+func (r ruleRequest) rule() *model.Rule {
+	return &model.Rule{
+		Name:            r.Name,
+		CommentContains: r.CommentContains,
+		CommentPattern:  r.CommentPattern,
+		MinAmount:       r.MinAmount,
+		MaxAmount:       r.MaxAmount,
+		Type:            r.Type,
+		AccountID:       r.AccountID,
+		CategoryID:      r.CategoryID,
+		SetComment:      r.SetComment,
+		TagNames:        r.AddTags,
+	}
+}
+
+type reorderRulesRequest struct {
+	IDs []string `json:"ids" validate:"required,dive,uuid"`
+}
+
+// Create godoc
+// @Summary Create a rule
+// @Description Adds a rule after the existing ones. A transaction matches when its comment contains comment_contains (ignoring case) and matches the regular expression comment_pattern,
+// @Description its amount is between min_amount and max_amount and it has the given type and account; conditions that are not set are ignored, at least one is required.
+// @Description A matching transaction without a category gets category_id, and set_comment replaces its comment, or only the text matched by comment_pattern with $1 style group references. add_tags are added to it.
+// @Description Rules run on new and imported transactions; for category and comment the first matching rule wins, tags of all matching rules are added
+// @Tags Rules
+// @Accept json
+// @Produce json
+// @Param rule body ruleRequest true "Rule details"
+// @Success 201 {object} model.Rule
+// @Failure 400 {object} map[string]interface{} "error: validation failed, details: list of errors"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /rules [post]
+func (h *RuleHandler) Create(c *gin.Context) {
+	var req ruleRequest
+	if !bindJSON(c, h.validate, h.logger, &req) {
+		return
+	}
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"userID": userID, "name": req.Name}).Info("Creating rule")
+	rule := req.rule()
+	if err := h.svc.Create(userID, rule); err != nil {
+		h.logger.WithError(err).Warn("Failed to create rule")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Rule created successfully")
+	c.JSON(http.StatusCreated, rule)
+}
+
+// List godoc
+// @Summary List rules
+// @Description Retrieves the rules of the authenticated user in the order they are applied
+// @Tags Rules
+// @Produce json
+// @Success 200 {array} model.Rule
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /rules [get]
+func (h *RuleHandler) List(c *gin.Context) {
+	userID := c.GetString("userID")
+	h.logger.WithField("userID", userID).Info("Listing rules")
+	list, err := h.svc.List(userID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list rules")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithField("count", len(list)).Info("Rules listed successfully")
+	c.JSON(http.StatusOK, list)
+}
+
+// Update godoc
+// @Summary Update a rule
+// @Description Replaces the conditions and actions of a rule, keeping its position
+// @Tags Rules
+// @Accept json
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Param rule body ruleRequest true "Rule details"
+// @Success 200 {object} model.Rule
+// @Failure 400 {object} map[string]interface{} "error: validation failed"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: rule not found"
+// @Security BearerAuth
+// @Router /rules/{id} [put]
+func (h *RuleHandler) Update(c *gin.Context) {
+	var req ruleRequest
+	if !bindJSON(c, h.validate, h.logger, &req) {
+		return
+	}
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"ruleID": id, "userID": userID}).Info("Updating rule")
+	rule, err := h.svc.Update(id, userID, req.rule())
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to update rule")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Rule updated successfully")
+	c.JSON(http.StatusOK, rule)
+}
+
+// Delete godoc
+// @Summary Delete a rule
+// @Description Deletes a rule of the authenticated user
+// @Tags Rules
+// @Produce json
+// @Param id path string true "Rule ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: rule not found"
+// @Security BearerAuth
+// @Router /rules/{id} [delete]
+func (h *RuleHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"ruleID": id, "userID": userID}).Info("Deleting rule")
+	if err := h.svc.Delete(id, userID); err != nil {
+		h.logger.WithError(err).Warn("Failed to delete rule")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Rule deleted successfully")
+	c.Status(http.StatusNoContent)
+}
+
+// Reorder godoc
+// @Summary Reorder rules
+// @Description Sets the order rules are applied in. ids must list every rule of the user exactly once
+// @Tags Rules
+// @Accept json
+// @Param order body reorderRulesRequest true "Rule IDs in the new order"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]interface{} "error: validation failed"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /rules/order [put]
+func (h *RuleHandler) Reorder(c *gin.Context) {
+	var req reorderRulesRequest
+	if !bindJSON(c, h.validate, h.logger, &req) {
+		return
+	}
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"userID": userID, "count": len(req.IDs)}).Info("Reordering rules")
+	if err := h.svc.Reorder(userID, req.IDs); err != nil {
+		h.logger.WithError(err).Warn("Failed to reorder rules")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Rules reordered successfully")
+	c.Status(http.StatusNoContent)
+}
+
+// Preview godoc
+// @Summary Preview a rule
+// @Description Runs an unsaved rule against the existing transactions matching the filters and lists what it would change, without storing anything
+// @Tags Rules
+// @Accept json
+// @Produce json
+// @Param rule body ruleRequest true "Rule details"
+// @Param date_from query string false "Start date in RFC3339 format"
+// @Param date_to query string false "End date in RFC3339 format"
+// @Param type query string false "Transaction type: income or expense"
+// @Param account_id query string false "Account ID"
+// @Success 200 {object} service.RulePreview
+// @Failure 400 {object} map[string]interface{} "error: validation failed"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /rules/preview [post]
+func (h *RuleHandler) Preview(c *gin.Context) {
+	var req ruleRequest
+	if !bindJSON(c, h.validate, h.logger, &req) {
+		return
+	}
+	f, err := parseTransactionFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"userID": userID, "filter": f}).Info("Previewing rule")
+	preview, err := h.svc.Preview(userID, req.rule(), f)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to preview rule")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithField("matched", preview.Matched).Info("Rule previewed successfully")
+	c.JSON(http.StatusOK, preview)
+}
+
+// Apply godoc
+// @Summary Apply rules to existing transactions
+// @Description Runs all rules of the authenticated user against the existing transactions matching the filters and stores the changes in one DB transaction
+// @Tags Rules
+// @Produce json
+// @Param date_from query string false "Start date in RFC3339 format"
+// @Param date_to query string false "End date in RFC3339 format"
+// @Param type query string false "Transaction type: income or expense"
+// @Param account_id query string false "Account ID"
+// @Success 200 {object} map[string]int "updated: number of changed transactions"
+// @Failure 400 {object} map[string]string "error: invalid filter"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /rules/apply [post]
+func (h *RuleHandler) Apply(c *gin.Context) {
+	f, err := parseTransactionFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"userID": userID, "filter": f}).Info("Applying rules")
+	updated, err := h.svc.Apply(c.Request.Context(), userID, f)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to apply rules")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithField("updated", updated).Info("Rules applied successfully")
+	c.JSON(http.StatusOK, gin.H{"updated": updated})
+}
@@ -2,6 +2,7 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 	"time"
 
 	"statistic_service/internal/service"
@@ -21,13 +22,13 @@ func NewStatsHandler(s service.TransactionService, logger *logrus.Logger) *Stats
 
 // Summary godoc
 // @Summary Get transactions summary
-// @Description Returns total income and expenses for the authenticated user
+// @Description Returns total income and expenses for the authenticated user, converted to the user's base currency
 // @Tags Statistics
 // @Accept json
 // @Produce json
 // @Param date_from query string false "Start date in RFC3339 format"
 // @Param date_to query string false "End date in RFC3339 format"
-// @Success 200 {object} map[string]float64
+// @Success 200 {object} map[string]string "income, expense: totals as decimal strings"
 // @Failure 401 {object} map[string]string "error: unauthorized"
 // @Security BearerAuth
 // @Router /stats/summary [get]
@@ -55,13 +56,17 @@ func (h *StatsHandler) Summary(c *gin.Context) {
 
 // ByCategory godoc
 // @Summary Get summary by category
-// @Description Returns sum of transactions grouped by category for the authenticated user
+// @Description Returns sum of transactions grouped by category ID, with category names, for the authenticated user.
+// @Description Without depth every category gets only its own transactions; with depth N subcategory totals are rolled up into their ancestor at level N
+// @Description Split transactions count towards the category of each split line with the line amount
 // @Tags Statistics
 // @Accept json
 // @Produce json
 // @Param date_from query string false "Start date in RFC3339 format"
 // @Param date_to query string false "End date in RFC3339 format"
-// @Success 200 {object} map[string]float64
+// @Param depth query int false "Roll up subcategories into categories at this tree level (1 = root categories)"
+// @Success 200 {array} model.CategoryTotal
+// @Failure 400 {object} map[string]string "error: invalid depth"
 // @Failure 401 {object} map[string]string "error: unauthorized"
 // @Security BearerAuth
 // @Router /stats/categories [get]
@@ -76,13 +81,53 @@ func (h *StatsHandler) ByCategory(c *gin.Context) {
 		t, _ := time.Parse(time.RFC3339, tstr)
 		to = &t
 	}
-	h.logger.WithFields(logrus.Fields{"userID": userID, "from": from, "to": to}).Info("Fetching stats by category")
-	data, err := h.svc.ByCategory(userID, from, to)
+	depth := 0
+	if d := c.Query("depth"); d != "" {
+		n, err := strconv.Atoi(d)
+		if err != nil || n < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid depth"})
+			return
+		}
+		depth = n
+	}
+	h.logger.WithFields(logrus.Fields{"userID": userID, "from": from, "to": to, "depth": depth}).Info("Fetching stats by category")
+	data, err := h.svc.ByCategory(userID, from, to, depth)
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to fetch stats by category")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
 		return
 	}
 	h.logger.WithField("categoriesCount", len(data)).Info("Stats by category retrieved successfully")
 	c.JSON(http.StatusOK, data)
 }
+
+// ByTag godoc
+// @Summary Get summary by tag
+// @Description Returns sum of transactions grouped by tag, with tag names, for the authenticated user, converted to the user's base currency.
+// @Description A transaction with several tags counts towards each of them; untagged transactions are returned with a null tag_id
+// @Tags Statistics
+// @Produce json
+// @Param date_from query string false "Start date in RFC3339 format"
+// @Param date_to query string false "End date in RFC3339 format"
+// @Success 200 {array} model.TagTotal
+// @Failure 400 {object} map[string]string "error: invalid date"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /stats/tags [get]
+func (h *StatsHandler) ByTag(c *gin.Context) {
+	userID := c.GetString("userID")
+	f, err := parseTransactionFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithFields(logrus.Fields{"userID": userID, "from": f.From, "to": f.To}).Info("Fetching stats by tag")
+	data, err := h.svc.ByTag(userID, f.From, f.To)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to fetch stats by tag")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithField("tagsCount", len(data)).Info("Stats by tag retrieved successfully")
+	c.JSON(http.StatusOK, data)
+}
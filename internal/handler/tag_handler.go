@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"net/http"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"github.com/sirupsen/logrus"
+)
+
+// TagHandler handles tag management HTTP requests.
+type TagHandler struct {
+	svc      service.TagService
+	validate *validator.Validate
+	logger   *logrus.Logger
+}
+
+// NewTagHandler creates a new TagHandler instance.
+func NewTagHandler(s service.TagService, logger *logrus.Logger) *TagHandler {
+	return &TagHandler{svc: s, validate: validator.New(), logger: logger}
+}
+
+type tagRequest struct {
+	Name string `json:"name" validate:"required,max=50"`
+}
+
+// Create godoc
+// @Summary Create a tag
+// @Description Creates a tag for the authenticated user. Names are stored in lower case. Tags are also created when first used on a transaction or rule
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Param tag body tagRequest true "Tag name"
+// @Success 201 {object} model.Tag
+// @Failure 400 {object} map[string]interface{} "error: validation failed, details: list of errors"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 409 {object} map[string]string "error: tag with this name already exists"
+// @Security BearerAuth
+// @Router /tags [post]
+func (h *TagHandler) Create(c *gin.Context) {
+	var req tagRequest
+	if !bindJSON(c, h.validate, h.logger, &req) {
+		return
+	}
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"userID": userID, "name": req.Name}).Info("Creating tag")
+	tag := &model.Tag{Name: req.Name}
+	if err := h.svc.Create(userID, tag); err != nil {
+		h.logger.WithError(err).Warn("Failed to create tag")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Tag created successfully")
+	c.JSON(http.StatusCreated, tag)
+}
+
+// List godoc
+// @Summary List tags
+// @Description Retrieves the tags of the authenticated user ordered by name
+// @Tags Tags
+// @Produce json
+// @Success 200 {array} model.Tag
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /tags [get]
+func (h *TagHandler) List(c *gin.Context) {
+	userID := c.GetString("userID")
+	h.logger.WithField("userID", userID).Info("Listing tags")
+	list, err := h.svc.List(userID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list tags")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithField("count", len(list)).Info("Tags listed successfully")
+	c.JSON(http.StatusOK, list)
+}
+
+// Rename godoc
+// @Summary Rename a tag
+// @Description Changes the name of a tag on all transactions and rules of the authenticated user
+// @Tags Tags
+// @Accept json
+// @Produce json
+// @Param id path string true "Tag ID"
+// @Param tag body tagRequest true "New name"
+// @Success 200 {object} model.Tag
+// @Failure 400 {object} map[string]interface{} "error: validation failed, details: list of errors"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: tag not found"
+// @Failure 409 {object} map[string]string "error: tag with this name already exists"
+// @Security BearerAuth
+// @Router /tags/{id} [put]
+func (h *TagHandler) Rename(c *gin.Context) {
+	var req tagRequest
+	if !bindJSON(c, h.validate, h.logger, &req) {
+		return
+	}
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"tagID": id, "userID": userID}).Info("Renaming tag")
+	tag, err := h.svc.Rename(id, userID, req.Name)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to rename tag")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Tag renamed successfully")
+	c.JSON(http.StatusOK, tag)
+}
+
+// Delete godoc
+// @Summary Delete a tag
+// @Description Deletes a tag and removes it from all transactions and rules of the authenticated user
+// @Tags Tags
+// @Produce json
+// @Param id path string true "Tag ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: tag not found"
+// @Security BearerAuth
+// @Router /tags/{id} [delete]
+func (h *TagHandler) Delete(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"tagID": id, "userID": userID}).Info("Deleting tag")
+	if err := h.svc.Delete(id, userID); err != nil {
+		h.logger.WithError(err).Warn("Failed to delete tag")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Tag deleted successfully")
+	c.Status(http.StatusNoContent)
+}
@@ -5,6 +5,7 @@ import (
 	"time"
 
 	"statistic_service/internal/service"
+	"statistic_service/pkg/money"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -21,15 +22,17 @@ func NewTimelineHandler(s service.TransactionService, logger *logrus.Logger) *Ti
 
 // Timeline godoc
 // @Summary Get timeline of expenses or income
-// @Description Returns a map of daily totals over a specified time range (week or month) for graph/chart usage
+// @Description Returns a map of daily totals in the user's base currency over a specified time range (week or month) for graph/chart usage.
+// @Description With category_id only that category is counted, with the category's share of split transactions
 // @Tags Statistics
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param type query string false "Transaction type: expense or income" default(expense)
 // @Param range query string false "Time range: week or month" default(month)
-// @Success 200 {object} map[string]float64
-// @Failure 400 {object} map[string]string "Invalid range"
+// @Param category_id query string false "Category ID"
+// @Success 200 {object} map[string]string "date: total as decimal string"
+// @Failure 400 {object} map[string]string "Invalid range or category_id"
 // @Failure 500 {object} map[string]string "Internal error"
 // @Router /stats/timeline [get]
 func (h *TimelineHandler) Timeline(c *gin.Context) {
@@ -50,21 +53,21 @@ func (h *TimelineHandler) Timeline(c *gin.Context) {
 		return
 	}
 
-	list, err := h.svc.List(userID, &startDate, &now, txType)
+	list, err := h.svc.List(userID, &startDate, &now, txType, c.Query("category_id"))
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to get timeline transactions")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
 		return
 	}
 
-	result := make(map[string]float64)
+	result := make(map[string]money.Amount)
 	for d := startDate; d.Before(now) || d.Equal(now); d = d.AddDate(0, 0, 1) {
 		result[d.Format("2006-01-02")] = 0
 	}
 
 	for _, tx := range list {
 		date := tx.CreatedAt.Format("2006-01-02")
-		result[date] += tx.Amount
+		result[date] = result[date].Add(tx.BaseAmount)
 	}
 
 	c.JSON(http.StatusOK, result)
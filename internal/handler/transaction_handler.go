@@ -2,11 +2,16 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"statistic_service/internal/model"
+	"statistic_service/internal/repository"
 	"statistic_service/internal/service"
+	"statistic_service/pkg/money"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -23,14 +28,22 @@ func NewTransactionHandler(s service.TransactionService, logger *logrus.Logger)
 
 // Create godoc
 // @Summary Create a new transaction
-// @Description Adds a new transaction (expense or income) for the authenticated user
+// @Description Adds a new transaction (expense or income) for the authenticated user. The optional category_id must reference a category of the same user and type.
+// @Description created_at defaults to now. tags lists tag names, missing tags are created. The user's rules are applied.
+// @Description splits divide the amount between categories: at least two lines whose amounts add up to the amount, and no category_id on the transaction itself.
+// @Description A transaction with the same amount, currency, type and category and a similar comment created within the duplicate window is a likely duplicate.
+// @Description By default it is stored with duplicate_of set; with on_duplicate=reject it is refused with 409 and the candidates
 // @Tags Transactions
 // @Accept json
 // @Produce json
 // @Param transaction body model.Transaction true "Transaction details"
-// @Success 201 "Created"
+// @Param on_duplicate query string false "flag, reject or ignore" default(flag)
+// @Param Idempotency-Key header string false "Key that makes retries return the original response; reusing it with a different request gives 422"
+// @Success 201 {object} map[string]string "id and duplicate_of"
 // @Failure 400 {object} map[string]string "error: bad request"
 // @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 409 {object} map[string]interface{} "error and candidates"
+// @Failure 422 {object} map[string]string "error: idempotency key was already used with a different request"
 // @Failure 500 {object} map[string]string "error: internal server error"
 // @Security BearerAuth
 // @Router /transactions [post]
@@ -41,56 +54,88 @@ func (h *TransactionHandler) Create(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request format"})
 		return
 	}
+	input.RecurringID = nil
+	input.ExternalID = nil
 	userID := c.GetString("userID")
 	h.logger.WithFields(logrus.Fields{"userID": userID, "amount": input.Amount, "type": input.Type}).Info("Creating transaction")
-	if err := h.svc.Create(userID, &input); err != nil {
+	onDuplicate := service.DuplicatePolicy(c.DefaultQuery("on_duplicate", string(service.DuplicateFlag)))
+	if err := h.svc.Create(c.Request.Context(), userID, &input, onDuplicate); err != nil {
+		var dupErr *service.DuplicateError
+		if errors.As(err, &dupErr) {
+			h.logger.WithField("candidates", len(dupErr.Candidates)).Warn("Duplicate transaction rejected")
+			c.JSON(http.StatusConflict, gin.H{"error": err.Error(), "candidates": dupErr.Candidates})
+			return
+		}
 		h.logger.WithError(err).Error("Failed to create transaction")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
 		return
 	}
-	h.logger.Info("Transaction created successfully")
-	c.Status(http.StatusCreated)
+	if input.DuplicateOf != nil {
+		h.logger.WithField("duplicateOf", *input.DuplicateOf).Info("Transaction created and flagged as duplicate")
+	} else {
+		h.logger.Info("Transaction created successfully")
+	}
+	c.JSON(http.StatusCreated, gin.H{"id": input.ID, "duplicate_of": input.DuplicateOf})
 }
 
 // List godoc
 // @Summary List transactions
-// @Description Retrieves transactions for the authenticated user, with optional filters
+// @Description Retrieves one page of transactions for the authenticated user, ordered by creation time, with optional filters.
+// @Description Pass next_cursor from the previous response as cursor to get the following page
 // @Tags Transactions
 // @Accept json
 // @Produce json
 // @Param date_from query string false "Start date in RFC3339 format"
 // @Param date_to query string false "End date in RFC3339 format"
 // @Param type query string false "Transaction type: income or expense"
-// @Success 200 {array} model.Transaction
+// @Param category_id query string false "Category ID, also matching split transactions with a line in it"
+// @Param account_id query string false "Account ID"
+// @Param amount_min query string false "Minimum amount, decimal with up to 2 places"
+// @Param amount_max query string false "Maximum amount, decimal with up to 2 places"
+// @Param q query string false "Text to search for in the comment"
+// @Param tags query string false "Comma-separated tag names the transactions must all have"
+// @Param sort query string false "Sort direction by creation time: asc or desc" default(desc)
+// @Param limit query int false "Page size" default(50)
+// @Param cursor query string false "Opaque cursor from the previous page"
+// @Success 200 {object} service.TransactionPage
+// @Failure 400 {object} map[string]string "error: invalid filter, limit or cursor"
 // @Failure 401 {object} map[string]string "error: unauthorized"
 // @Security BearerAuth
 // @Router /transactions [get]
 func (h *TransactionHandler) List(c *gin.Context) {
 	userID := c.GetString("userID")
-	var from, to *time.Time
-	if f := c.Query("date_from"); f != "" {
-		t, _ := time.Parse(time.RFC3339, f)
-		from = &t
-	}
-	if tstr := c.Query("date_to"); tstr != "" {
-		t, _ := time.Parse(time.RFC3339, tstr)
-		to = &t
-	}
-	txType := c.Query("type")
-	h.logger.WithFields(logrus.Fields{"userID": userID, "from": from, "to": to, "type": txType}).Info("Listing transactions")
-	list, err := h.svc.List(userID, from, to, txType)
+	f, err := parseTransactionFilter(c)
+	if err != nil {
+		h.logger.WithError(err).Warn("Invalid transaction filter")
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	sort := c.DefaultQuery("sort", "desc")
+	if sort != "asc" && sort != "desc" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid sort"})
+		return
+	}
+	limit := 0
+	if l := c.Query("limit"); l != "" {
+		if limit, err = strconv.Atoi(l); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+			return
+		}
+	}
+	h.logger.WithFields(logrus.Fields{"userID": userID, "filter": f, "sort": sort, "limit": limit}).Info("Listing transactions")
+	page, err := h.svc.ListPage(userID, f, c.Query("cursor"), limit, sort == "desc")
 	if err != nil {
 		h.logger.WithError(err).Error("Failed to list transactions")
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
 		return
 	}
-	h.logger.WithField("count", len(list)).Info("Transactions listed successfully")
-	c.JSON(http.StatusOK, list)
+	h.logger.WithField("count", len(page.Items)).Info("Transactions listed successfully")
+	c.JSON(http.StatusOK, page)
 }
 
 // Update godoc
 // @Summary Update a transaction
-// @Description Updates an existing transaction by ID for the authenticated user
+// @Description Updates an existing transaction by ID for the authenticated user. Without tags or splits in the body they stay unchanged; an empty splits list removes the split
 // @Tags Transactions
 // @Accept json
 // @Produce json
@@ -99,7 +144,7 @@ func (h *TransactionHandler) List(c *gin.Context) {
 // @Success 200 "OK"
 // @Failure 400 {object} map[string]string "error: bad request"
 // @Failure 401 {object} map[string]string "error: unauthorized"
-// @Failure 403 {object} map[string]string "error: forbidden"
+// @Failure 404 {object} map[string]string "error: transaction not found"
 // @Security BearerAuth
 // @Router /transactions/{id} [put]
 func (h *TransactionHandler) Update(c *gin.Context) {
@@ -112,9 +157,9 @@ func (h *TransactionHandler) Update(c *gin.Context) {
 		return
 	}
 	h.logger.WithFields(logrus.Fields{"transactionID": id, "userID": userID}).Info("Updating transaction")
-	if err := h.svc.Update(id, userID, &input); err != nil {
+	if err := h.svc.Update(c.Request.Context(), id, userID, &input); err != nil {
 		h.logger.WithError(err).Error("Failed to update transaction")
-		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
 		return
 	}
 	h.logger.Info("Transaction updated successfully")
@@ -123,24 +168,215 @@ func (h *TransactionHandler) Update(c *gin.Context) {
 
 // Delete godoc
 // @Summary Delete a transaction
-// @Description Deletes an existing transaction by ID for the authenticated user
+// @Description Moves a transaction of the authenticated user to the trash, where it is left out of lists and statistics and can be restored until it is purged.
+// @Description Deleting one leg of a transfer deletes both
 // @Tags Transactions
 // @Produce json
 // @Param id path string true "Transaction ID"
 // @Success 204 "No Content"
 // @Failure 401 {object} map[string]string "error: unauthorized"
-// @Failure 403 {object} map[string]string "error: forbidden"
+// @Failure 404 {object} map[string]string "error: transaction not found"
 // @Security BearerAuth
 // @Router /transactions/{id} [delete]
 func (h *TransactionHandler) Delete(c *gin.Context) {
 	id := c.Param("id")
 	userID := c.GetString("userID")
 	h.logger.WithFields(logrus.Fields{"transactionID": id, "userID": userID}).Info("Deleting transaction")
-	if err := h.svc.Delete(id, userID); err != nil {
+	if err := h.svc.Delete(c.Request.Context(), id, userID); err != nil {
 		h.logger.WithError(err).Error("Failed to delete transaction")
-		c.JSON(http.StatusForbidden, gin.H{"error": err.Error()})
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
 		return
 	}
 	h.logger.Info("Transaction deleted successfully")
 	c.Status(http.StatusNoContent)
 }
+
+// Trash godoc
+// @Summary List deleted transactions
+// @Description Lists the transactions of the authenticated user that are in the trash, most recently deleted first. They are purged permanently, with their attachments, after the retention period
+// @Tags Transactions
+// @Produce json
+// @Success 200 {array} model.Transaction
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /transactions/trash [get]
+func (h *TransactionHandler) Trash(c *gin.Context) {
+	userID := c.GetString("userID")
+	h.logger.WithField("userID", userID).Info("Listing deleted transactions")
+	list, err := h.svc.Trash(userID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list deleted transactions")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithField("count", len(list)).Info("Deleted transactions listed successfully")
+	c.JSON(http.StatusOK, list)
+}
+
+// Restore godoc
+// @Summary Restore a deleted transaction
+// @Description Takes a transaction of the authenticated user out of the trash. Restoring one leg of a transfer restores both
+// @Tags Transactions
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Success 204 "No Content"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: transaction not found in trash"
+// @Security BearerAuth
+// @Router /transactions/{id}/restore [post]
+func (h *TransactionHandler) Restore(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"transactionID": id, "userID": userID}).Info("Restoring transaction")
+	if err := h.svc.Restore(c.Request.Context(), id, userID); err != nil {
+		h.logger.WithError(err).Warn("Failed to restore transaction")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Transaction restored successfully")
+	c.Status(http.StatusNoContent)
+}
+
+// History godoc
+// @Summary Transaction history
+// @Description Lists every change of a transaction of the authenticated user, oldest first: who made it, when, in which request (X-Request-ID) and the values of the changed fields before and after.
+// @Description The history stays available after the transaction is deleted or purged
+// @Tags Transactions
+// @Produce json
+// @Param id path string true "Transaction ID"
+// @Success 200 {array} model.TransactionVersion
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: transaction not found"
+// @Security BearerAuth
+// @Router /transactions/{id}/history [get]
+func (h *TransactionHandler) History(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"transactionID": id, "userID": userID}).Info("Listing transaction history")
+	versions, err := h.svc.History(id, userID)
+	if err != nil {
+		h.logger.WithError(err).Warn("Failed to list transaction history")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithField("count", len(versions)).Info("Transaction history listed successfully")
+	c.JSON(http.StatusOK, versions)
+}
+
+// Duplicates godoc
+// @Summary List flagged duplicates
+// @Description Lists the transactions flagged as likely duplicates of the authenticated user, each with the transaction it duplicates
+// @Tags Transactions
+// @Produce json
+// @Success 200 {array} service.DuplicatePair
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Security BearerAuth
+// @Router /transactions/duplicates [get]
+func (h *TransactionHandler) Duplicates(c *gin.Context) {
+	userID := c.GetString("userID")
+	h.logger.WithField("userID", userID).Info("Listing duplicate transactions")
+	pairs, err := h.svc.Duplicates(userID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to list duplicate transactions")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithField("count", len(pairs)).Info("Duplicate transactions listed successfully")
+	c.JSON(http.StatusOK, pairs)
+}
+
+// Merge godoc
+// @Summary Merge a duplicate
+// @Description Moves a flagged transaction to the trash and keeps the transaction it duplicates, filling in its missing category, account, comment and bank reference and moving over its tags and attachments
+// @Tags Transactions
+// @Produce json
+// @Param id path string true "Flagged transaction ID"
+// @Success 200 {object} model.Transaction "the kept transaction"
+// @Failure 400 {object} map[string]string "error: transaction is not flagged as a duplicate"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: transaction not found"
+// @Security BearerAuth
+// @Router /transactions/{id}/merge [post]
+func (h *TransactionHandler) Merge(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"transactionID": id, "userID": userID}).Info("Merging duplicate transaction")
+	kept, err := h.svc.Merge(c.Request.Context(), id, userID)
+	if err != nil {
+		h.logger.WithError(err).Error("Failed to merge duplicate transaction")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.WithField("keptID", kept.ID).Info("Duplicate transaction merged successfully")
+	c.JSON(http.StatusOK, kept)
+}
+
+// Dismiss godoc
+// @Summary Dismiss a duplicate flag
+// @Description Marks a flagged transaction as not a duplicate
+// @Tags Transactions
+// @Param id path string true "Flagged transaction ID"
+// @Success 204 "No Content"
+// @Failure 400 {object} map[string]string "error: transaction is not flagged as a duplicate"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 404 {object} map[string]string "error: transaction not found"
+// @Security BearerAuth
+// @Router /transactions/{id}/duplicate [delete]
+func (h *TransactionHandler) Dismiss(c *gin.Context) {
+	id := c.Param("id")
+	userID := c.GetString("userID")
+	h.logger.WithFields(logrus.Fields{"transactionID": id, "userID": userID}).Info("Dismissing duplicate flag")
+	if err := h.svc.Dismiss(c.Request.Context(), id, userID); err != nil {
+		h.logger.WithError(err).Error("Failed to dismiss duplicate flag")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	h.logger.Info("Duplicate flag dismissed successfully")
+	c.Status(http.StatusNoContent)
+}
+
+// parseTransactionFilter reads the transaction filter query parameters.
+func parseTransactionFilter(c *gin.Context) (repository.TransactionFilter, error) {
+	f := repository.TransactionFilter{
+		Type:       c.Query("type"),
+		CategoryID: c.Query("category_id"),
+		AccountID:  c.Query("account_id"),
+		Search:     c.Query("q"),
+	}
+	if v := c.Query("tags"); v != "" {
+		for _, tag := range strings.Split(v, ",") {
+			if tag = strings.ToLower(strings.TrimSpace(tag)); tag != "" {
+				f.Tags = append(f.Tags, tag)
+			}
+		}
+	}
+	if v := c.Query("date_from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return f, errors.New("invalid date_from")
+		}
+		f.From = &t
+	}
+	if v := c.Query("date_to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return f, errors.New("invalid date_to")
+		}
+		f.To = &t
+	}
+	if v := c.Query("amount_min"); v != "" {
+		a, err := money.Parse(v)
+		if err != nil {
+			return f, errors.New("invalid amount_min")
+		}
+		f.MinAmount = &a
+	}
+	if v := c.Query("amount_max"); v != "" {
+		a, err := money.Parse(v)
+		if err != nil {
+			return f, errors.New("invalid amount_max")
+		}
+		f.MaxAmount = &a
+	}
+	return f, nil
+}
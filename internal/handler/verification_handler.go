@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"net/http"
+
+	"statistic_service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+// VerificationHandler handles email verification HTTP requests.
+type VerificationHandler struct {
+	svc    service.VerificationService
+	logger *logrus.Logger
+}
+
+// NewVerificationHandler creates a new VerificationHandler instance.
+func NewVerificationHandler(s service.VerificationService, logger *logrus.Logger) *VerificationHandler {
+	return &VerificationHandler{svc: s, logger: logger}
+}
+
+// Verify godoc
+// @Summary Verify an email address
+// @Description Marks the user's email address verified with the token from the link emailed on registration. The token can be used once
+// @Tags Auth
+// @Produce json
+// @Param token query string true "Verification token"
+// @Success 200 {object} map[string]string "status: verified"
+// @Failure 400 {object} map[string]string "error: invalid or expired verification token"
+// @Router /verify-email [get]
+func (h *VerificationHandler) Verify(c *gin.Context) {
+	token := c.Query("token")
+	if token == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "token is required"})
+		return
+	}
+	if err := h.svc.Verify(token); err != nil {
+		h.logger.WithError(err).Warn("Email verification failed")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"status": "verified"})
+}
+
+// Resend godoc
+// @Summary Resend the verification email
+// @Description Emails a new verification link to the authenticated user, at most once a minute by default. Earlier links stop working
+// @Tags Auth
+// @Produce json
+// @Security BearerAuth
+// @Success 202 {object} map[string]string "status: accepted"
+// @Failure 401 {object} map[string]string "error: unauthorized"
+// @Failure 409 {object} map[string]string "error: email address is already verified"
+// @Failure 429 {object} map[string]string "error: verification email was sent recently"
+// @Router /verify-email/resend [post]
+func (h *VerificationHandler) Resend(c *gin.Context) {
+	if err := h.svc.Resend(c.Request.Context(), c.GetString("userID")); err != nil {
+		h.logger.WithError(err).Warn("Failed to resend verification email")
+		c.JSON(statusForError(err), gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusAccepted, gin.H{"status": "accepted"})
+}
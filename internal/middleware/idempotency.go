@@ -0,0 +1,129 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"statistic_service/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// IdempotencyKeyHeader carries the client's key for a request that may be retried.
+	IdempotencyKeyHeader = "Idempotency-Key"
+	// IdempotentReplayedHeader marks a response replayed from an earlier request.
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+	// maxIdempotencyKeyLength bounds the length of a key.
+	maxIdempotencyKeyLength = 255
+	// maxIdempotentBodySize bounds the request body kept in memory to
+	// fingerprint the request.
+	maxIdempotentBodySize = 8 << 20
+)
+
+// recordingWriter keeps a copy of the response body.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(b []byte) (int, error) {
+	w.body.Write(b)
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
+
+// Idempotency делает POST-запросы с заголовком Idempotency-Key повторяемыми:
+// повтор с тем же ключом и телом получает сохранённый ответ, с другим телом —
+// 422. Ответы с ошибкой сервера не сохраняются. Должен стоять после JWTAuth
+func Idempotency(svc service.IdempotencyService, logger *logrus.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "Idempotency-Key is too long"})
+			return
+		}
+		body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxIdempotentBodySize+1))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request body"})
+			return
+		}
+		if len(body) > maxIdempotentBodySize {
+			c.AbortWithStatusJSON(http.StatusRequestEntityTooLarge, gin.H{"error": "request body too large"})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		userID := c.GetString("userID")
+		log := logger.WithFields(logrus.Fields{"userID": userID, "idempotencyKey": key})
+		stored, err := svc.Begin(userID, key, fingerprint(c.Request, c.FullPath(), body))
+		switch {
+		case errors.Is(err, service.ErrIdempotencyKeyReused):
+			log.Warn("Idempotency key reused with a different request")
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+			return
+		case errors.Is(err, service.ErrIdempotencyKeyInUse):
+			c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		case err != nil:
+			log.WithError(err).Error("Failed to check idempotency key")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		case stored != nil:
+			log.Info("Replaying stored response")
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(stored.StatusCode, stored.ContentType, stored.Body)
+			c.Abort()
+			return
+		}
+
+		// The key is released unless a response is stored, also when the
+		// handler panics.
+		completed := false
+		defer func() {
+			if !completed {
+				if err := svc.Abort(userID, key); err != nil {
+					log.WithError(err).Error("Failed to release idempotency key")
+				}
+			}
+		}()
+		w := &recordingWriter{ResponseWriter: c.Writer}
+		c.Writer = w
+		c.Next()
+
+		if w.Status() < http.StatusInternalServerError {
+			if err := svc.Complete(userID, key, w.Status(), w.Header().Get("Content-Type"), w.body.Bytes()); err != nil {
+				log.WithError(err).Error("Failed to store idempotent response")
+				return
+			}
+			completed = true
+		}
+	}
+}
+
+// fingerprint identifies a request by its route, query and body. The random
+// boundary of a multipart body is left out, so a client may rebuild the body
+// on retry.
+func fingerprint(r *http.Request, route string, body []byte) string {
+	h := sha256.New()
+	io.WriteString(h, r.Method+" "+route+"?"+r.URL.RawQuery+"\n")
+	if _, params, err := mime.ParseMediaType(r.Header.Get("Content-Type")); err == nil && params["boundary"] != "" {
+		body = bytes.ReplaceAll(body, []byte(params["boundary"]), nil)
+	}
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
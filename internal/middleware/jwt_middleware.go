@@ -8,8 +8,14 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-// JWTAuth проверяет и разбирает токен, сохраняет userID в контекст
-func JWTAuth(secret string) gin.HandlerFunc {
+// SessionChecker reports whether the session of an access token was revoked.
+type SessionChecker interface {
+	SessionRevoked(sessionID string) (bool, error)
+}
+
+// JWTAuth проверяет и разбирает токен, отклоняет токены завершённых сессий,
+// сохраняет userID и sessionID в контекст
+func JWTAuth(secret string, sessions SessionChecker) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" || !strings.HasPrefix(authHeader, "Bearer ") {
@@ -40,7 +46,22 @@ func JWTAuth(secret string) gin.HandlerFunc {
 			return
 		}
 
+		// Токены, выданные до появления сессий, не содержат sid
+		sessionID, _ := claims["sid"].(string)
+		if sessionID != "" {
+			revoked, err := sessions.SessionRevoked(sessionID)
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to check session"})
+				return
+			}
+			if revoked {
+				c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session has been logged out"})
+				return
+			}
+		}
+
 		c.Set("userID", userID)
+		c.Set("sessionID", sessionID)
 		c.Next()
 	}
 }
@@ -0,0 +1,51 @@
+package middleware
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Features that the verification policy can restrict to verified users.
+const (
+	FeatureExport      = "export"
+	FeatureImport      = "import"
+	FeatureAttachments = "attachments"
+)
+
+// VerificationChecker reports whether a user's email address is verified.
+type VerificationChecker interface {
+	Verified(userID string) (bool, error)
+}
+
+// RequireVerified пропускает только пользователей с подтверждённым email.
+// Должен стоять после JWTAuth
+func RequireVerified(checker VerificationChecker) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		verified, err := checker.Verified(c.GetString("userID"))
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "failed to check email verification"})
+			return
+		}
+		if !verified {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "verify your email address to use this feature"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// VerificationPolicy возвращает для функции RequireVerified, если она есть
+// в restricted, и пропускающий всё обработчик в остальных случаях
+func VerificationPolicy(checker VerificationChecker, restricted []string) func(feature string) gin.HandlerFunc {
+	require := RequireVerified(checker)
+	allow := func(c *gin.Context) { c.Next() }
+	return func(feature string) gin.HandlerFunc {
+		for _, f := range restricted {
+			if f == feature {
+				return require
+			}
+		}
+		return allow
+	}
+}
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"statistic_service/pkg/money"
+)
+
+// Account types.
+const (
+	AccountCash    = "cash"
+	AccountCard    = "card"
+	AccountSavings = "savings"
+	AccountCredit  = "credit"
+)
+
+// Account is a place where a user keeps money. Transactions tied to an
+// account are in the account's currency.
+type Account struct {
+	ID             string       `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	UserID         string       `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name           string       `gorm:"not null" json:"name"`
+	Type           string       `gorm:"type:text;not null" json:"type"`
+	Currency       string       `gorm:"type:char(3);not null" json:"currency"`
+	OpeningBalance money.Amount `gorm:"type:numeric(14,2);not null;default:0" json:"opening_balance" swaggertype:"string" example:"100.00"`
+	CreatedAt      time.Time    `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -0,0 +1,17 @@
+package model
+
+import "time"
+
+// Attachment is a file such as a receipt image kept with a transaction.
+// The content lives in blob storage under StorageKey.
+type Attachment struct {
+	ID            string       `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	TransactionID string       `gorm:"type:uuid;not null;index" json:"transaction_id"`
+	Transaction   *Transaction `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	UserID        string       `gorm:"type:uuid;not null" json:"user_id"`
+	FileName      string       `gorm:"type:text;not null" json:"file_name"`
+	ContentType   string       `gorm:"type:text;not null" json:"content_type"`
+	Size          int64        `gorm:"not null" json:"size"`
+	StorageKey    string       `gorm:"type:text;not null" json:"-"`
+	CreatedAt     time.Time    `gorm:"autoCreateTime" json:"created_at"`
+}
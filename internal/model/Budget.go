@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+
+	"statistic_service/pkg/money"
+)
+
+// Budget periods.
+const (
+	BudgetWeekly  = "weekly"
+	BudgetMonthly = "monthly"
+)
+
+// Budget is a spending limit for an expense category and its subcategories
+// per period, in the user's base currency. With Rollover the unused part of
+// each period since the budget was created is added to the next one.
+type Budget struct {
+	ID         string       `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	UserID     string       `gorm:"type:uuid;not null;index" json:"user_id"`
+	CategoryID string       `gorm:"type:uuid;not null" json:"category_id"`
+	Category   *Category    `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	Period     string       `gorm:"type:text;not null" json:"period"`
+	Limit      money.Amount `gorm:"column:limit_amount;type:numeric(14,2);not null" json:"limit" swaggertype:"string" example:"300.00"`
+	Rollover   bool         `gorm:"not null;default:false" json:"rollover"`
+	CreatedAt  time.Time    `gorm:"autoCreateTime" json:"created_at"`
+}
@@ -2,10 +2,17 @@ package model
 
 import "time"
 
+// MaxCategoryDepth bounds the number of levels in a category tree.
+const MaxCategoryDepth = 5
+
+// Category names are unique among siblings, ignoring case. The index
+// matches the one created by the migrations.
 type Category struct {
-	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	UserID    string    `gorm:"type:uuid;not null;index"`
-	Name      string    `gorm:"not null"`
-	Type      string    `gorm:"not null"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
+	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	UserID    string    `gorm:"type:uuid;not null;index;uniqueIndex:categories_user_id_parent_id_name_key,priority:1" json:"user_id"`
+	ParentID  *string   `gorm:"type:uuid;index;uniqueIndex:categories_user_id_parent_id_name_key,priority:2,expression:COALESCE(parent_id\\, '00000000-0000-0000-0000-000000000000')" json:"parent_id"`
+	Parent    *Category `json:"-"`
+	Name      string    `gorm:"not null;uniqueIndex:categories_user_id_parent_id_name_key,priority:3,expression:lower(name)" json:"name"`
+	Type      string    `gorm:"not null" json:"type"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
 }
@@ -0,0 +1,13 @@
+package model
+
+import "time"
+
+// EmailVerificationToken confirms that a user owns the email address. Only
+// the keyed hash of the token is stored; it is deleted once used.
+type EmailVerificationToken struct {
+	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID    string    `gorm:"type:uuid;not null;index"`
+	TokenHash string    `gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	CreatedAt time.Time
+}
@@ -0,0 +1,15 @@
+package model
+
+import "time"
+
+// RateReferenceCurrency is the currency all exchange rates are quoted against,
+// following the ECB reference rates.
+const RateReferenceCurrency = "EUR"
+
+// ExchangeRate is the number of Currency units per one RateReferenceCurrency
+// unit, effective from Date until the next published rate.
+type ExchangeRate struct {
+	Currency string    `gorm:"primaryKey;type:char(3)" json:"currency"`
+	Date     time.Time `gorm:"primaryKey;type:date" json:"date"`
+	Rate     float64   `gorm:"type:numeric(20,10);not null" json:"rate"`
+}
@@ -0,0 +1,19 @@
+package model
+
+import "time"
+
+// IdempotencyKey keeps the response to a request sent with an
+// Idempotency-Key header, so a retry with the same key gets the stored
+// response instead of being processed again. Fingerprint identifies the
+// request the key was first used with. StatusCode is 0 while that request
+// is still being processed.
+type IdempotencyKey struct {
+	UserID      string    `gorm:"primaryKey;type:uuid" json:"-"`
+	Key         string    `gorm:"primaryKey;type:text" json:"key"`
+	Fingerprint string    `gorm:"type:text;not null" json:"-"`
+	StatusCode  int       `gorm:"not null;default:0" json:"status_code"`
+	ContentType string    `gorm:"type:text" json:"-"`
+	Body        []byte    `gorm:"type:bytea" json:"-"`
+	CreatedAt   time.Time `gorm:"autoCreateTime" json:"created_at"`
+	ExpiresAt   time.Time `gorm:"not null;index" json:"expires_at"`
+}
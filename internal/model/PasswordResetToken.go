@@ -0,0 +1,14 @@
+package model
+
+import "time"
+
+// PasswordResetToken lets a user who forgot the password set a new one. Only
+// the keyed hash of the token is stored; a token can be used once.
+type PasswordResetToken struct {
+	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID    string    `gorm:"type:uuid;not null;index"`
+	TokenHash string    `gorm:"not null;uniqueIndex"`
+	ExpiresAt time.Time `gorm:"not null"`
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
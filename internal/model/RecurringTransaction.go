@@ -0,0 +1,41 @@
+package model
+
+import (
+	"time"
+
+	"statistic_service/pkg/money"
+	"statistic_service/pkg/recurrence"
+)
+
+// RecurringTransaction is a template the scheduler turns into transactions
+// on every occurrence of its schedule.
+type RecurringTransaction struct {
+	ID         string       `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	UserID     string       `gorm:"type:uuid;not null;index" json:"user_id"`
+	Amount     money.Amount `gorm:"type:numeric(14,2);not null" json:"amount" swaggertype:"string" example:"12.50"`
+	Currency   string       `gorm:"type:char(3);not null" json:"currency"`
+	Type       string       `gorm:"type:text;not null" json:"type"`
+	CategoryID *string      `gorm:"type:uuid" json:"category_id"`
+	Category   *Category    `gorm:"constraint:OnDelete:SET NULL" json:"-"`
+	AccountID  *string      `gorm:"type:uuid" json:"account_id"`
+	Account    *Account     `gorm:"constraint:OnDelete:SET NULL" json:"-"`
+	Comment    string       `gorm:"type:text" json:"comment"`
+
+	Frequency  string     `gorm:"type:text;not null" json:"frequency"`
+	Interval   int        `gorm:"not null;default:1" json:"interval"`
+	DayOfMonth int        `gorm:"not null;default:0" json:"day_of_month"`
+	StartAt    time.Time  `gorm:"not null" json:"start_at"`
+	EndAt      *time.Time `json:"end_at"`
+	// Count limits the number of occurrences; zero means no limit.
+	Count int `gorm:"not null;default:0" json:"count"`
+	// Occurrences is the number of transactions created so far.
+	Occurrences int `gorm:"not null;default:0" json:"occurrences"`
+	// NextAt is the time of the next occurrence, nil once the schedule ended.
+	NextAt    *time.Time `gorm:"index" json:"next_at"`
+	CreatedAt time.Time  `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// Rule returns the schedule of the template.
+func (r *RecurringTransaction) Rule() recurrence.Rule {
+	return recurrence.Rule{Frequency: r.Frequency, Interval: r.Interval, DayOfMonth: r.DayOfMonth, Start: r.StartAt}
+}
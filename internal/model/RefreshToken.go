@@ -2,10 +2,29 @@ package model
 
 import "time"
 
+// RefreshToken is one token of a login session. Refreshing rotates the token:
+// a new token is issued in the same family and the old one is kept, marked
+// rotated, so that a replay of it can be detected. The family ID identifies
+// the session in access tokens.
 type RefreshToken struct {
-	ID        string    `gorm:"primaryKey"`
-	UserID    string    `gorm:"not null"`
-	Token     string    `gorm:"not null"`
+	ID       string `gorm:"primaryKey"`
+	FamilyID string `gorm:"not null;index"`
+	UserID   string `gorm:"not null;index"`
+	// TokenHash is the keyed hash of the token; the token itself is not stored.
+	TokenHash string `gorm:"not null;uniqueIndex"`
+	// RotatedAt is set once the token was exchanged for a new one.
+	RotatedAt *time.Time
 	ExpiresAt time.Time `gorm:"not null"`
+	// CreatedAt is when the session logged in; it is carried over on rotation.
 	CreatedAt time.Time
-}
\ No newline at end of file
+	// UserAgent and IP are those of the last login or refresh.
+	UserAgent string `gorm:"type:text"`
+	IP        string `gorm:"type:text"`
+}
+
+// RevokedSession denies the access tokens of a session that was logged out
+// until they expire.
+type RevokedSession struct {
+	SessionID string    `gorm:"primaryKey"`
+	ExpiresAt time.Time `gorm:"not null;index"`
+}
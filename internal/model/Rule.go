@@ -0,0 +1,46 @@
+package model
+
+import (
+	"time"
+
+	"statistic_service/pkg/money"
+
+	"gorm.io/gorm"
+)
+
+// Rule categorizes transactions automatically. A transaction matches when it
+// meets every condition that is set: its comment contains CommentContains
+// (ignoring case) and matches the regular expression CommentPattern, its
+// amount is within MinAmount and MaxAmount, and it has the given Type and
+// AccountID. Rules are applied in Position order.
+type Rule struct {
+	ID              string        `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	UserID          string        `gorm:"type:uuid;not null;index" json:"user_id"`
+	Name            string        `gorm:"type:text;not null" json:"name"`
+	Position        int           `gorm:"not null;default:0" json:"position"`
+	CommentContains string        `gorm:"type:text" json:"comment_contains,omitempty"`
+	CommentPattern  string        `gorm:"type:text" json:"comment_pattern,omitempty"`
+	MinAmount       *money.Amount `gorm:"type:numeric(14,2)" json:"min_amount,omitempty" swaggertype:"string" example:"10.00"`
+	MaxAmount       *money.Amount `gorm:"type:numeric(14,2)" json:"max_amount,omitempty" swaggertype:"string" example:"100.00"`
+	Type            string        `gorm:"type:text" json:"type,omitempty"`
+	AccountID       *string       `gorm:"type:uuid" json:"account_id,omitempty"`
+	Account         *Account      `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	// CategoryID is assigned to matching transactions without a category.
+	CategoryID *string   `gorm:"type:uuid" json:"category_id,omitempty"`
+	Category   *Category `gorm:"constraint:OnDelete:CASCADE" json:"-"`
+	// SetComment replaces the comment of matching transactions. With a
+	// CommentPattern only the matched text is replaced and $1 style
+	// references to its groups are expanded.
+	SetComment *string `gorm:"type:text" json:"set_comment,omitempty"`
+	// Tags are added to matching transactions.
+	Tags []Tag `gorm:"many2many:rule_tags;constraint:OnDelete:CASCADE" json:"-"`
+	// TagNames are the names of Tags as sent and returned by the API.
+	TagNames  []string  `gorm:"-" json:"add_tags,omitempty"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// AfterFind fills TagNames from the preloaded Tags.
+func (r *Rule) AfterFind(*gorm.DB) error {
+	r.TagNames = tagNames(r.Tags)
+	return nil
+}
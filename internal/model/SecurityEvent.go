@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+// Security event types.
+const (
+	// SecurityEventRefreshTokenReuse is recorded when a rotated-out refresh
+	// token is presented again and its session is revoked.
+	SecurityEventRefreshTokenReuse = "refresh_token_reuse"
+)
+
+// SecurityEvent records a suspicious event on a user's account.
+type SecurityEvent struct {
+	ID        string `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	UserID    string `gorm:"type:uuid;not null;index"`
+	Type      string `gorm:"type:text;not null"`
+	SessionID string `gorm:"type:text"`
+	// UserAgent and IP are those of the request that caused the event.
+	UserAgent string `gorm:"type:text"`
+	IP        string `gorm:"type:text"`
+	CreatedAt time.Time
+}
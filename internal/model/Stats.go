@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+
+	"statistic_service/pkg/money"
+)
+
+// CategoryTotal is the sum of a user's transactions attributed to one category.
+// CategoryID is nil for uncategorized transactions.
+type CategoryTotal struct {
+	CategoryID *string      `json:"category_id"`
+	ParentID   *string      `json:"parent_id"`
+	Name       string       `json:"name"`
+	Type       string       `json:"type"`
+	Total      money.Amount `json:"total" swaggertype:"string" example:"12.50"`
+}
+
+// PeriodTotal is the sum of transactions in the week or month starting at
+// PeriodStart, in UTC.
+type PeriodTotal struct {
+	PeriodStart time.Time    `json:"period_start"`
+	Total       money.Amount `json:"total" swaggertype:"string" example:"12.50"`
+}
+
+// TagTotal is the sum of a user's transactions with one tag. A transaction
+// with several tags counts towards each of them. TagID is nil for untagged
+// transactions.
+type TagTotal struct {
+	TagID *string      `json:"tag_id"`
+	Name  string       `json:"name"`
+	Type  string       `json:"type"`
+	Total money.Amount `json:"total" swaggertype:"string" example:"12.50"`
+}
@@ -0,0 +1,24 @@
+package model
+
+import "time"
+
+// MaxTagLength bounds the length of a tag name.
+const MaxTagLength = 50
+
+// Tag is a user's label for transactions. Unlike categories a transaction
+// can have any number of tags. Names are stored in lower case.
+type Tag struct {
+	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	UserID    string    `gorm:"type:uuid;not null;uniqueIndex:idx_tags_user_name" json:"user_id"`
+	Name      string    `gorm:"type:text;not null;uniqueIndex:idx_tags_user_name" json:"name"`
+	CreatedAt time.Time `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// tagNames returns the names of tags.
+func tagNames(tags []Tag) []string {
+	names := make([]string, len(tags))
+	for i, t := range tags {
+		names[i] = t.Name
+	}
+	return names
+}
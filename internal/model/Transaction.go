@@ -2,16 +2,54 @@ package model
 
 import (
 	"time"
+
+	"statistic_service/pkg/money"
+
+	"gorm.io/gorm"
 )
 
 type Transaction struct {
-	ID        string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	UserID    string    `gorm:"type:uuid;not null;index"`
-	Amount    float64   `gorm:"not null"`
-	Type      string    `gorm:"type:text;not null"`
-	Category  string    `gorm:"type:text"`
-	Comment   string    `gorm:"type:text"`
-	CreatedAt time.Time `gorm:"autoCreateTime"`
+	ID         string       `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	UserID     string       `gorm:"type:uuid;not null;index" json:"user_id"`
+	Amount     money.Amount `gorm:"type:numeric(14,2);not null" json:"amount" swaggertype:"string" example:"12.50"`
+	Currency   string       `gorm:"type:char(3);not null;default:'USD'" json:"currency"`
+	Type       string       `gorm:"type:text;not null" json:"type"`
+	CategoryID *string      `gorm:"type:uuid;index" json:"category_id"`
+	Category   *Category    `gorm:"constraint:OnDelete:SET NULL" json:"-"`
+	AccountID  *string      `gorm:"type:uuid;index" json:"account_id"`
+	Account    *Account     `json:"-"`
+	// TransferID links the two legs of a transfer between accounts.
+	// Transfer legs are excluded from income and expense statistics.
+	TransferID *string `gorm:"type:uuid;index" json:"transfer_id,omitempty"`
+	// RecurringID is the template a scheduled transaction was created from.
+	// Each occurrence, identified by its time, has at most one transaction.
+	RecurringID *string               `gorm:"type:uuid;uniqueIndex:idx_transactions_recurring_occurrence,priority:1,where:recurring_id IS NOT NULL" json:"recurring_id,omitempty"`
+	Recurring   *RecurringTransaction `gorm:"constraint:OnDelete:SET NULL" json:"-"`
+	// ExternalID is the bank's reference of an imported statement entry.
+	ExternalID *string `gorm:"type:text" json:"external_id,omitempty"`
+	// DuplicateOf is the earlier transaction this one likely duplicates.
+	// It is set when the transaction is flagged on create or import.
+	DuplicateOf *string      `gorm:"type:uuid;index" json:"duplicate_of,omitempty"`
+	Original    *Transaction `gorm:"foreignKey:DuplicateOf;constraint:OnDelete:SET NULL" json:"-"`
+	Comment     string       `gorm:"type:text" json:"comment"`
+	CreatedAt   time.Time    `gorm:"autoCreateTime;uniqueIndex:idx_transactions_recurring_occurrence,priority:2,where:recurring_id IS NOT NULL" json:"created_at"`
+	// DeletedAt is set while the transaction is in the trash. Deleted
+	// transactions are left out of queries unless they are unscoped.
+	DeletedAt gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty" swaggertype:"string" example:"2024-01-02T15:04:05Z"`
+	Tags      []Tag          `gorm:"many2many:transaction_tags;constraint:OnDelete:CASCADE" json:"-"`
+	// TagNames are the names of Tags as sent and returned by the API.
+	TagNames []string `gorm:"-" json:"tags"`
+	// Splits divide the amount between several categories. A split
+	// transaction has no category of its own.
+	Splits []TransactionSplit `gorm:"constraint:OnDelete:CASCADE" json:"splits,omitempty"`
+
+	// BaseAmount is Amount converted to the owner's base currency at the rate
+	// effective on the transaction date. It is computed on read.
+	BaseAmount money.Amount `gorm:"->;-:migration" json:"base_amount" swaggertype:"string" example:"12.50"`
 }
 
-//ewdwdqwdqdqw
+// AfterFind fills TagNames from the preloaded Tags.
+func (t *Transaction) AfterFind(*gorm.DB) error {
+	t.TagNames = tagNames(t.Tags)
+	return nil
+}
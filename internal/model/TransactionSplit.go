@@ -0,0 +1,20 @@
+package model
+
+import "statistic_service/pkg/money"
+
+// MaxSplits bounds the number of split lines of one transaction.
+const MaxSplits = 50
+
+// TransactionSplit is one line of a transaction split across categories,
+// such as the household part of a supermarket receipt. The amounts of all
+// lines add up to the transaction amount, in the transaction currency.
+type TransactionSplit struct {
+	ID            string       `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	TransactionID string       `gorm:"type:uuid;not null;index" json:"-"`
+	CategoryID    *string      `gorm:"type:uuid;index" json:"category_id"`
+	Category      *Category    `gorm:"constraint:OnDelete:SET NULL" json:"-"`
+	Amount        money.Amount `gorm:"type:numeric(14,2);not null" json:"amount" swaggertype:"string" example:"4.20"`
+	Note          string       `gorm:"type:text" json:"note"`
+	// Position keeps the lines in the order they were sent.
+	Position int `gorm:"not null;default:0" json:"-"`
+}
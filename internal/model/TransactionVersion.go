@@ -0,0 +1,57 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// Actions recorded in the history of a transaction.
+const (
+	HistoryCreate  = "create"
+	HistoryUpdate  = "update"
+	HistoryDelete  = "delete"
+	HistoryRestore = "restore"
+	// HistoryMerge is recorded for a duplicate merged into its original.
+	HistoryMerge = "merge"
+)
+
+// TransactionVersion is one change in the history of a transaction: who made
+// it, when, in which request and how the fields changed. Versions are never
+// updated and outlive the transaction, so they have no foreign key to it.
+type TransactionVersion struct {
+	ID            string `gorm:"primaryKey;type:uuid;default:gen_random_uuid()" json:"id"`
+	TransactionID string `gorm:"type:uuid;not null;index" json:"transaction_id"`
+	// UserID owns the transaction; ActorID made the change.
+	UserID    string       `gorm:"type:uuid;not null;index" json:"-"`
+	ActorID   string       `gorm:"type:uuid;not null" json:"actor_id"`
+	Action    string       `gorm:"type:text;not null" json:"action"`
+	Changes   FieldChanges `gorm:"type:jsonb;not null" json:"changes" swaggertype:"object"`
+	RequestID string       `gorm:"type:text" json:"request_id,omitempty"`
+	CreatedAt time.Time    `gorm:"autoCreateTime" json:"created_at"`
+}
+
+// FieldChange is the JSON value of a field before and after a change.
+type FieldChange struct {
+	Before json.RawMessage `json:"before" swaggertype:"object"`
+	After  json.RawMessage `json:"after" swaggertype:"object"`
+}
+
+// FieldChanges maps the JSON names of changed fields to their change. It is
+// stored as a JSON document.
+type FieldChanges map[string]FieldChange
+
+func (c FieldChanges) Value() (driver.Value, error) {
+	return json.Marshal(c)
+}
+
+func (c *FieldChanges) Scan(src interface{}) error {
+	switch x := src.(type) {
+	case []byte:
+		return json.Unmarshal(x, c)
+	case string:
+		return json.Unmarshal([]byte(x), c)
+	}
+	return errors.New("unsupported field changes type")
+}
@@ -2,9 +2,17 @@ package model
 
 import "time"
 
+// DefaultCurrency is the base currency of new users and the currency of
+// transactions recorded before multi-currency support.
+const DefaultCurrency = "USD"
+
 type User struct {
-	ID           string    `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
-	Email        string    `gorm:"unique;not null"`
-	PasswordHash string    `gorm:"not null"`
-	CreatedAt    time.Time `gorm:"autoCreateTime"`
+	ID           string `gorm:"primaryKey;type:uuid;default:gen_random_uuid()"`
+	Email        string `gorm:"unique;not null"`
+	PasswordHash string `gorm:"not null"`
+	BaseCurrency string `gorm:"type:char(3);not null;default:'USD'"`
+	// EmailVerified is set once the user opened the link emailed on
+	// registration.
+	EmailVerified bool      `gorm:"not null;default:false"`
+	CreatedAt     time.Time `gorm:"autoCreateTime"`
 }
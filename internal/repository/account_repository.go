@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"time"
+
+	"statistic_service/internal/model"
+	"statistic_service/pkg/money"
+
+	"gorm.io/gorm"
+)
+
+type AccountRepository interface {
+	Create(account *model.Account) error
+	GetByUser(userID string) ([]model.Account, error)
+	GetByID(id string) (*model.Account, error)
+	Update(account *model.Account) error
+	Delete(id string) error
+	HasTransactions(id string) (bool, error)
+	// Balance returns the sum of the account's income minus expenses
+	// recorded up to and including at, without the opening balance.
+	Balance(id string, at time.Time) (money.Amount, error)
+}
+
+type accountRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountRepository(db *gorm.DB) AccountRepository {
+	return &accountRepository{db: db}
+}
+
+func (r *accountRepository) Create(account *model.Account) error {
+	return r.db.Create(account).Error
+}
+
+func (r *accountRepository) GetByUser(userID string) ([]model.Account, error) {
+	var accounts []model.Account
+	if err := r.db.Where("user_id = ?", userID).Order("name").Find(&accounts).Error; err != nil {
+		return nil, err
+	}
+	return accounts, nil
+}
+
+func (r *accountRepository) GetByID(id string) (*model.Account, error) {
+	var account model.Account
+	if err := r.db.First(&account, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *accountRepository) Update(account *model.Account) error {
+	return r.db.Save(account).Error
+}
+
+func (r *accountRepository) Delete(id string) error {
+	return r.db.Delete(&model.Account{}, "id = ?", id).Error
+}
+
+// HasTransactions also counts deleted transactions, which still reference
+// the account until they are purged.
+func (r *accountRepository) HasTransactions(id string) (bool, error) {
+	var txs []model.Transaction
+	err := r.db.Unscoped().Select("id").Where("account_id = ?", id).Limit(1).Find(&txs).Error
+	return len(txs) > 0, err
+}
+
+func (r *accountRepository) Balance(id string, at time.Time) (money.Amount, error) {
+	var balance money.Amount
+	err := r.db.Model(&model.Transaction{}).
+		Select("COALESCE(SUM(CASE WHEN type = 'income' THEN amount ELSE -amount END), 0)").
+		Where("account_id = ? AND created_at <= ?", id, at).
+		Scan(&balance).Error
+	return balance, err
+}
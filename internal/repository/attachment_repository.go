@@ -0,0 +1,52 @@
+package repository
+
+import (
+	"statistic_service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type AttachmentRepository interface {
+	Create(a *model.Attachment) error
+	GetByID(id string) (*model.Attachment, error)
+	GetByTransaction(transactionID string) ([]model.Attachment, error)
+	CountByTransaction(transactionID string) (int64, error)
+	Delete(id string) error
+}
+
+type attachmentRepository struct {
+	db *gorm.DB
+}
+
+func NewAttachmentRepository(db *gorm.DB) AttachmentRepository {
+	return &attachmentRepository{db: db}
+}
+
+func (r *attachmentRepository) Create(a *model.Attachment) error {
+	return r.db.Create(a).Error
+}
+
+func (r *attachmentRepository) GetByID(id string) (*model.Attachment, error) {
+	var a model.Attachment
+	if err := r.db.First(&a, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &a, nil
+}
+
+// GetByTransaction returns the attachments of a transaction, oldest first.
+func (r *attachmentRepository) GetByTransaction(transactionID string) ([]model.Attachment, error) {
+	var list []model.Attachment
+	err := r.db.Where("transaction_id = ?", transactionID).Order("created_at, id").Find(&list).Error
+	return list, err
+}
+
+func (r *attachmentRepository) CountByTransaction(transactionID string) (int64, error) {
+	var n int64
+	err := r.db.Model(&model.Attachment{}).Where("transaction_id = ?", transactionID).Count(&n).Error
+	return n, err
+}
+
+func (r *attachmentRepository) Delete(id string) error {
+	return r.db.Delete(&model.Attachment{}, "id = ?", id).Error
+}
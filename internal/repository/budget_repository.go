@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"statistic_service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type BudgetRepository interface {
+	Create(budget *model.Budget) error
+	GetByUser(userID string) ([]model.Budget, error)
+	GetByID(id string) (*model.Budget, error)
+	GetByCategory(categoryID, period string) (*model.Budget, error)
+	Update(budget *model.Budget) error
+	Delete(id string) error
+}
+
+type budgetRepository struct {
+	db *gorm.DB
+}
+
+func NewBudgetRepository(db *gorm.DB) BudgetRepository {
+	return &budgetRepository{db: db}
+}
+
+func (r *budgetRepository) Create(budget *model.Budget) error {
+	return r.db.Create(budget).Error
+}
+
+func (r *budgetRepository) GetByUser(userID string) ([]model.Budget, error) {
+	var budgets []model.Budget
+	if err := r.db.Where("user_id = ?", userID).Order("created_at, id").Find(&budgets).Error; err != nil {
+		return nil, err
+	}
+	return budgets, nil
+}
+
+func (r *budgetRepository) GetByID(id string) (*model.Budget, error) {
+	var budget model.Budget
+	if err := r.db.First(&budget, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+func (r *budgetRepository) GetByCategory(categoryID, period string) (*model.Budget, error) {
+	var budget model.Budget
+	if err := r.db.First(&budget, "category_id = ? AND period = ?", categoryID, period).Error; err != nil {
+		return nil, err
+	}
+	return &budget, nil
+}
+
+func (r *budgetRepository) Update(budget *model.Budget) error {
+	return r.db.Save(budget).Error
+}
+
+func (r *budgetRepository) Delete(id string) error {
+	return r.db.Delete(&model.Budget{}, "id = ?", id).Error
+}
@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"statistic_service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type CategoryRepository interface {
+	Create(category *model.Category) error
+	GetByUser(userID, catType string) ([]model.Category, error)
+	GetByID(id string) (*model.Category, error)
+	GetByName(userID string, parentID *string, name string) (*model.Category, error)
+	AncestorIDs(id string) ([]string, error)
+	SubtreeHeight(id string) (int, error)
+	HasChildren(id string) (bool, error)
+	Update(category *model.Category) error
+	Delete(id string) error
+}
+
+type categoryRepository struct {
+	db *gorm.DB
+}
+
+func NewCategoryRepository(db *gorm.DB) CategoryRepository {
+	return &categoryRepository{db: db}
+}
+
+// Create and Update report a name already taken among the siblings as
+// gorm.ErrDuplicatedKey.
+func (r *categoryRepository) Create(category *model.Category) error {
+	return r.translate(r.db.Create(category).Error)
+}
+
+func (r *categoryRepository) GetByUser(userID, catType string) ([]model.Category, error) {
+	q := r.db.Where("user_id = ?", userID)
+	if catType != "" {
+		q = q.Where("type = ?", catType)
+	}
+	var categories []model.Category
+	if err := q.Order("name").Find(&categories).Error; err != nil {
+		return nil, err
+	}
+	return categories, nil
+}
+
+func (r *categoryRepository) GetByID(id string) (*model.Category, error) {
+	var category model.Category
+	if err := r.db.First(&category, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetByName looks up a category by name among the children of parentID
+// (or among root categories when parentID is nil), ignoring case.
+func (r *categoryRepository) GetByName(userID string, parentID *string, name string) (*model.Category, error) {
+	q := r.db.Where("user_id = ? AND lower(name) = lower(?)", userID, name)
+	if parentID == nil {
+		q = q.Where("parent_id IS NULL")
+	} else {
+		q = q.Where("parent_id = ?", *parentID)
+	}
+	var category model.Category
+	if err := q.First(&category).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// AncestorIDs returns the IDs on the path from the category up to its root,
+// starting with the category itself.
+func (r *categoryRepository) AncestorIDs(id string) ([]string, error) {
+	var ids []string
+	err := r.db.Raw(`
+		WITH RECURSIVE up AS (
+			SELECT id, parent_id, 1 AS level FROM categories WHERE id = ?
+			UNION ALL
+			SELECT c.id, c.parent_id, up.level + 1
+			FROM categories c JOIN up ON c.id = up.parent_id
+			WHERE up.level <= ?
+		)
+		SELECT id FROM up ORDER BY level`, id, model.MaxCategoryDepth).Scan(&ids).Error
+	return ids, err
+}
+
+// SubtreeHeight returns the number of levels in the subtree rooted at the
+// category, counting the category itself.
+func (r *categoryRepository) SubtreeHeight(id string) (int, error) {
+	var height int
+	err := r.db.Raw(`
+		WITH RECURSIVE down AS (
+			SELECT id, 1 AS level FROM categories WHERE id = ?
+			UNION ALL
+			SELECT c.id, down.level + 1
+			FROM categories c JOIN down ON c.parent_id = down.id
+			WHERE down.level <= ?
+		)
+		SELECT COALESCE(MAX(level), 0) FROM down`, id, model.MaxCategoryDepth).Scan(&height).Error
+	return height, err
+}
+
+func (r *categoryRepository) HasChildren(id string) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.Category{}).Where("parent_id = ?", id).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *categoryRepository) Update(category *model.Category) error {
+	return r.translate(r.db.Save(category).Error)
+}
+
+func (r *categoryRepository) translate(err error) error {
+	if t, ok := r.db.Dialector.(gorm.ErrorTranslator); ok && err != nil {
+		return t.Translate(err)
+	}
+	return err
+}
+
+func (r *categoryRepository) Delete(id string) error {
+	return r.db.Delete(&model.Category{}, "id = ?", id).Error
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"time"
+
+	"statistic_service/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type IdempotencyRepository interface {
+	Reserve(k *model.IdempotencyKey) (bool, error)
+	Get(userID, key string) (*model.IdempotencyKey, error)
+	Complete(k *model.IdempotencyKey) error
+	Delete(userID, key string) error
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+type idempotencyRepository struct {
+	db *gorm.DB
+}
+
+func NewIdempotencyRepository(db *gorm.DB) IdempotencyRepository {
+	return &idempotencyRepository{db: db}
+}
+
+// Reserve stores a pending key. It returns false without changing anything
+// when the user already has the key.
+func (r *idempotencyRepository) Reserve(k *model.IdempotencyKey) (bool, error) {
+	res := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(k)
+	return res.RowsAffected == 1, res.Error
+}
+
+func (r *idempotencyRepository) Get(userID, key string) (*model.IdempotencyKey, error) {
+	var k model.IdempotencyKey
+	if err := r.db.First(&k, "user_id = ? AND key = ?", userID, key).Error; err != nil {
+		return nil, err
+	}
+	return &k, nil
+}
+
+// Complete stores the response of a pending key.
+func (r *idempotencyRepository) Complete(k *model.IdempotencyKey) error {
+	return r.db.Model(&model.IdempotencyKey{}).
+		Where("user_id = ? AND key = ?", k.UserID, k.Key).
+		Updates(map[string]interface{}{"status_code": k.StatusCode, "content_type": k.ContentType, "body": k.Body}).Error
+}
+
+func (r *idempotencyRepository) Delete(userID, key string) error {
+	return r.db.Delete(&model.IdempotencyKey{}, "user_id = ? AND key = ?", userID, key).Error
+}
+
+// DeleteExpired removes the keys that expired before now and returns how many
+// it removed.
+func (r *idempotencyRepository) DeleteExpired(now time.Time) (int64, error) {
+	res := r.db.Delete(&model.IdempotencyKey{}, "expires_at < ?", now)
+	return res.RowsAffected, res.Error
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"time"
+
+	"statistic_service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type PasswordResetRepository interface {
+	Create(token *model.PasswordResetToken) error
+	GetByHash(tokenHash string) (*model.PasswordResetToken, error)
+	GetLatest(userID string) (*model.PasswordResetToken, error)
+	Use(token *model.PasswordResetToken, passwordHash string, now, deniedUntil time.Time) (bool, error)
+}
+
+type passwordResetRepository struct {
+	db *gorm.DB
+}
+
+func NewPasswordResetRepository(db *gorm.DB) PasswordResetRepository {
+	return &passwordResetRepository{db: db}
+}
+
+// Create stores a token in place of the user's earlier ones, so only the
+// latest email can be used.
+func (r *passwordResetRepository) Create(token *model.PasswordResetToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.PasswordResetToken{}, "user_id = ?", token.UserID).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *passwordResetRepository) GetByHash(tokenHash string) (*model.PasswordResetToken, error) {
+	var token model.PasswordResetToken
+	if err := r.db.First(&token, "token_hash = ?", tokenHash).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *passwordResetRepository) GetLatest(userID string) (*model.PasswordResetToken, error) {
+	var token model.PasswordResetToken
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Use marks the token used, sets the user's password hash and ends all
+// sessions of the user, denying their access tokens until deniedUntil, in one
+// transaction. It returns false when the token was already used or expired,
+// e.g. by a concurrent reset.
+func (r *passwordResetRepository) Use(token *model.PasswordResetToken, passwordHash string, now, deniedUntil time.Time) (bool, error) {
+	used := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&model.PasswordResetToken{}).
+			Where("id = ? AND used_at IS NULL AND expires_at > ?", token.ID, now).
+			Update("used_at", now)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		if err := tx.Model(&model.User{}).Where("id = ?", token.UserID).Update("password_hash", passwordHash).Error; err != nil {
+			return err
+		}
+		users := &userRepository{db: tx}
+		revoked, err := users.DeleteSessions(token.UserID, nil)
+		if err != nil {
+			return err
+		}
+		if err := users.DenySessions(revoked, deniedUntil); err != nil {
+			return err
+		}
+		used = true
+		return nil
+	})
+	return used, err
+}
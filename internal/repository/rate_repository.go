@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"statistic_service/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type RateRepository interface {
+	Upsert(rates []model.ExchangeRate) error
+	HasRates(currency string) (bool, error)
+}
+
+type rateRepository struct {
+	db *gorm.DB
+}
+
+func NewRateRepository(db *gorm.DB) RateRepository {
+	return &rateRepository{db: db}
+}
+
+// Upsert stores the rates, replacing already known rates for the same day.
+func (r *rateRepository) Upsert(rates []model.ExchangeRate) error {
+	if len(rates) == 0 {
+		return nil
+	}
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "currency"}, {Name: "date"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rate"}),
+	}).CreateInBatches(rates, 500).Error
+}
+
+func (r *rateRepository) HasRates(currency string) (bool, error) {
+	var rates []model.ExchangeRate
+	err := r.db.Where("currency = ?", currency).Limit(1).Find(&rates).Error
+	return len(rates) > 0, err
+}
+
+// rateAtSQL returns an SQL expression for the rate of the currency expression
+// effective on the date of transaction t: the latest rate on or before that
+// date, or the earliest later one when none was published before.
+func rateAtSQL(currency string) string {
+	return `CASE WHEN ` + currency + ` = '` + model.RateReferenceCurrency + `' THEN 1 ELSE COALESCE(
+		(SELECT r.rate FROM exchange_rates r WHERE r.currency = ` + currency + ` AND r.date <= t.created_at::date ORDER BY r.date DESC LIMIT 1),
+		(SELECT r.rate FROM exchange_rates r WHERE r.currency = ` + currency + ` AND r.date > t.created_at::date ORDER BY r.date LIMIT 1)) END`
+}
+
+// baseAmountSQL converts t.amount into the base currency of its owner u,
+// rounding every converted amount half away from zero to cents before it is
+// summed. Queries using it must join users u ON u.id = t.user_id.
+var baseAmountSQL = `(CASE WHEN t.currency = u.base_currency THEN t.amount
+	ELSE ROUND(t.amount * ` + rateAtSQL("u.base_currency") + ` / ` + rateAtSQL("t.currency") + `, 2) END)`
@@ -0,0 +1,74 @@
+package repository
+
+import (
+	"time"
+
+	"statistic_service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type RecurringRepository interface {
+	Create(r *model.RecurringTransaction) error
+	GetByUser(userID string) ([]model.RecurringTransaction, error)
+	GetByID(id string) (*model.RecurringTransaction, error)
+	Update(r *model.RecurringTransaction) error
+	// Advance stores the occurrences and next occurrence of r, unless its next
+	// occurrence is no longer prevNextAt. It reports whether it did.
+	Advance(r *model.RecurringTransaction, prevNextAt time.Time) (bool, error)
+	Delete(id string) error
+	// Due returns up to limit templates whose next occurrence is at or before at.
+	Due(at time.Time, limit int) ([]model.RecurringTransaction, error)
+}
+
+type recurringRepository struct {
+	db *gorm.DB
+}
+
+func NewRecurringRepository(db *gorm.DB) RecurringRepository {
+	return &recurringRepository{db: db}
+}
+
+func (r *recurringRepository) Create(rec *model.RecurringTransaction) error {
+	return r.db.Create(rec).Error
+}
+
+func (r *recurringRepository) GetByUser(userID string) ([]model.RecurringTransaction, error) {
+	var list []model.RecurringTransaction
+	if err := r.db.Where("user_id = ?", userID).Order("created_at, id").Find(&list).Error; err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (r *recurringRepository) GetByID(id string) (*model.RecurringTransaction, error) {
+	var rec model.RecurringTransaction
+	if err := r.db.First(&rec, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &rec, nil
+}
+
+func (r *recurringRepository) Update(rec *model.RecurringTransaction) error {
+	return r.db.Save(rec).Error
+}
+
+func (r *recurringRepository) Advance(rec *model.RecurringTransaction, prevNextAt time.Time) (bool, error) {
+	res := r.db.Model(&model.RecurringTransaction{}).Where("id = ? AND next_at = ?", rec.ID, prevNextAt).
+		Updates(map[string]interface{}{"occurrences": rec.Occurrences, "next_at": rec.NextAt})
+	return res.RowsAffected > 0, res.Error
+}
+
+func (r *recurringRepository) Delete(id string) error {
+	return r.db.Delete(&model.RecurringTransaction{}, "id = ?", id).Error
+}
+
+func (r *recurringRepository) Due(at time.Time, limit int) ([]model.RecurringTransaction, error) {
+	var list []model.RecurringTransaction
+	err := r.db.Where("next_at IS NOT NULL AND next_at <= ?", at).
+		Order("next_at, id").Limit(limit).Find(&list).Error
+	if err != nil {
+		return nil, err
+	}
+	return list, nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"statistic_service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type RuleRepository interface {
+	Create(rule *model.Rule) error
+	GetByUser(userID string) ([]model.Rule, error)
+	GetByID(id string) (*model.Rule, error)
+	Update(rule *model.Rule) error
+	Delete(id string) error
+	Reorder(ids []string) error
+}
+
+type ruleRepository struct {
+	db *gorm.DB
+}
+
+func NewRuleRepository(db *gorm.DB) RuleRepository {
+	return &ruleRepository{db: db}
+}
+
+func (r *ruleRepository) Create(rule *model.Rule) error {
+	return r.db.Omit("Category", "Account").Create(rule).Error
+}
+
+// GetByUser returns the user's rules in the order they are applied, with
+// their categories.
+func (r *ruleRepository) GetByUser(userID string) ([]model.Rule, error) {
+	var rules []model.Rule
+	if err := r.db.Preload("Category").Preload("Tags").Where("user_id = ?", userID).Order("position, created_at, id").Find(&rules).Error; err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+func (r *ruleRepository) GetByID(id string) (*model.Rule, error) {
+	var rule model.Rule
+	if err := r.db.Preload("Tags").First(&rule, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+// Update saves a rule and replaces its tags in one DB transaction.
+func (r *ruleRepository) Update(rule *model.Rule) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Category", "Account", "Tags").Save(rule).Error; err != nil {
+			return err
+		}
+		if len(rule.Tags) == 0 {
+			return tx.Model(rule).Association("Tags").Clear()
+		}
+		return tx.Model(rule).Association("Tags").Replace(rule.Tags)
+	})
+}
+
+func (r *ruleRepository) Delete(id string) error {
+	return r.db.Delete(&model.Rule{}, "id = ?", id).Error
+}
+
+// Reorder sets the position of every rule to its index in ids in one DB transaction.
+func (r *ruleRepository) Reorder(ids []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			if err := tx.Model(&model.Rule{}).Where("id = ?", id).Update("position", i).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"statistic_service/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type TagRepository interface {
+	Create(tag *model.Tag) error
+	GetByUser(userID string) ([]model.Tag, error)
+	GetByID(id string) (*model.Tag, error)
+	GetByName(userID, name string) (*model.Tag, error)
+	Ensure(userID string, names []string) ([]model.Tag, error)
+	Update(tag *model.Tag) error
+	Delete(id string) error
+}
+
+type tagRepository struct {
+	db *gorm.DB
+}
+
+func NewTagRepository(db *gorm.DB) TagRepository {
+	return &tagRepository{db: db}
+}
+
+// Create and Update report a name the user already has as
+// gorm.ErrDuplicatedKey.
+func (r *tagRepository) Create(tag *model.Tag) error {
+	return r.translate(r.db.Create(tag).Error)
+}
+
+func (r *tagRepository) GetByUser(userID string) ([]model.Tag, error) {
+	var tags []model.Tag
+	if err := r.db.Where("user_id = ?", userID).Order("name").Find(&tags).Error; err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+func (r *tagRepository) GetByID(id string) (*model.Tag, error) {
+	var tag model.Tag
+	if err := r.db.First(&tag, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+func (r *tagRepository) GetByName(userID, name string) (*model.Tag, error) {
+	var tag model.Tag
+	if err := r.db.First(&tag, "user_id = ? AND name = ?", userID, name).Error; err != nil {
+		return nil, err
+	}
+	return &tag, nil
+}
+
+// Ensure returns the user's tags with the given names, creating the missing
+// ones. Tags created concurrently under the same name are reused.
+func (r *tagRepository) Ensure(userID string, names []string) ([]model.Tag, error) {
+	tags := []model.Tag{}
+	if len(names) == 0 {
+		return tags, nil
+	}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND name IN ?", userID, names).Find(&tags).Error; err != nil {
+			return err
+		}
+		if len(tags) == len(names) {
+			return nil
+		}
+		found := make(map[string]bool, len(tags))
+		for _, t := range tags {
+			found[t.Name] = true
+		}
+		var missing []model.Tag
+		for _, name := range names {
+			if !found[name] {
+				missing = append(missing, model.Tag{UserID: userID, Name: name})
+			}
+		}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&missing).Error; err != nil {
+			return err
+		}
+		tags = nil
+		return tx.Where("user_id = ? AND name IN ?", userID, names).Order("name").Find(&tags).Error
+	})
+	return tags, err
+}
+
+func (r *tagRepository) Update(tag *model.Tag) error {
+	return r.translate(r.db.Save(tag).Error)
+}
+
+func (r *tagRepository) translate(err error) error {
+	if t, ok := r.db.Dialector.(gorm.ErrorTranslator); ok && err != nil {
+		return t.Translate(err)
+	}
+	return err
+}
+
+func (r *tagRepository) Delete(id string) error {
+	return r.db.Delete(&model.Tag{}, "id = ?", id).Error
+}
@@ -1,21 +1,88 @@
 package repository
 
 import (
+	"fmt"
+	"strings"
 	"time"
 
 	"statistic_service/internal/model"
+	"statistic_service/pkg/money"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+const (
+	// maxDuplicateCandidates bounds the candidates returned for one transaction.
+	maxDuplicateCandidates = 10
+	// purgeBatchSize bounds the transactions purged in one DB transaction.
+	purgeBatchSize = 500
+)
+
+// TransactionFilter narrows the transactions returned by GetPage.
+type TransactionFilter struct {
+	From       *time.Time
+	To         *time.Time
+	Type       string
+	CategoryID string
+	AccountID  string
+	MinAmount  *money.Amount
+	MaxAmount  *money.Amount
+	Search     string
+	// Tags are tag names a transaction must all have.
+	Tags []string
+}
+
+// PageQuery selects one keyset page ordered by (created_at, id).
+// AfterTime and AfterID hold the last row of the previous page.
+type PageQuery struct {
+	AfterTime *time.Time
+	AfterID   string
+	Limit     int
+	Desc      bool
+}
+
+// TransactionUpdate is a partial update of a stored transaction: the listed
+// columns are set from Transaction and Tags are added to it.
+type TransactionUpdate struct {
+	Transaction *model.Transaction
+	Columns     []string
+	Tags        []model.Tag
+}
+
 type TransactionRepository interface {
 	Create(tx *model.Transaction) error
-	GetByUser(userID string, from, to *time.Time, txType string) ([]model.Transaction, error)
+	CreateTransfer(legs ...*model.Transaction) error
+	CreateBatch(txs []*model.Transaction) error
+	GetByUser(userID string, from, to *time.Time, txType, categoryID string) ([]model.Transaction, error)
+	GetPage(userID string, f TransactionFilter, p PageQuery) ([]model.Transaction, error)
 	GetByID(id string) (*model.Transaction, error)
 	Update(tx *model.Transaction) error
+	UpdateBatch(updates []TransactionUpdate) error
 	Delete(id string) error
-	Summary(userID string, from, to *time.Time) (income, expense float64, err error)
-	ByCategory(userID string, from, to *time.Time) (map[string]float64, error)
+	DeleteTransfer(transferID string) error
+	GetTransfer(transferID string) ([]model.Transaction, error)
+	GetTrash(userID string) ([]model.Transaction, error)
+	GetDeleted(id string) (*model.Transaction, error)
+	Restore(id string) error
+	RestoreTransfer(transferID string) error
+	Purge(before time.Time) (int, []model.Attachment, error)
+	HasOccurrence(recurringID string, at time.Time) (bool, error)
+	ExistingExternalIDs(userID string, accountID *string, ids []string) ([]string, error)
+	DuplicateCandidates(tx *model.Transaction, window time.Duration) ([]model.Transaction, error)
+	GetFlagged(userID string) ([]model.Transaction, error)
+	GetByIDs(ids []string) ([]model.Transaction, error)
+	Merge(keep *model.Transaction, duplicateID string) error
+	AddVersions(versions []model.TransactionVersion) error
+	// WithTx runs fn with a repository whose changes are committed together
+	// when fn returns nil and rolled back otherwise.
+	WithTx(fn func(r TransactionRepository) error) error
+	GetHistory(transactionID, userID string) ([]model.TransactionVersion, error)
+	Summary(userID string, from, to *time.Time) (income, expense money.Amount, err error)
+	ByCategory(userID string, from, to *time.Time, depth int) ([]model.CategoryTotal, error)
+	SpentByPeriod(userID, categoryID, period string, from, to time.Time) ([]model.PeriodTotal, error)
+	ByTag(userID string, from, to *time.Time) ([]model.TagTotal, error)
+	Currencies(userID string) ([]string, error)
 }
 
 type transactionRepository struct {
@@ -26,20 +93,78 @@ func NewTransactionRepository(db *gorm.DB) TransactionRepository {
 	return &transactionRepository{db: db}
 }
 
+func (r *transactionRepository) WithTx(fn func(r TransactionRepository) error) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return fn(&transactionRepository{db: tx})
+	})
+}
+
+// splitLinesSQL lists transactions t with one row per split line, or one row
+// for a transaction that is not split, carrying the line's category and amount.
+// Deleted transactions are left out.
+const splitLinesSQL = `(SELECT t.id, t.user_id, t.type, t.currency, t.created_at, t.transfer_id, t.account_id, t.comment,
+		CASE WHEN s.id IS NULL THEN t.category_id ELSE s.category_id END AS category_id,
+		COALESCE(s.amount, t.amount) AS amount
+	FROM transactions t LEFT JOIN transaction_splits s ON s.transaction_id = t.id
+	WHERE t.deleted_at IS NULL) t`
+
+// splitsInOrder preloads split lines in the order they were sent.
+func splitsInOrder(db *gorm.DB) *gorm.DB {
+	return db.Order("position")
+}
+
+// withBaseAmount selects transactions t together with their amount in the
+// owner's base currency.
+func (r *transactionRepository) withBaseAmount() *gorm.DB {
+	return r.db.Table("transactions t").
+		Select("t.*, " + baseAmountSQL + " AS base_amount").
+		Joins("JOIN users u ON u.id = t.user_id")
+}
+
 func (r *transactionRepository) Create(tx *model.Transaction) error {
 	return r.db.Create(tx).Error
 }
 
-func (r *transactionRepository) GetByUser(userID string, from, to *time.Time, txType string) ([]model.Transaction, error) {
-	q := r.db.Where("user_id = ?", userID)
+// CreateTransfer stores all legs of a transfer in one DB transaction.
+func (r *transactionRepository) CreateTransfer(legs ...*model.Transaction) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, leg := range legs {
+			if err := tx.Create(leg).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CreateBatch stores all transactions in one DB transaction, so either all or
+// none of them are saved.
+func (r *transactionRepository) CreateBatch(txs []*model.Transaction) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		return tx.CreateInBatches(txs, 500).Error
+	})
+}
+
+// GetByUser returns the user's income and expense transactions for statistics.
+// Transfer legs are excluded. With categoryID only the transactions of that
+// category are returned; for a split transaction Amount and BaseAmount are
+// then the category's share.
+func (r *transactionRepository) GetByUser(userID string, from, to *time.Time, txType, categoryID string) ([]model.Transaction, error) {
+	q := r.withBaseAmount().Where("t.user_id = ? AND t.transfer_id IS NULL", userID)
+	if categoryID != "" {
+		q = r.db.Unscoped().Table(splitLinesSQL).
+			Select("t.*, "+baseAmountSQL+" AS base_amount").
+			Joins("JOIN users u ON u.id = t.user_id").
+			Where("t.user_id = ? AND t.transfer_id IS NULL AND t.category_id = ?", userID, categoryID)
+	}
 	if txType != "" {
-		q = q.Where("type = ?", txType)
+		q = q.Where("t.type = ?", txType)
 	}
 	if from != nil {
-		q = q.Where("created_at >= ?", *from)
+		q = q.Where("t.created_at >= ?", *from)
 	}
 	if to != nil {
-		q = q.Where("created_at <= ?", *to)
+		q = q.Where("t.created_at <= ?", *to)
 	}
 	var transactions []model.Transaction
 	if err := q.Find(&transactions).Error; err != nil {
@@ -48,42 +173,319 @@ func (r *transactionRepository) GetByUser(userID string, from, to *time.Time, tx
 	return transactions, nil
 }
 
+func (r *transactionRepository) GetPage(userID string, f TransactionFilter, p PageQuery) ([]model.Transaction, error) {
+	q := r.withBaseAmount().Where("t.user_id = ?", userID)
+	if f.Type != "" {
+		q = q.Where("t.type = ?", f.Type)
+	}
+	if f.CategoryID != "" {
+		q = q.Where("(t.category_id = ? OR EXISTS (SELECT 1 FROM transaction_splits s WHERE s.transaction_id = t.id AND s.category_id = ?))", f.CategoryID, f.CategoryID)
+	}
+	if f.AccountID != "" {
+		q = q.Where("t.account_id = ?", f.AccountID)
+	}
+	if f.From != nil {
+		q = q.Where("t.created_at >= ?", *f.From)
+	}
+	if f.To != nil {
+		q = q.Where("t.created_at <= ?", *f.To)
+	}
+	if f.MinAmount != nil {
+		q = q.Where("t.amount >= ?", *f.MinAmount)
+	}
+	if f.MaxAmount != nil {
+		q = q.Where("t.amount <= ?", *f.MaxAmount)
+	}
+	if f.Search != "" {
+		q = q.Where("t.comment ILIKE ? ESCAPE '\\'", "%"+escapeLike(f.Search)+"%")
+	}
+	for _, tag := range f.Tags {
+		q = q.Where("EXISTS (SELECT 1 FROM transaction_tags tt JOIN tags g ON g.id = tt.tag_id WHERE tt.transaction_id = t.id AND g.name = ?)", tag)
+	}
+
+	order := "t.created_at ASC, t.id ASC"
+	cmp := ">"
+	if p.Desc {
+		order = "t.created_at DESC, t.id DESC"
+		cmp = "<"
+	}
+	if p.AfterTime != nil {
+		q = q.Where("(t.created_at, t.id) "+cmp+" (?, ?)", *p.AfterTime, p.AfterID)
+	}
+
+	var transactions []model.Transaction
+	if err := q.Preload("Tags").Preload("Splits", splitsInOrder).Order(order).Limit(p.Limit).Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
 func (r *transactionRepository) GetByID(id string) (*model.Transaction, error) {
 	var tx model.Transaction
-	if err := r.db.First(&tx, "id = ?", id).Error; err != nil {
+	if err := r.db.Preload("Tags").Preload("Splits", splitsInOrder).First(&tx, "id = ?", id).Error; err != nil {
 		return nil, err
 	}
 	return &tx, nil
 }
 
+// Update saves a transaction and replaces its split lines. Unless Tags is
+// nil its tags are replaced too, in the same DB transaction.
 func (r *transactionRepository) Update(tx *model.Transaction) error {
-	return r.db.Save(tx).Error
+	return r.db.Transaction(func(db *gorm.DB) error {
+		if err := db.Omit("Tags", "Splits").Save(tx).Error; err != nil {
+			return err
+		}
+		if err := db.Delete(&model.TransactionSplit{}, "transaction_id = ?", tx.ID).Error; err != nil {
+			return err
+		}
+		for i := range tx.Splits {
+			tx.Splits[i].TransactionID = tx.ID
+		}
+		if len(tx.Splits) > 0 {
+			if err := db.Create(&tx.Splits).Error; err != nil {
+				return err
+			}
+		}
+		switch {
+		case tx.Tags == nil:
+			return nil
+		case len(tx.Tags) == 0:
+			return db.Model(tx).Association("Tags").Clear()
+		}
+		return db.Model(tx).Association("Tags").Replace(tx.Tags)
+	})
 }
 
+// UpdateBatch applies partial updates in one DB transaction, leaving the
+// other columns as they are, so concurrent edits of them are kept. Tags are
+// added, never removed.
+func (r *transactionRepository) UpdateBatch(updates []TransactionUpdate) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, u := range updates {
+			if len(u.Columns) > 0 {
+				if err := tx.Model(u.Transaction).Select(u.Columns).Updates(u.Transaction).Error; err != nil {
+					return err
+				}
+			}
+			if len(u.Tags) > 0 {
+				if err := tx.Model(u.Transaction).Omit("Tags.*").Association("Tags").Append(u.Tags); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// Delete moves a transaction to the trash by setting DeletedAt.
 func (r *transactionRepository) Delete(id string) error {
 	return r.db.Delete(&model.Transaction{}, "id = ?", id).Error
 }
 
-func (r *transactionRepository) Summary(userID string, from, to *time.Time) (float64, float64, error) {
-	var income, expense float64
+func (r *transactionRepository) DeleteTransfer(transferID string) error {
+	return r.db.Delete(&model.Transaction{}, "transfer_id = ?", transferID).Error
+}
+
+// GetTransfer returns the legs of a transfer, including deleted ones.
+func (r *transactionRepository) GetTransfer(transferID string) ([]model.Transaction, error) {
+	var legs []model.Transaction
+	err := r.db.Unscoped().Where("transfer_id = ?", transferID).Order("created_at, id").Find(&legs).Error
+	return legs, err
+}
+
+// GetTrash returns the user's deleted transactions, most recently deleted first.
+func (r *transactionRepository) GetTrash(userID string) ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	err := r.withBaseAmount().Unscoped().Preload("Tags").Preload("Splits", splitsInOrder).
+		Where("t.user_id = ? AND t.deleted_at IS NOT NULL", userID).
+		Order("t.deleted_at DESC, t.id").Find(&transactions).Error
+	return transactions, err
+}
+
+// GetDeleted returns a transaction in the trash.
+func (r *transactionRepository) GetDeleted(id string) (*model.Transaction, error) {
+	var tx model.Transaction
+	if err := r.db.Unscoped().Where("deleted_at IS NOT NULL").First(&tx, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &tx, nil
+}
+
+// Restore takes a transaction out of the trash. It returns
+// gorm.ErrRecordNotFound when the transaction is no longer in the trash,
+// e.g. because it was purged meanwhile.
+func (r *transactionRepository) Restore(id string) error {
+	return r.restore("id = ?", id)
+}
+
+func (r *transactionRepository) RestoreTransfer(transferID string) error {
+	return r.restore("transfer_id = ?", transferID)
+}
+
+func (r *transactionRepository) restore(query, arg string) error {
+	res := r.db.Unscoped().Model(&model.Transaction{}).Where(query, arg).Where("deleted_at IS NOT NULL").
+		Update("deleted_at", nil)
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// Purge permanently deletes up to purgeBatchSize transactions deleted before
+// the given time and returns how many it deleted, with their attachments.
+// The rows are locked first, so a concurrent restore either completes before
+// or finds the transaction gone and fails with gorm.ErrRecordNotFound.
+func (r *transactionRepository) Purge(before time.Time) (int, []model.Attachment, error) {
+	var ids []string
+	var attachments []model.Attachment
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&model.Transaction{}).Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("deleted_at < ?", before).Limit(purgeBatchSize).Pluck("id", &ids).Error; err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if err := tx.Where("transaction_id IN ?", ids).Find(&attachments).Error; err != nil {
+			return err
+		}
+		return tx.Unscoped().Delete(&model.Transaction{}, "id IN ?", ids).Error
+	})
+	if err != nil {
+		return 0, nil, err
+	}
+	return len(ids), attachments, nil
+}
+
+// HasOccurrence reports whether the occurrence of a recurring template at the
+// given time was already materialized, including into the trash.
+func (r *transactionRepository) HasOccurrence(recurringID string, at time.Time) (bool, error) {
+	var ids []string
+	err := r.db.Unscoped().Model(&model.Transaction{}).
+		Where("recurring_id = ? AND created_at = ?", recurringID, at).
+		Limit(1).Pluck("id", &ids).Error
+	return len(ids) > 0, err
+}
+
+// ExistingExternalIDs returns which of the given bank references were already
+// imported for the user and account, including deleted transactions. A nil
+// account matches transactions without an account.
+func (r *transactionRepository) ExistingExternalIDs(userID string, accountID *string, ids []string) ([]string, error) {
+	var existing []string
+	if len(ids) == 0 {
+		return existing, nil
+	}
+	q := r.db.Unscoped().Model(&model.Transaction{}).Where("user_id = ? AND external_id IN ?", userID, ids)
+	if accountID != nil {
+		q = q.Where("account_id = ?", *accountID)
+	} else {
+		q = q.Where("account_id IS NULL")
+	}
+	err := q.Pluck("external_id", &existing).Error
+	return existing, err
+}
+
+// DuplicateCandidates returns the user's transactions that could duplicate tx:
+// same amount, currency, type and category, created less than window apart,
+// closest first. Transfers and tx itself are excluded.
+func (r *transactionRepository) DuplicateCandidates(tx *model.Transaction, window time.Duration) ([]model.Transaction, error) {
+	q := r.withBaseAmount().
+		Where("t.user_id = ? AND t.amount = ? AND t.currency = ? AND t.type = ?", tx.UserID, tx.Amount, tx.Currency, tx.Type).
+		Where("t.category_id IS NOT DISTINCT FROM ?", tx.CategoryID).
+		Where("t.created_at > ? AND t.created_at < ?", tx.CreatedAt.Add(-window), tx.CreatedAt.Add(window)).
+		Where("t.transfer_id IS NULL")
+	if tx.ID != "" {
+		q = q.Where("t.id <> ?", tx.ID)
+	}
+	var candidates []model.Transaction
+	err := q.Preload("Tags").Preload("Splits", splitsInOrder).Order(clause.Expr{SQL: "abs(extract(epoch FROM t.created_at - ?)), t.id", Vars: []interface{}{tx.CreatedAt}}).
+		Limit(maxDuplicateCandidates).Find(&candidates).Error
+	return candidates, err
+}
+
+// GetFlagged returns the user's transactions flagged as duplicates, newest first.
+func (r *transactionRepository) GetFlagged(userID string) ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	err := r.withBaseAmount().Preload("Tags").Preload("Splits", splitsInOrder).
+		Where("t.user_id = ? AND t.duplicate_of IS NOT NULL", userID).
+		Order("t.created_at DESC, t.id DESC").Find(&transactions).Error
+	return transactions, err
+}
+
+func (r *transactionRepository) GetByIDs(ids []string) ([]model.Transaction, error) {
+	var transactions []model.Transaction
+	if len(ids) == 0 {
+		return transactions, nil
+	}
+	err := r.withBaseAmount().Preload("Tags").Preload("Splits", splitsInOrder).Where("t.id IN ?", ids).Find(&transactions).Error
+	return transactions, err
+}
+
+// Merge moves the attachments of the duplicate to keep, moves the duplicate
+// to the trash and saves keep in one DB transaction. A bank reference taken
+// over by keep is cleared on the duplicate so that it stays unique.
+func (r *transactionRepository) Merge(keep *model.Transaction, duplicateID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.Attachment{}).Where("transaction_id = ?", duplicateID).
+			Update("transaction_id", keep.ID).Error; err != nil {
+			return err
+		}
+		if keep.ExternalID != nil {
+			if err := tx.Model(&model.Transaction{}).Where("id = ? AND external_id = ?", duplicateID, *keep.ExternalID).
+				Update("external_id", nil).Error; err != nil {
+				return err
+			}
+		}
+		if err := tx.Delete(&model.Transaction{}, "id = ?", duplicateID).Error; err != nil {
+			return err
+		}
+		return tx.Omit("Splits").Save(keep).Error
+	})
+}
+
+// AddVersions appends versions to the history of their transactions.
+func (r *transactionRepository) AddVersions(versions []model.TransactionVersion) error {
+	if len(versions) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(&versions, 500).Error
+}
+
+// GetHistory returns the versions of a transaction owned by userID, oldest
+// first. The history stays available after the transaction is purged.
+func (r *transactionRepository) GetHistory(transactionID, userID string) ([]model.TransactionVersion, error) {
+	var versions []model.TransactionVersion
+	err := r.db.Where("transaction_id = ? AND user_id = ?", transactionID, userID).
+		Order("created_at, id").Find(&versions).Error
+	return versions, err
+}
+
+// Summary sums income and expense in the user's base currency, excluding
+// transfers and deleted transactions.
+func (r *transactionRepository) Summary(userID string, from, to *time.Time) (money.Amount, money.Amount, error) {
+	var income, expense money.Amount
 	type row struct {
 		Type string
-		Sum  float64
+		Sum  money.Amount
 	}
 	var rows []row
 
-	q := r.db.Model(&model.Transaction{}).
-		Select("type, SUM(amount) as sum").
-		Where("user_id = ?", userID)
+	q := r.db.Table("transactions t").
+		Select("t.type, SUM("+baseAmountSQL+") as sum").
+		Joins("JOIN users u ON u.id = t.user_id").
+		Where("t.user_id = ? AND t.transfer_id IS NULL AND t.deleted_at IS NULL", userID)
 
 	if from != nil {
-		q = q.Where("created_at >= ?", *from)
+		q = q.Where("t.created_at >= ?", *from)
 	}
 	if to != nil {
-		q = q.Where("created_at <= ?", *to)
+		q = q.Where("t.created_at <= ?", *to)
 	}
 
-	if err := q.Group("type").Scan(&rows).Error; err != nil {
+	if err := q.Group("t.type").Scan(&rows).Error; err != nil {
 		return 0, 0, err
 	}
 	for _, r := range rows {
@@ -96,22 +498,119 @@ func (r *transactionRepository) Summary(userID string, from, to *time.Time) (flo
 	return income, expense, nil
 }
 
-func (r *transactionRepository) ByCategory(userID string, from, to *time.Time) (map[string]float64, error) {
-	results := make(map[string]float64)
-	rows, err := r.db.Raw(
-		"SELECT category, SUM(amount) FROM transactions WHERE user_id = ? AND created_at BETWEEN ? AND ? GROUP BY category",
-		userID, from, to,
-	).Rows()
-	if err != nil {
+// Currencies returns the distinct currencies of a user's transactions,
+// including deleted ones that could still be restored.
+func (r *transactionRepository) Currencies(userID string) ([]string, error) {
+	var currencies []string
+	err := r.db.Unscoped().Model(&model.Transaction{}).Where("user_id = ?", userID).Distinct().Pluck("currency", &currencies).Error
+	return currencies, err
+}
+
+// ByCategory sums transactions per category in the user's base currency,
+// joining category names.
+// With depth 0 every category gets only its own transactions. With depth N > 0
+// transactions of deeper subcategories are rolled up into their ancestor at
+// level N, resolved by walking the category tree with a recursive query.
+// Split transactions count towards the category of each line with the line
+// amount. Uncategorized transactions are grouped under a nil CategoryID per
+// type. Transfers and deleted transactions are excluded.
+func (r *transactionRepository) ByCategory(userID string, from, to *time.Time, depth int) ([]model.CategoryTotal, error) {
+	args := []interface{}{userID, model.MaxCategoryDepth, userID}
+	conds := "t.user_id = ? AND t.transfer_id IS NULL"
+	if from != nil {
+		conds += " AND t.created_at >= ?"
+		args = append(args, *from)
+	}
+	if to != nil {
+		conds += " AND t.created_at <= ?"
+		args = append(args, *to)
+	}
+
+	// The category itself, or its ancestor at level N when it is deeper.
+	target := "tree.path[cardinality(tree.path)]"
+	if depth > 0 {
+		target = fmt.Sprintf("tree.path[LEAST(cardinality(tree.path), %d)]", depth)
+	}
+
+	query := `
+		WITH RECURSIVE tree AS (
+			SELECT id, ARRAY[id] AS path FROM categories WHERE user_id = ? AND parent_id IS NULL
+			UNION ALL
+			SELECT c.id, tree.path || c.id
+			FROM categories c JOIN tree ON c.parent_id = tree.id
+			WHERE cardinality(tree.path) < ?
+		)
+		SELECT c.id AS category_id, c.parent_id, COALESCE(c.name, '') AS name, t.type, SUM(` + baseAmountSQL + `) AS total
+		FROM ` + splitLinesSQL + `
+		JOIN users u ON u.id = t.user_id
+		LEFT JOIN tree ON tree.id = t.category_id
+		LEFT JOIN categories c ON c.id = ` + target + `
+		WHERE ` + conds + `
+		GROUP BY c.id, c.parent_id, c.name, t.type
+		ORDER BY total DESC`
+
+	var results []model.CategoryTotal
+	if err := r.db.Raw(query, args...).Scan(&results).Error; err != nil {
 		return nil, err
 	}
-	defer rows.Close()
+	return results, nil
+}
+
+// SpentByPeriod sums the expenses of a category and its subcategories in
+// [from, to) in the user's base currency per UTC week (starting on Monday) or
+// month, as period is "week" or "month". Periods without expenses are left
+// out.
+func (r *transactionRepository) SpentByPeriod(userID, categoryID, period string, from, to time.Time) ([]model.PeriodTotal, error) {
+	query := `
+		WITH RECURSIVE sub AS (
+			SELECT id FROM categories WHERE id = ? AND user_id = ?
+			UNION ALL
+			SELECT c.id FROM categories c JOIN sub ON c.parent_id = sub.id
+		)
+		SELECT date_trunc(?, t.created_at AT TIME ZONE 'UTC') AS period_start, SUM(` + baseAmountSQL + `) AS total
+		FROM ` + splitLinesSQL + `
+		JOIN users u ON u.id = t.user_id
+		WHERE t.user_id = ? AND t.transfer_id IS NULL AND t.type = 'expense'
+			AND t.category_id IN (SELECT id FROM sub)
+			AND t.created_at >= ? AND t.created_at < ?
+		GROUP BY 1
+		ORDER BY 1`
+
+	var results []model.PeriodTotal
+	if err := r.db.Raw(query, categoryID, userID, period, userID, from, to).Scan(&results).Error; err != nil {
+		return nil, err
+	}
+	for i := range results {
+		p := results[i].PeriodStart
+		results[i].PeriodStart = time.Date(p.Year(), p.Month(), p.Day(), 0, 0, 0, 0, time.UTC)
+	}
+	return results, nil
+}
 
-	for rows.Next() {
-		var category string
-		var sum float64
-		rows.Scan(&category, &sum)
-		results[category] = sum
+// ByTag sums transactions per tag in the user's base currency, joining tag
+// names. Untagged transactions are grouped under a nil TagID per type.
+// Transfers and deleted transactions are excluded.
+func (r *transactionRepository) ByTag(userID string, from, to *time.Time) ([]model.TagTotal, error) {
+	q := r.db.Table("transactions t").
+		Select("g.id AS tag_id, COALESCE(g.name, '') AS name, t.type, SUM("+baseAmountSQL+") AS total").
+		Joins("JOIN users u ON u.id = t.user_id").
+		Joins("LEFT JOIN transaction_tags tt ON tt.transaction_id = t.id").
+		Joins("LEFT JOIN tags g ON g.id = tt.tag_id").
+		Where("t.user_id = ? AND t.transfer_id IS NULL AND t.deleted_at IS NULL", userID)
+	if from != nil {
+		q = q.Where("t.created_at >= ?", *from)
+	}
+	if to != nil {
+		q = q.Where("t.created_at <= ?", *to)
+	}
+	var results []model.TagTotal
+	if err := q.Group("g.id, g.name, t.type").Order("total DESC").Scan(&results).Error; err != nil {
+		return nil, err
 	}
 	return results, nil
 }
+
+// escapeLike escapes LIKE wildcards so user input is matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -1,17 +1,25 @@
 package repository
 
 import (
+	"time"
 	"statistic_service/internal/model"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type UserRepository interface {
 	Create(user *model.User) error
 	GetByEmail(email string) (*model.User, error)
 	GetByID(id string) (*model.User, error)
+	Update(user *model.User) error
 	CreateRefreshToken(token *model.RefreshToken) error
-	GetRefreshToken(token string) (*model.RefreshToken, error)
-	DeleteRefreshToken(token string) error
+	GetRefreshToken(tokenHash string) (*model.RefreshToken, error)
+	RotateRefreshToken(old, token *model.RefreshToken) (bool, error)
+	GetSessions(userID string, now time.Time) ([]model.RefreshToken, error)
+	DeleteSessions(userID string, ids []string) ([]string, error)
+	DenySessions(ids []string, until time.Time) error
+	IsSessionDenied(id string, now time.Time) (bool, error)
+	AddSecurityEvent(event *model.SecurityEvent) error
 }
 
 type userRepository struct {
@@ -38,16 +46,104 @@ func (r *userRepository) GetByID(id string) (*model.User, error) {
 	return &user, err
 }
 
+func (r *userRepository) Update(user *model.User) error {
+	return r.db.Save(user).Error
+}
+
 func (r *userRepository) CreateRefreshToken(token *model.RefreshToken) error {
 	return r.db.Create(token).Error
 }
 
-func (r *userRepository) GetRefreshToken(token string) (*model.RefreshToken, error) {
+// GetRefreshToken finds a refresh token by the keyed hash of the token.
+func (r *userRepository) GetRefreshToken(tokenHash string) (*model.RefreshToken, error) {
 	var refreshToken model.RefreshToken
-	err := r.db.Where("token = ?", token).First(&refreshToken).Error
+	err := r.db.Where("token_hash = ?", tokenHash).First(&refreshToken).Error
 	return &refreshToken, err
 }
 
-func (r *userRepository) DeleteRefreshToken(token string) error {
-	return r.db.Where("token = ?", token).Delete(&model.RefreshToken{}).Error
+// RotateRefreshToken marks old rotated and stores token in one transaction,
+// dropping rotated tokens of the family that expired. It returns false when
+// old was already rotated, e.g. by a concurrent refresh.
+func (r *userRepository) RotateRefreshToken(old, token *model.RefreshToken) (bool, error) {
+	rotated := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Model(&model.RefreshToken{}).Where("id = ? AND rotated_at IS NULL", old.ID).Update("rotated_at", now)
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		if err := tx.Create(token).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("family_id = ? AND rotated_at IS NOT NULL AND expires_at < ?", token.FamilyID, now).
+			Delete(&model.RefreshToken{}).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
+
+// GetSessions returns the user's current refresh tokens that have not
+// expired, one per session, most recent login first.
+func (r *userRepository) GetSessions(userID string, now time.Time) ([]model.RefreshToken, error) {
+	var tokens []model.RefreshToken
+	err := r.db.Where("user_id = ? AND rotated_at IS NULL AND expires_at > ?", userID, now).
+		Order("created_at DESC, family_id").Find(&tokens).Error
+	return tokens, err
+}
+
+// DeleteSessions deletes the refresh tokens of the user's sessions with the
+// given IDs, or of all sessions when ids is nil, and returns the IDs of the
+// sessions it deleted.
+func (r *userRepository) DeleteSessions(userID string, ids []string) ([]string, error) {
+	var deleted []model.RefreshToken
+	q := r.db.Clauses(clause.Returning{Columns: []clause.Column{{Name: "family_id"}}}).Where("user_id = ?", userID)
+	if ids != nil {
+		q = q.Where("family_id IN ?", ids)
+	}
+	if err := q.Delete(&deleted).Error; err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(deleted))
+	revoked := make([]string, 0, len(deleted))
+	for _, t := range deleted {
+		if !seen[t.FamilyID] {
+			seen[t.FamilyID] = true
+			revoked = append(revoked, t.FamilyID)
+		}
+	}
+	return revoked, nil
+}
+
+// DenySessions adds sessions to the deny-list until the given time and drops
+// entries that expired.
+func (r *userRepository) DenySessions(ids []string, until time.Time) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	denied := make([]model.RevokedSession, len(ids))
+	for i, id := range ids {
+		denied[i] = model.RevokedSession{SessionID: id, ExpiresAt: until}
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.RevokedSession{}, "expires_at < ?", time.Now()).Error; err != nil {
+			return err
+		}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "session_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"expires_at"}),
+		}).Create(&denied).Error
+	})
+}
+
+func (r *userRepository) IsSessionDenied(id string, now time.Time) (bool, error) {
+	var count int64
+	err := r.db.Model(&model.RevokedSession{}).Where("session_id = ? AND expires_at > ?", id, now).Count(&count).Error
+	return count > 0, err
+}
+
+func (r *userRepository) AddSecurityEvent(event *model.SecurityEvent) error {
+	return r.db.Create(event).Error
 }
\ No newline at end of file
@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"time"
+
+	"statistic_service/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type VerificationRepository interface {
+	Create(token *model.EmailVerificationToken) error
+	GetByHash(tokenHash string) (*model.EmailVerificationToken, error)
+	// GetLatest returns the user's most recent token.
+	GetLatest(userID string) (*model.EmailVerificationToken, error)
+	Verify(token *model.EmailVerificationToken, now time.Time) (bool, error)
+}
+
+type verificationRepository struct {
+	db *gorm.DB
+}
+
+func NewVerificationRepository(db *gorm.DB) VerificationRepository {
+	return &verificationRepository{db: db}
+}
+
+// Create stores a token in place of the user's earlier ones, so only the
+// latest email can be used.
+func (r *verificationRepository) Create(token *model.EmailVerificationToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&model.EmailVerificationToken{}, "user_id = ?", token.UserID).Error; err != nil {
+			return err
+		}
+		return tx.Create(token).Error
+	})
+}
+
+func (r *verificationRepository) GetByHash(tokenHash string) (*model.EmailVerificationToken, error) {
+	var token model.EmailVerificationToken
+	if err := r.db.First(&token, "token_hash = ?", tokenHash).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *verificationRepository) GetLatest(userID string) (*model.EmailVerificationToken, error) {
+	var token model.EmailVerificationToken
+	if err := r.db.Where("user_id = ?", userID).Order("created_at DESC").First(&token).Error; err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// Verify deletes the token and marks the user's email verified in one
+// transaction. It returns false when the token expired or was already used,
+// e.g. by a concurrent request.
+func (r *verificationRepository) Verify(token *model.EmailVerificationToken, now time.Time) (bool, error) {
+	verified := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Where("id = ? AND expires_at > ?", token.ID, now).Delete(&model.EmailVerificationToken{})
+		if res.Error != nil || res.RowsAffected == 0 {
+			return res.Error
+		}
+		if err := tx.Model(&model.User{}).Where("id = ?", token.UserID).Update("email_verified", true).Error; err != nil {
+			return err
+		}
+		verified = true
+		return nil
+	})
+	return verified, err
+}
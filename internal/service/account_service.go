@@ -0,0 +1,226 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/repository"
+	"statistic_service/pkg/money"
+	"statistic_service/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// AccountBalance is the balance of an account at a point in time.
+type AccountBalance struct {
+	AccountID string       `json:"account_id"`
+	Currency  string       `json:"currency"`
+	At        time.Time    `json:"at"`
+	Balance   money.Amount `json:"balance" swaggertype:"string" example:"100.00"`
+}
+
+// TransferInput describes a transfer between two accounts of one user.
+// ToAmount is required only when the accounts have different currencies.
+type TransferInput struct {
+	FromAccountID string
+	ToAccountID   string
+	Amount        money.Amount
+	ToAmount      money.Amount
+	Comment       string
+}
+
+// Transfer is a pair of transactions moving money between accounts.
+type Transfer struct {
+	ID   string            `json:"id"`
+	From model.Transaction `json:"from"`
+	To   model.Transaction `json:"to"`
+}
+
+type AccountService interface {
+	Create(userID string, input *model.Account) error
+	List(userID string) ([]model.Account, error)
+	Update(id, userID, name, accountType string) (*model.Account, error)
+	Delete(id, userID string) error
+	Balance(id, userID string, at time.Time) (*AccountBalance, error)
+	Transfer(ctx context.Context, userID string, input TransferInput) (*Transfer, error)
+}
+
+type accountService struct {
+	repo       repository.AccountRepository
+	txRepo     repository.TransactionRepository
+	userRepo   repository.UserRepository
+	currencies CurrencyService
+}
+
+func NewAccountService(r repository.AccountRepository, tx repository.TransactionRepository, u repository.UserRepository, cur CurrencyService) AccountService {
+	return &accountService{r, tx, u, cur}
+}
+
+func (s *accountService) Create(userID string, input *model.Account) error {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return utils.NewValidation("account name must not be empty")
+	}
+	if !isValidAccountType(input.Type) {
+		return utils.NewValidation("account type must be cash, card, savings or credit")
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	input.Currency = strings.ToUpper(strings.TrimSpace(input.Currency))
+	if input.Currency == "" {
+		input.Currency = user.BaseCurrency
+	}
+	if err := s.currencies.CheckConvertible(input.Currency, user.BaseCurrency); err != nil {
+		return err
+	}
+	input.ID = ""
+	input.UserID = userID
+	return s.repo.Create(input)
+}
+
+func (s *accountService) List(userID string) ([]model.Account, error) {
+	return s.repo.GetByUser(userID)
+}
+
+func (s *accountService) Update(id, userID, name, accountType string) (*model.Account, error) {
+	account, err := s.getOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, utils.NewValidation("account name must not be empty")
+	}
+	if !isValidAccountType(accountType) {
+		return nil, utils.NewValidation("account type must be cash, card, savings or credit")
+	}
+	account.Name = name
+	account.Type = accountType
+	if err := s.repo.Update(account); err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func (s *accountService) Delete(id, userID string) error {
+	if _, err := s.getOwned(id, userID); err != nil {
+		return err
+	}
+	used, err := s.repo.HasTransactions(id)
+	if err != nil {
+		return err
+	}
+	if used {
+		return utils.NewConflict("account has transactions, including deleted ones in the trash")
+	}
+	return s.repo.Delete(id)
+}
+
+// Balance returns the opening balance plus all income minus all expenses of
+// the account recorded up to and including at. Transfer legs are included.
+func (s *accountService) Balance(id, userID string, at time.Time) (*AccountBalance, error) {
+	account, err := s.getOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	sum, err := s.repo.Balance(id, at)
+	if err != nil {
+		return nil, err
+	}
+	return &AccountBalance{
+		AccountID: account.ID,
+		Currency:  account.Currency,
+		At:        at,
+		Balance:   account.OpeningBalance.Add(sum),
+	}, nil
+}
+
+// Transfer atomically records an expense on the source account and an income
+// on the destination account sharing one transfer ID.
+func (s *accountService) Transfer(ctx context.Context, userID string, input TransferInput) (*Transfer, error) {
+	if input.FromAccountID == input.ToAccountID {
+		return nil, utils.NewValidation("cannot transfer to the same account")
+	}
+	from, err := s.getOwned(input.FromAccountID, userID)
+	if err != nil {
+		return nil, refError(err, "source account not found")
+	}
+	to, err := s.getOwned(input.ToAccountID, userID)
+	if err != nil {
+		return nil, refError(err, "destination account not found")
+	}
+	if !input.Amount.IsPositive() {
+		return nil, utils.NewValidation("amount must be positive")
+	}
+	toAmount := input.ToAmount
+	if from.Currency == to.Currency {
+		if !toAmount.IsZero() && toAmount != input.Amount {
+			return nil, utils.NewValidation("to_amount must equal amount for accounts in the same currency")
+		}
+		toAmount = input.Amount
+	} else if !toAmount.IsPositive() {
+		return nil, utils.NewValidation("to_amount is required for accounts in different currencies")
+	}
+
+	transferID := uuid.NewString()
+	now := time.Now()
+	out := &model.Transaction{
+		UserID:     userID,
+		Amount:     input.Amount,
+		Currency:   from.Currency,
+		Type:       "expense",
+		AccountID:  &from.ID,
+		TransferID: &transferID,
+		Comment:    input.Comment,
+		CreatedAt:  now,
+	}
+	in := &model.Transaction{
+		UserID:     userID,
+		Amount:     toAmount,
+		Currency:   to.Currency,
+		Type:       "income",
+		AccountID:  &to.ID,
+		TransferID: &transferID,
+		Comment:    input.Comment,
+		CreatedAt:  now,
+	}
+	err = s.txRepo.WithTx(func(r repository.TransactionRepository) error {
+		if err := r.CreateTransfer(out, in); err != nil {
+			return err
+		}
+		return r.AddVersions(createdVersions(ctx, userID, out, in))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Transfer{ID: transferID, From: *out, To: *in}, nil
+}
+
+// getOwned loads an account and hides accounts of other users behind not found.
+func (s *accountService) getOwned(id, userID string) (*model.Account, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, utils.NewNotFound("account not found")
+	}
+	account, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && account.UserID != userID) {
+		return nil, utils.NewNotFound("account not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return account, nil
+}
+
+func isValidAccountType(t string) bool {
+	switch t {
+	case model.AccountCash, model.AccountCard, model.AccountSavings, model.AccountCredit:
+		return true
+	}
+	return false
+}
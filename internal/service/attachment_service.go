@@ -0,0 +1,215 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/repository"
+	"statistic_service/pkg/storage"
+	"statistic_service/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxAttachmentsPerTransaction bounds the number of files on one transaction.
+const MaxAttachmentsPerTransaction = 10
+
+// allowedAttachmentTypes are the accepted content types, detected from the
+// file content rather than trusted from the upload.
+var allowedAttachmentTypes = map[string]bool{
+	"image/jpeg":      true,
+	"image/png":       true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"application/pdf": true,
+}
+
+type AttachmentService interface {
+	Upload(transactionID, userID, fileName string, r io.Reader, size int64) (*model.Attachment, error)
+	List(transactionID, userID string) ([]model.Attachment, error)
+	Open(id, transactionID, userID string) (*model.Attachment, io.ReadCloser, error)
+	Delete(id, transactionID, userID string) error
+}
+
+type attachmentService struct {
+	repo   repository.AttachmentRepository
+	txRepo repository.TransactionRepository
+	blobs  storage.Storage
+	// maxSize is the largest accepted file in bytes.
+	maxSize int64
+}
+
+func NewAttachmentService(r repository.AttachmentRepository, txRepo repository.TransactionRepository, blobs storage.Storage, maxSize int64) AttachmentService {
+	return &attachmentService{r, txRepo, blobs, maxSize}
+}
+
+// Upload stores a file with a transaction. The blob is written before the
+// record, and removed again when the record cannot be saved.
+func (s *attachmentService) Upload(transactionID, userID, fileName string, r io.Reader, size int64) (*model.Attachment, error) {
+	tx, err := s.getTransaction(transactionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if tx.TransferID != nil {
+		return nil, utils.NewValidation("transfers cannot have attachments")
+	}
+	if size <= 0 {
+		return nil, utils.NewValidation("file must not be empty")
+	}
+	if size > s.maxSize {
+		return nil, utils.NewValidation(fmt.Sprintf("file must be at most %s", formatSize(s.maxSize)))
+	}
+	count, err := s.repo.CountByTransaction(tx.ID)
+	if err != nil {
+		return nil, err
+	}
+	if count >= MaxAttachmentsPerTransaction {
+		return nil, utils.NewValidation(fmt.Sprintf("a transaction can have at most %d attachments", MaxAttachmentsPerTransaction))
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+	head = head[:n]
+	contentType := strings.SplitN(http.DetectContentType(head), ";", 2)[0]
+	if !allowedAttachmentTypes[contentType] {
+		return nil, utils.NewValidation("file must be a JPEG, PNG, GIF or WebP image or a PDF document")
+	}
+
+	a := &model.Attachment{
+		ID:            uuid.NewString(),
+		TransactionID: tx.ID,
+		UserID:        userID,
+		FileName:      cleanFileName(fileName),
+		ContentType:   contentType,
+		Size:          size,
+	}
+	a.StorageKey = userID + "/" + a.ID
+	if err := s.blobs.Put(context.Background(), a.StorageKey, io.MultiReader(bytes.NewReader(head), r), size, contentType); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(a); err != nil {
+		_ = s.blobs.Delete(context.Background(), a.StorageKey)
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *attachmentService) List(transactionID, userID string) ([]model.Attachment, error) {
+	if _, err := s.getTransaction(transactionID, userID); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByTransaction(transactionID)
+}
+
+// Open returns an attachment with its content, which the caller must close.
+func (s *attachmentService) Open(id, transactionID, userID string) (*model.Attachment, io.ReadCloser, error) {
+	a, err := s.getOwned(id, transactionID, userID)
+	if err != nil {
+		return nil, nil, err
+	}
+	body, err := s.blobs.Get(context.Background(), a.StorageKey)
+	if errors.Is(err, storage.ErrNotFound) {
+		return nil, nil, utils.NewNotFound("attachment content not found")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return a, body, nil
+}
+
+// Delete removes the record first, so a failure to remove the blob leaves
+// an unused blob rather than a record without content.
+func (s *attachmentService) Delete(id, transactionID, userID string) error {
+	a, err := s.getOwned(id, transactionID, userID)
+	if err != nil {
+		return err
+	}
+	if err := s.repo.Delete(a.ID); err != nil {
+		return err
+	}
+	return removeBlobs(s.blobs, []model.Attachment{*a})
+}
+
+// getOwned loads an attachment of a transaction and hides attachments of
+// other users behind not found.
+func (s *attachmentService) getOwned(id, transactionID, userID string) (*model.Attachment, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, utils.NewNotFound("attachment not found")
+	}
+	a, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && (a.UserID != userID || a.TransactionID != transactionID)) {
+		return nil, utils.NewNotFound("attachment not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+func (s *attachmentService) getTransaction(id, userID string) (*model.Transaction, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, utils.NewNotFound("transaction not found")
+	}
+	tx, err := s.txRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && tx.UserID != userID) {
+		return nil, utils.NewNotFound("transaction not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// removeBlobs deletes the stored content of attachments whose records are
+// already gone.
+func removeBlobs(blobs storage.Storage, list []model.Attachment) error {
+	var errs []error
+	for _, a := range list {
+		if err := blobs.Delete(context.Background(), a.StorageKey); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// cleanFileName keeps the base name of an uploaded file without control
+// characters or quotes, which would break the Content-Disposition header.
+func cleanFileName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+	for len(name) > 255 {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
+
+func formatSize(n int64) string {
+	if n%(1<<20) == 0 {
+		return fmt.Sprintf("%d MB", n>>20)
+	}
+	if n%(1<<10) == 0 {
+		return fmt.Sprintf("%d KB", n>>10)
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
@@ -1,9 +1,12 @@
 package service
 
 import (
+	"context"
+	"crypto/subtle"
 	"errors"
 	"regexp"
 	"time"
+	"unicode/utf8"
 	"statistic_service/internal/model"
 	"statistic_service/internal/repository"
 	"statistic_service/pkg/jwt"
@@ -13,17 +16,48 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// refreshTokenTTL is how long a session lasts without a refresh.
+const refreshTokenTTL = 7 * 24 * time.Hour
+
+// maxUserAgentLength bounds the user agent stored with a session.
+const maxUserAgentLength = 512
+
+// Client describes where a login or refresh comes from.
+type Client struct {
+	UserAgent string
+	IP        string
+}
+
+// Session is an active login of a user, backed by a refresh token.
+type Session struct {
+	ID        string    `json:"id"`
+	UserAgent string    `json:"user_agent"`
+	IP        string    `json:"ip"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// Current marks the session of the access token used for the request.
+	Current bool `json:"current"`
+}
+
 type AuthService struct {
 	userRepo  repository.UserRepository
 	jwtSecret string
 	logger    *logrus.Logger
+	// verification emails new users a link to verify their address; nil
+	// when verification is not set up.
+	verification VerificationService
 }
 
 func NewAuthService(repo repository.UserRepository, secret string, logger *logrus.Logger) *AuthService {
-	return &AuthService{repo, secret, logger}
+	return &AuthService{userRepo: repo, jwtSecret: secret, logger: logger}
+}
+
+// SetVerification makes Register email new users a verification link.
+func (s *AuthService) SetVerification(v VerificationService) {
+	s.verification = v
 }
 
-func (s *AuthService) Register(email, password string) error {
+func (s *AuthService) Register(ctx context.Context, email, password string) error {
 	s.logger.WithFields(logrus.Fields{
 		"email": email,
 	}).Info("Attempting to register user")
@@ -55,11 +89,18 @@ func (s *AuthService) Register(email, password string) error {
 		return err
 	}
 
+	// The user can ask for another email, so registration succeeds anyway.
+	if s.verification != nil {
+		if err := s.verification.Send(ctx, user); err != nil {
+			s.logger.WithError(err).Error("Failed to send verification email")
+		}
+	}
+
 	s.logger.Info("User registered successfully")
 	return nil
 }
 
-func (s *AuthService) Login(email, password string) (string, string, error) {
+func (s *AuthService) Login(email, password string, client Client) (string, string, error) {
 	s.logger.WithFields(logrus.Fields{
 		"email": email,
 	}).Info("Attempting to login user")
@@ -75,23 +116,21 @@ func (s *AuthService) Login(email, password string) (string, string, error) {
 		return "", "", errors.New("invalid email or password")
 	}
 
-	accessToken, err := jwt.GenerateToken(user.ID, s.jwtSecret)
-	if err != nil {
-		s.logger.WithError(err).Error("Failed to generate access token")
-		return "", "", err
-	}
-
 	refreshToken, err := jwt.GenerateRefreshToken()
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to generate refresh token")
 		return "", "", err
 	}
 
+	sessionID := uuid.New().String()
 	refreshTokenModel := &model.RefreshToken{
-		ID:        uuid.New().String(),
+		ID:        sessionID,
+		FamilyID:  sessionID,
 		UserID:    user.ID,
-		Token:     refreshToken,
-		ExpiresAt: time.Now().Add(7 * 24 * time.Hour),
+		TokenHash: jwt.HashToken(refreshToken, s.jwtSecret),
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+		UserAgent: truncate(client.UserAgent, maxUserAgentLength),
+		IP:        client.IP,
 	}
 
 	if err := s.userRepo.CreateRefreshToken(refreshTokenModel); err != nil {
@@ -99,19 +138,33 @@ func (s *AuthService) Login(email, password string) (string, string, error) {
 		return "", "", err
 	}
 
+	accessToken, err := jwt.GenerateToken(user.ID, sessionID, s.jwtSecret)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to generate access token")
+		return "", "", err
+	}
+
 	s.logger.Info("User logged in successfully")
 	return accessToken, refreshToken, nil
 }
 
-func (s *AuthService) RefreshToken(refreshToken string) (string, string, error) {
+// RefreshToken rotates a refresh token within its session and issues a new
+// access token for the session. A token that was already rotated is taken as
+// stolen: its whole session is revoked and a security event is recorded.
+func (s *AuthService) RefreshToken(refreshToken string, client Client) (string, string, error) {
 	s.logger.Info("Attempting to refresh token")
 
-	token, err := s.userRepo.GetRefreshToken(refreshToken)
-	if err != nil {
+	tokenHash := jwt.HashToken(refreshToken, s.jwtSecret)
+	token, err := s.userRepo.GetRefreshToken(tokenHash)
+	if err != nil || subtle.ConstantTimeCompare([]byte(token.TokenHash), []byte(tokenHash)) != 1 {
 		s.logger.WithError(err).Warn("Invalid refresh token")
 		return "", "", utils.NewInvalidRefreshToken()
 	}
 
+	if token.RotatedAt != nil {
+		return "", "", s.revokeReused(token, client)
+	}
+
 	if time.Now().After(token.ExpiresAt) {
 		s.logger.Warn("Refresh token expired")
 		return "", "", utils.NewRefreshTokenExpired()
@@ -123,12 +176,6 @@ func (s *AuthService) RefreshToken(refreshToken string) (string, string, error)
 		return "", "", errors.New("user not found")
 	}
 
-	accessToken, err := jwt.GenerateToken(user.ID, s.jwtSecret)
-	if err != nil {
-		s.logger.WithError(err).Error("Failed to generate new access token")
-		return "", "", err
-	}
-
 	newRefreshToken, err := jwt.GenerateRefreshToken()
 	if err != nil {
 		s.logger.WithError(err).Error("Failed to generate new refresh token")
@@ -137,25 +184,66 @@ func (s *AuthService) RefreshToken(refreshToken string) (string, string, error)
 
 	newRefreshTokenModel := &model.RefreshToken{
 		ID:        uuid.New().String(),
+		FamilyID:  token.FamilyID,
 		UserID:    user.ID,
-		Token:     newRefreshToken,
-		ExpiresAt: time.Now().Add(7 * 24 * time.Hour),
+		TokenHash: jwt.HashToken(newRefreshToken, s.jwtSecret),
+		ExpiresAt: time.Now().Add(refreshTokenTTL),
+		CreatedAt: token.CreatedAt,
+		UserAgent: truncate(client.UserAgent, maxUserAgentLength),
+		IP:        client.IP,
 	}
 
-	if err := s.userRepo.CreateRefreshToken(newRefreshTokenModel); err != nil {
-		s.logger.WithError(err).Error("Failed to save new refresh token")
+	rotated, err := s.userRepo.RotateRefreshToken(token, newRefreshTokenModel)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to rotate refresh token")
 		return "", "", err
 	}
+	if !rotated {
+		// A concurrent refresh used the token first.
+		return "", "", s.revokeReused(token, client)
+	}
 
-	if err := s.userRepo.DeleteRefreshToken(refreshToken); err != nil {
-		s.logger.WithError(err).Warn("Failed to delete old refresh token")
-		// Log but don't fail, as new tokens are already issued
+	accessToken, err := jwt.GenerateToken(user.ID, token.FamilyID, s.jwtSecret)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to generate new access token")
+		return "", "", err
 	}
 
 	s.logger.Info("Token refreshed successfully")
 	return accessToken, newRefreshToken, nil
 }
 
+// revokeReused revokes the session of a refresh token presented after it was
+// rotated and records the reuse as a security event.
+func (s *AuthService) revokeReused(token *model.RefreshToken, client Client) error {
+	log := s.logger.WithFields(logrus.Fields{"userID": token.UserID, "sessionID": token.FamilyID, "ip": client.IP})
+	log.Warn("Refresh token reuse detected, revoking session")
+
+	revoked, err := s.userRepo.DeleteSessions(token.UserID, []string{token.FamilyID})
+	if err != nil {
+		log.WithError(err).Error("Failed to delete session")
+		return err
+	}
+	if len(revoked) == 0 {
+		revoked = []string{token.FamilyID}
+	}
+	if err := s.deny(revoked); err != nil {
+		return err
+	}
+	event := &model.SecurityEvent{
+		UserID:    token.UserID,
+		Type:      model.SecurityEventRefreshTokenReuse,
+		SessionID: token.FamilyID,
+		UserAgent: truncate(client.UserAgent, maxUserAgentLength),
+		IP:        client.IP,
+	}
+	if err := s.userRepo.AddSecurityEvent(event); err != nil {
+		log.WithError(err).Error("Failed to record security event")
+		return err
+	}
+	return utils.NewRefreshTokenReused()
+}
+
 func (s *AuthService) GetUserByID(id string) (*model.User, error) {
 	user, err := s.userRepo.GetByID(id)
 	if err != nil {
@@ -165,6 +253,96 @@ func (s *AuthService) GetUserByID(id string) (*model.User, error) {
 	return user, nil
 }
 
+// Sessions lists the user's active sessions, marking currentID as current.
+func (s *AuthService) Sessions(userID, currentID string) ([]Session, error) {
+	tokens, err := s.userRepo.GetSessions(userID, time.Now())
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to list sessions")
+		return nil, err
+	}
+	sessions := make([]Session, len(tokens))
+	for i, t := range tokens {
+		sessions[i] = Session{
+			ID:        t.FamilyID,
+			UserAgent: t.UserAgent,
+			IP:        t.IP,
+			CreatedAt: t.CreatedAt,
+			ExpiresAt: t.ExpiresAt,
+			Current:   t.FamilyID == currentID,
+		}
+	}
+	return sessions, nil
+}
+
+// Logout ends the session of the access token used for the request.
+func (s *AuthService) Logout(userID, sessionID string) error {
+	if sessionID == "" {
+		return utils.NewValidation("access token has no session, log in again")
+	}
+	revoked, err := s.userRepo.DeleteSessions(userID, []string{sessionID})
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to delete session")
+		return err
+	}
+	// The access token is denied even when the session already expired.
+	if len(revoked) == 0 {
+		revoked = []string{sessionID}
+	}
+	return s.deny(revoked)
+}
+
+// RevokeSession ends one session of the user.
+func (s *AuthService) RevokeSession(id, userID string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return utils.NewNotFound("session not found")
+	}
+	revoked, err := s.userRepo.DeleteSessions(userID, []string{id})
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to delete session")
+		return err
+	}
+	if len(revoked) == 0 {
+		return utils.NewNotFound("session not found")
+	}
+	return s.deny(revoked)
+}
+
+// RevokeAllSessions ends every session of the user, including the current
+// one, and returns how many it ended.
+func (s *AuthService) RevokeAllSessions(userID string) (int, error) {
+	revoked, err := s.userRepo.DeleteSessions(userID, nil)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to delete sessions")
+		return 0, err
+	}
+	return len(revoked), s.deny(revoked)
+}
+
+// SessionRevoked reports whether the access tokens of a session are denied.
+func (s *AuthService) SessionRevoked(sessionID string) (bool, error) {
+	return s.userRepo.IsSessionDenied(sessionID, time.Now())
+}
+
+// deny adds sessions to the deny-list for as long as their access tokens
+// can be valid.
+func (s *AuthService) deny(sessionIDs []string) error {
+	if err := s.userRepo.DenySessions(sessionIDs, time.Now().Add(jwt.AccessTokenTTL)); err != nil {
+		s.logger.WithError(err).Error("Failed to deny sessions")
+		return err
+	}
+	s.logger.WithField("count", len(sessionIDs)).Info("Sessions revoked")
+	return nil
+}
+
+// truncate shortens s to at most n bytes without splitting a character.
+func truncate(s string, n int) string {
+	for len(s) > n {
+		_, size := utf8.DecodeLastRuneInString(s)
+		s = s[:len(s)-size]
+	}
+	return s
+}
+
 func isPasswordComplex(password string) bool {
 	if len(password) < 8 {
 		return false
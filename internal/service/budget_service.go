@@ -0,0 +1,245 @@
+package service
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/repository"
+	"statistic_service/pkg/money"
+	"statistic_service/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Budget statuses.
+const (
+	BudgetOnTrack = "on_track"
+	BudgetAtRisk  = "at_risk"
+	BudgetOver    = "over"
+)
+
+// BudgetProgress is the state of a budget in the period containing a point in time.
+// Available is the limit plus the amount rolled over from earlier periods.
+// Status is over once Spent exceeds Available, and at_risk when Projected,
+// the spend at the end of the period at the current daily pace, would.
+type BudgetProgress struct {
+	BudgetID    string       `json:"budget_id"`
+	CategoryID  string       `json:"category_id"`
+	Period      string       `json:"period"`
+	PeriodStart time.Time    `json:"period_start"`
+	PeriodEnd   time.Time    `json:"period_end"`
+	Limit       money.Amount `json:"limit" swaggertype:"string" example:"300.00"`
+	RolledOver  money.Amount `json:"rolled_over" swaggertype:"string" example:"25.00"`
+	Available   money.Amount `json:"available" swaggertype:"string" example:"325.00"`
+	Spent       money.Amount `json:"spent" swaggertype:"string" example:"120.00"`
+	Remaining   money.Amount `json:"remaining" swaggertype:"string" example:"205.00"`
+	PercentUsed float64      `json:"percent_used" example:"36.92"`
+	Projected   money.Amount `json:"projected" swaggertype:"string" example:"310.00"`
+	Status      string       `json:"status" example:"on_track"`
+}
+
+type BudgetService interface {
+	Create(userID string, input *model.Budget) error
+	List(userID string) ([]model.Budget, error)
+	Update(id, userID string, limit money.Amount, rollover bool) (*model.Budget, error)
+	Delete(id, userID string) error
+	Progress(id, userID string, at time.Time) (*BudgetProgress, error)
+	ProgressAll(userID string, at time.Time) ([]BudgetProgress, error)
+}
+
+type budgetService struct {
+	repo         repository.BudgetRepository
+	categoryRepo repository.CategoryRepository
+	txs          TransactionService
+}
+
+func NewBudgetService(r repository.BudgetRepository, c repository.CategoryRepository, txs TransactionService) BudgetService {
+	return &budgetService{r, c, txs}
+}
+
+func (s *budgetService) Create(userID string, input *model.Budget) error {
+	if input.Period != model.BudgetWeekly && input.Period != model.BudgetMonthly {
+		return utils.NewValidation("period must be weekly or monthly")
+	}
+	if !input.Limit.IsPositive() {
+		return utils.NewValidation("limit must be positive")
+	}
+	if _, err := uuid.Parse(input.CategoryID); err != nil {
+		return utils.NewValidation("category not found")
+	}
+	category, err := s.categoryRepo.GetByID(input.CategoryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && category.UserID != userID) {
+		return utils.NewValidation("category not found")
+	}
+	if err != nil {
+		return err
+	}
+	if category.Type != "expense" {
+		return utils.NewValidation("budgets can only be set for expense categories")
+	}
+	_, err = s.repo.GetByCategory(input.CategoryID, input.Period)
+	if err == nil {
+		return utils.NewConflict("category already has a budget for this period")
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+	input.ID = ""
+	input.UserID = userID
+	return s.repo.Create(input)
+}
+
+func (s *budgetService) List(userID string) ([]model.Budget, error) {
+	return s.repo.GetByUser(userID)
+}
+
+func (s *budgetService) Update(id, userID string, limit money.Amount, rollover bool) (*model.Budget, error) {
+	budget, err := s.getOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if !limit.IsPositive() {
+		return nil, utils.NewValidation("limit must be positive")
+	}
+	budget.Limit = limit
+	budget.Rollover = rollover
+	if err := s.repo.Update(budget); err != nil {
+		return nil, err
+	}
+	return budget, nil
+}
+
+func (s *budgetService) Delete(id, userID string) error {
+	if _, err := s.getOwned(id, userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *budgetService) Progress(id, userID string, at time.Time) (*BudgetProgress, error) {
+	budget, err := s.getOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.progress(budget, at)
+}
+
+func (s *budgetService) ProgressAll(userID string, at time.Time) ([]BudgetProgress, error) {
+	budgets, err := s.repo.GetByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	list := make([]BudgetProgress, 0, len(budgets))
+	for i := range budgets {
+		p, err := s.progress(&budgets[i], at)
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, *p)
+	}
+	return list, nil
+}
+
+func (s *budgetService) progress(budget *model.Budget, at time.Time) (*BudgetProgress, error) {
+	// Progress can be looked up for the next period at most.
+	_, currentEnd := periodBounds(budget.Period, time.Now())
+	if _, nextEnd := periodBounds(budget.Period, currentEnd); !at.Before(nextEnd) {
+		return nil, utils.NewValidation("at must not be more than one period ahead")
+	}
+
+	start, end := periodBounds(budget.Period, at)
+	until := end
+	if at.Before(end) {
+		until = at
+	}
+	// One query sums the current period and, for rollover, every earlier
+	// period since the budget was created.
+	from := start
+	if budget.Rollover {
+		if first, _ := periodBounds(budget.Period, budget.CreatedAt); first.Before(start) {
+			from = first
+		}
+	}
+	totals, err := s.txs.SpentByPeriod(budget.UserID, budget.CategoryID, truncUnit(budget.Period), from, until)
+	if err != nil {
+		return nil, err
+	}
+	spentIn := make(map[time.Time]money.Amount, len(totals))
+	for _, t := range totals {
+		spentIn[t.PeriodStart] = t.Total
+	}
+
+	var rolledOver money.Amount
+	for ps, pe := periodBounds(budget.Period, from); ps.Before(start); ps, pe = periodBounds(budget.Period, pe) {
+		rolledOver = budget.Limit.Add(rolledOver).Sub(spentIn[ps])
+		if rolledOver.IsNegative() {
+			rolledOver = 0
+		}
+	}
+
+	spent := spentIn[start]
+	available := budget.Limit.Add(rolledOver)
+	days := int64(end.Sub(start) / (secondsPerDay * time.Second))
+	_, projected := Forecast(spent, start, until, days)
+
+	status := BudgetOnTrack
+	if spent > available {
+		status = BudgetOver
+	} else if projected > available {
+		status = BudgetAtRisk
+	}
+	return &BudgetProgress{
+		BudgetID:    budget.ID,
+		CategoryID:  budget.CategoryID,
+		Period:      budget.Period,
+		PeriodStart: start,
+		PeriodEnd:   end,
+		Limit:       budget.Limit,
+		RolledOver:  rolledOver,
+		Available:   available,
+		Spent:       spent,
+		Remaining:   available.Sub(spent),
+		PercentUsed: math.Round(float64(spent.Minor())*10000/float64(available.Minor())) / 100,
+		Projected:   projected,
+		Status:      status,
+	}, nil
+}
+
+// truncUnit is the date_trunc unit of a budget period.
+func truncUnit(period string) string {
+	if period == model.BudgetWeekly {
+		return "week"
+	}
+	return "month"
+}
+
+// getOwned loads a budget and hides budgets of other users behind not found.
+func (s *budgetService) getOwned(id, userID string) (*model.Budget, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, utils.NewNotFound("budget not found")
+	}
+	budget, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && budget.UserID != userID) {
+		return nil, utils.NewNotFound("budget not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return budget, nil
+}
+
+// periodBounds returns the UTC period containing t as [start, end). Weeks
+// start on Monday.
+func periodBounds(period string, t time.Time) (time.Time, time.Time) {
+	t = t.UTC()
+	if period == model.BudgetWeekly {
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		start := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+		return start, start.AddDate(0, 0, 7)
+	}
+	start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return start, start.AddDate(0, 1, 0)
+}
@@ -0,0 +1,208 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/repository"
+	"statistic_service/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type CategoryService interface {
+	Create(userID string, input *model.Category) error
+	List(userID, catType string) ([]model.Category, error)
+	Rename(id, userID, name string) (*model.Category, error)
+	Move(id, userID string, parentID *string) (*model.Category, error)
+	Delete(id, userID string) error
+}
+
+type categoryService struct {
+	repo repository.CategoryRepository
+}
+
+func NewCategoryService(r repository.CategoryRepository) CategoryService {
+	return &categoryService{r}
+}
+
+func (s *categoryService) Create(userID string, input *model.Category) error {
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return utils.NewValidation("category name must not be empty")
+	}
+	if !isValidTxType(input.Type) {
+		return utils.NewValidation("category type must be income or expense")
+	}
+	if input.ParentID != nil && *input.ParentID == "" {
+		input.ParentID = nil
+	}
+	if input.ParentID != nil {
+		parent, err := s.getOwned(*input.ParentID, userID)
+		if err != nil {
+			return refError(err, "parent category not found")
+		}
+		if parent.Type != input.Type {
+			return utils.NewValidation("parent category type does not match category type")
+		}
+		ancestors, err := s.repo.AncestorIDs(parent.ID)
+		if err != nil {
+			return err
+		}
+		if len(ancestors)+1 > model.MaxCategoryDepth {
+			return utils.NewValidation(fmt.Sprintf("category tree must not be deeper than %d levels", model.MaxCategoryDepth))
+		}
+	}
+	if err := s.ensureNameFree(userID, input.ParentID, input.Name, ""); err != nil {
+		return err
+	}
+	input.ID = ""
+	input.UserID = userID
+	return nameTaken(s.repo.Create(input))
+}
+
+func (s *categoryService) List(userID, catType string) ([]model.Category, error) {
+	if catType != "" && !isValidTxType(catType) {
+		return nil, utils.NewValidation("category type must be income or expense")
+	}
+	return s.repo.GetByUser(userID, catType)
+}
+
+func (s *categoryService) Rename(id, userID, name string) (*model.Category, error) {
+	category, err := s.getOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, utils.NewValidation("category name must not be empty")
+	}
+	if err := s.ensureNameFree(userID, category.ParentID, name, category.ID); err != nil {
+		return nil, err
+	}
+	category.Name = name
+	if err := s.repo.Update(category); err != nil {
+		return nil, nameTaken(err)
+	}
+	return category, nil
+}
+
+// Move re-parents a category. A nil parentID makes it a root category.
+// The new parent must not be the category itself or one of its descendants,
+// and the moved subtree must still fit into MaxCategoryDepth.
+func (s *categoryService) Move(id, userID string, parentID *string) (*model.Category, error) {
+	category, err := s.getOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if parentID != nil && *parentID == "" {
+		parentID = nil
+	}
+
+	depth := 0
+	if parentID != nil {
+		parent, err := s.getOwned(*parentID, userID)
+		if err != nil {
+			return nil, refError(err, "parent category not found")
+		}
+		if parent.Type != category.Type {
+			return nil, utils.NewValidation("parent category type does not match category type")
+		}
+		ancestors, err := s.repo.AncestorIDs(parent.ID)
+		if err != nil {
+			return nil, err
+		}
+		for _, ancestorID := range ancestors {
+			if ancestorID == category.ID {
+				return nil, utils.NewValidation("category cannot be moved under itself or its subcategory")
+			}
+		}
+		depth = len(ancestors)
+	}
+
+	height, err := s.repo.SubtreeHeight(category.ID)
+	if err != nil {
+		return nil, err
+	}
+	if depth+height > model.MaxCategoryDepth {
+		return nil, utils.NewValidation(fmt.Sprintf("category tree must not be deeper than %d levels", model.MaxCategoryDepth))
+	}
+	if err := s.ensureNameFree(userID, parentID, category.Name, category.ID); err != nil {
+		return nil, err
+	}
+
+	category.ParentID = parentID
+	if err := s.repo.Update(category); err != nil {
+		return nil, nameTaken(err)
+	}
+	return category, nil
+}
+
+func (s *categoryService) Delete(id, userID string) error {
+	if _, err := s.getOwned(id, userID); err != nil {
+		return err
+	}
+	hasChildren, err := s.repo.HasChildren(id)
+	if err != nil {
+		return err
+	}
+	if hasChildren {
+		return utils.NewConflict("category has subcategories")
+	}
+	return s.repo.Delete(id)
+}
+
+// getOwned loads a category and hides categories of other users behind not found.
+func (s *categoryService) getOwned(id, userID string) (*model.Category, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, utils.NewNotFound("category not found")
+	}
+	category, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && category.UserID != userID) {
+		return nil, utils.NewNotFound("category not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+func (s *categoryService) ensureNameFree(userID string, parentID *string, name, exceptID string) error {
+	existing, err := s.repo.GetByName(userID, parentID, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != exceptID {
+		return utils.NewConflict("category with this name already exists")
+	}
+	return nil
+}
+
+// nameTaken reports a name taken by a concurrent request after ensureNameFree
+// passed with the same conflict.
+func nameTaken(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return utils.NewConflict("category with this name already exists")
+	}
+	return err
+}
+
+// refError reports a missing referenced entity as a validation problem of
+// the request rather than as a missing resource.
+func refError(err error, message string) error {
+	var appErr *utils.AppError
+	if errors.As(err, &appErr) && appErr.Type == utils.ErrNotFound {
+		return utils.NewValidation(message)
+	}
+	return err
+}
+
+func isValidTxType(t string) bool {
+	return t == "income" || t == "expense"
+}
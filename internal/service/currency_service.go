@@ -0,0 +1,239 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/repository"
+	"statistic_service/pkg/utils"
+)
+
+var currencyCodeRe = regexp.MustCompile(`^[A-Z]{3}$`)
+
+type CurrencyService interface {
+	// ImportRates loads exchange rates from a CSV file or an ECB XML file
+	// and returns the number of stored rates.
+	ImportRates(r io.Reader) (int, error)
+	SetBaseCurrency(userID, currency string) error
+	// CheckConvertible reports a validation error unless amounts in currency
+	// can be converted into base.
+	CheckConvertible(currency, base string) error
+}
+
+type currencyService struct {
+	rateRepo repository.RateRepository
+	userRepo repository.UserRepository
+	txRepo   repository.TransactionRepository
+}
+
+func NewCurrencyService(rates repository.RateRepository, users repository.UserRepository, txs repository.TransactionRepository) CurrencyService {
+	return &currencyService{rates, users, txs}
+}
+
+func (s *currencyService) ImportRates(r io.Reader) (int, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(512)
+	var rates []model.ExchangeRate
+	var err error
+	if bytes.HasPrefix(bytes.TrimSpace(head), []byte("<")) {
+		rates, err = parseECBRates(br)
+	} else {
+		rates, err = parseCSVRates(br)
+	}
+	if err != nil {
+		return 0, err
+	}
+	rates = dedupeRates(rates)
+	if err := s.rateRepo.Upsert(rates); err != nil {
+		return 0, err
+	}
+	return len(rates), nil
+}
+
+// SetBaseCurrency changes the reporting currency of the user. Every currency
+// the user already has transactions in must be convertible into it.
+func (s *currencyService) SetBaseCurrency(userID, currency string) error {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if !currencyCodeRe.MatchString(currency) {
+		return utils.NewValidation("currency must be a 3-letter ISO 4217 code")
+	}
+	used, err := s.txRepo.Currencies(userID)
+	if err != nil {
+		return err
+	}
+	for _, c := range used {
+		if err := s.CheckConvertible(c, currency); err != nil {
+			return err
+		}
+	}
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return utils.NewNotFound("user not found")
+	}
+	user.BaseCurrency = currency
+	return s.userRepo.Update(user)
+}
+
+func (s *currencyService) CheckConvertible(currency, base string) error {
+	if !currencyCodeRe.MatchString(currency) {
+		return utils.NewValidation("currency must be a 3-letter ISO 4217 code")
+	}
+	if currency == base {
+		return nil
+	}
+	for _, c := range []string{currency, base} {
+		if c == model.RateReferenceCurrency {
+			continue
+		}
+		ok, err := s.rateRepo.HasRates(c)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return utils.NewValidation(fmt.Sprintf("no exchange rates known for %s", c))
+		}
+	}
+	return nil
+}
+
+// parseCSVRates reads either a long file with a "date,currency,rate" header or
+// the wide ECB layout with a "Date" column followed by one column per currency.
+// Rates are units of currency per one EUR; empty and "N/A" cells are skipped.
+func parseCSVRates(r io.Reader) ([]model.ExchangeRate, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	cr.FieldsPerRecord = -1
+	header, err := cr.Read()
+	if err != nil {
+		return nil, utils.NewValidation("rates file is empty or not valid CSV")
+	}
+	for i := range header {
+		header[i] = strings.TrimSpace(header[i])
+	}
+	long := len(header) >= 3 && strings.EqualFold(header[0], "date") &&
+		strings.EqualFold(header[1], "currency") && strings.EqualFold(header[2], "rate")
+	if !long && (len(header) < 2 || !strings.EqualFold(header[0], "date")) {
+		return nil, utils.NewValidation("rates CSV must start with a date column")
+	}
+
+	var rates []model.ExchangeRate
+	line := 1
+	for {
+		rec, err := cr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, utils.NewValidation(fmt.Sprintf("line %d: %v", line, err))
+		}
+		date, err := time.Parse("2006-01-02", strings.TrimSpace(rec[0]))
+		if err != nil {
+			return nil, utils.NewValidation(fmt.Sprintf("line %d: invalid date %q", line, rec[0]))
+		}
+		if long {
+			if len(rec) < 3 {
+				return nil, utils.NewValidation(fmt.Sprintf("line %d: expected date,currency,rate", line))
+			}
+			rate, err := parseRate(rec[1], rec[2])
+			if err != nil {
+				return nil, utils.NewValidation(fmt.Sprintf("line %d: %v", line, err))
+			}
+			if rate != nil {
+				rate.Date = date
+				rates = append(rates, *rate)
+			}
+			continue
+		}
+		for i := 1; i < len(rec) && i < len(header); i++ {
+			rate, err := parseRate(header[i], rec[i])
+			if err != nil {
+				return nil, utils.NewValidation(fmt.Sprintf("line %d: %v", line, err))
+			}
+			if rate != nil {
+				rate.Date = date
+				rates = append(rates, *rate)
+			}
+		}
+	}
+	return rates, nil
+}
+
+// parseECBRates reads the ECB eurofxref XML format:
+// <Cube><Cube time="2024-01-02"><Cube currency="USD" rate="1.0956"/>...
+func parseECBRates(r io.Reader) ([]model.ExchangeRate, error) {
+	var doc struct {
+		Days []struct {
+			Time  string `xml:"time,attr"`
+			Rates []struct {
+				Currency string `xml:"currency,attr"`
+				Rate     string `xml:"rate,attr"`
+			} `xml:"Cube"`
+		} `xml:"Cube>Cube"`
+	}
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, utils.NewValidation(fmt.Sprintf("invalid rates XML: %v", err))
+	}
+	var rates []model.ExchangeRate
+	for _, day := range doc.Days {
+		date, err := time.Parse("2006-01-02", day.Time)
+		if err != nil {
+			return nil, utils.NewValidation(fmt.Sprintf("invalid date %q", day.Time))
+		}
+		for _, cube := range day.Rates {
+			rate, err := parseRate(cube.Currency, cube.Rate)
+			if err != nil {
+				return nil, utils.NewValidation(fmt.Sprintf("%s: %v", day.Time, err))
+			}
+			if rate != nil {
+				rate.Date = date
+				rates = append(rates, *rate)
+			}
+		}
+	}
+	return rates, nil
+}
+
+// dedupeRates keeps the last rate per currency and day, since one upsert
+// statement cannot touch the same row twice.
+func dedupeRates(rates []model.ExchangeRate) []model.ExchangeRate {
+	index := make(map[string]int, len(rates))
+	var out []model.ExchangeRate
+	for _, r := range rates {
+		key := r.Currency + r.Date.Format("2006-01-02")
+		if i, ok := index[key]; ok {
+			out[i] = r
+			continue
+		}
+		index[key] = len(out)
+		out = append(out, r)
+	}
+	return out
+}
+
+// parseRate returns nil for a missing rate.
+func parseRate(currency, value string) (*model.ExchangeRate, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	value = strings.TrimSpace(value)
+	if value == "" || strings.EqualFold(value, "N/A") {
+		return nil, nil
+	}
+	if !currencyCodeRe.MatchString(currency) {
+		return nil, fmt.Errorf("invalid currency %q", currency)
+	}
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil || rate <= 0 {
+		return nil, fmt.Errorf("invalid rate %q for %s", value, currency)
+	}
+	return &model.ExchangeRate{Currency: currency, Rate: rate}, nil
+}
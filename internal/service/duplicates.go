@@ -0,0 +1,177 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/repository"
+	"statistic_service/pkg/fuzzy"
+	"statistic_service/pkg/utils"
+)
+
+// DuplicatePolicy decides what happens to a new transaction that looks like
+// a duplicate of an existing one.
+type DuplicatePolicy string
+
+const (
+	// DuplicateFlag stores the transaction marked with DuplicateOf.
+	DuplicateFlag DuplicatePolicy = "flag"
+	// DuplicateReject refuses the transaction with a DuplicateError.
+	DuplicateReject DuplicatePolicy = "reject"
+	// DuplicateIgnore stores the transaction without looking for duplicates.
+	DuplicateIgnore DuplicatePolicy = "ignore"
+)
+
+// commentSimilarity is how alike two comments must be for the transactions to
+// count as duplicates.
+const commentSimilarity = 0.8
+
+// DuplicateError is returned when a transaction is rejected as a likely
+// duplicate of the candidates.
+type DuplicateError struct {
+	Candidates []model.Transaction
+}
+
+func (e *DuplicateError) Error() string {
+	return "transaction looks like a duplicate of an existing one"
+}
+
+// DuplicatePair is a flagged transaction together with the transaction it
+// likely duplicates.
+type DuplicatePair struct {
+	Transaction model.Transaction `json:"transaction"`
+	Original    model.Transaction `json:"original"`
+}
+
+func validatePolicy(policy DuplicatePolicy) error {
+	switch policy {
+	case DuplicateFlag, DuplicateReject, DuplicateIgnore:
+		return nil
+	}
+	return utils.NewValidation("on_duplicate must be one of: flag reject ignore")
+}
+
+// FindDuplicates returns the existing transactions tx likely duplicates: same
+// amount, currency, type and category, created within the duplicate window,
+// with a similar comment. The closest match comes first.
+func (s *txService) FindDuplicates(userID string, tx *model.Transaction) ([]model.Transaction, error) {
+	candidates, err := s.repo.DuplicateCandidates(&model.Transaction{
+		ID:         tx.ID,
+		UserID:     userID,
+		Amount:     tx.Amount,
+		Currency:   tx.Currency,
+		Type:       tx.Type,
+		CategoryID: tx.CategoryID,
+		CreatedAt:  tx.CreatedAt,
+	}, s.duplicateWindow)
+	if err != nil {
+		return nil, err
+	}
+	duplicates := candidates[:0]
+	for _, c := range candidates {
+		if fuzzy.Match(tx.Comment, c.Comment, commentSimilarity) {
+			duplicates = append(duplicates, c)
+		}
+	}
+	return duplicates, nil
+}
+
+// Duplicates lists the user's flagged transactions with their originals.
+func (s *txService) Duplicates(userID string) ([]DuplicatePair, error) {
+	flagged, err := s.repo.GetFlagged(userID)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, len(flagged))
+	for i, tx := range flagged {
+		ids[i] = *tx.DuplicateOf
+	}
+	originals, err := s.repo.GetByIDs(ids)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]model.Transaction, len(originals))
+	for _, tx := range originals {
+		byID[tx.ID] = tx
+	}
+	pairs := make([]DuplicatePair, 0, len(flagged))
+	for _, tx := range flagged {
+		if original, ok := byID[*tx.DuplicateOf]; ok {
+			pairs = append(pairs, DuplicatePair{Transaction: tx, Original: original})
+		}
+	}
+	return pairs, nil
+}
+
+// Merge resolves a flagged transaction by moving it to the trash and keeping
+// its original. Category, account, comment and bank reference missing on the
+// original are taken from the duplicate, and its tags are added.
+func (s *txService) Merge(ctx context.Context, id, userID string) (*model.Transaction, error) {
+	dup, err := s.getOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if dup.DuplicateOf == nil {
+		return nil, utils.NewValidation("transaction is not flagged as a duplicate")
+	}
+	original, err := s.getOwned(*dup.DuplicateOf, userID)
+	if err != nil {
+		return nil, err
+	}
+	before := *original
+	if original.CategoryID == nil && len(original.Splits) == 0 {
+		original.CategoryID = dup.CategoryID
+	}
+	if original.AccountID == nil {
+		original.AccountID = dup.AccountID
+	}
+	if original.Comment == "" {
+		original.Comment = dup.Comment
+	}
+	if original.ExternalID == nil {
+		original.ExternalID = dup.ExternalID
+	}
+	for _, tag := range dup.Tags {
+		addTag(original, tag)
+	}
+	if err := s.validate(userID, original); err != nil {
+		return nil, err
+	}
+	// The duplicate goes to the trash, its last version points to the original.
+	keptID, _ := json.Marshal(original.ID)
+	merged := newVersion(ctx, userID, model.HistoryMerge, dup, dup)
+	merged.Changes = model.FieldChanges{"merged_into": {Before: json.RawMessage("null"), After: keptID}}
+	err = s.repo.WithTx(func(r repository.TransactionRepository) error {
+		if err := r.Merge(original, dup.ID); err != nil {
+			return err
+		}
+		return r.AddVersions([]model.TransactionVersion{
+			newVersion(ctx, userID, model.HistoryUpdate, &before, original),
+			merged,
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return original, nil
+}
+
+// Dismiss clears the duplicate flag of a transaction that is not a duplicate.
+func (s *txService) Dismiss(ctx context.Context, id, userID string) error {
+	tx, err := s.getOwned(id, userID)
+	if err != nil {
+		return err
+	}
+	if tx.DuplicateOf == nil {
+		return utils.NewValidation("transaction is not flagged as a duplicate")
+	}
+	before := *tx
+	tx.DuplicateOf = nil
+	return s.repo.WithTx(func(r repository.TransactionRepository) error {
+		if err := r.Update(tx); err != nil {
+			return err
+		}
+		return r.AddVersions([]model.TransactionVersion{newVersion(ctx, userID, model.HistoryUpdate, &before, tx)})
+	})
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"time"
+
+	"statistic_service/pkg/money"
+)
+
+const secondsPerDay = 24 * 60 * 60
+
+// Forecast extrapolates total, accumulated between start and now, at its
+// current daily pace. It returns the average per day and the projected total
+// over days. Elapsed time is measured in seconds so rounding happens once.
+func Forecast(total money.Amount, start, now time.Time, days int64) (average, projected money.Amount) {
+	secondsPassed := int64(now.Sub(start) / time.Second)
+	if secondsPassed <= 0 {
+		secondsPassed = secondsPerDay
+	}
+	return total.MulFrac(secondsPerDay, secondsPassed), total.MulFrac(secondsPerDay*days, secondsPassed)
+}
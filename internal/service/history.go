@@ -0,0 +1,147 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"statistic_service/internal/model"
+	"statistic_service/pkg/money"
+	"statistic_service/pkg/requestid"
+	"statistic_service/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// historyFields are the JSON names of the transaction fields whose changes
+// are recorded in its history.
+var historyFields = []string{
+	"amount", "currency", "type", "category_id", "account_id", "transfer_id",
+	"external_id", "duplicate_of", "comment", "created_at", "tags", "splits",
+}
+
+// historySplit is a split line as recorded in the history. Split lines get
+// new IDs on every update, so the ID is left out.
+type historySplit struct {
+	CategoryID *string      `json:"category_id"`
+	Amount     money.Amount `json:"amount"`
+	Note       string       `json:"note,omitempty"`
+}
+
+// History returns the changes of a transaction of the user, oldest first.
+// The history of deleted and purged transactions stays available.
+func (s *txService) History(id, userID string) ([]model.TransactionVersion, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, utils.NewNotFound("transaction not found")
+	}
+	versions, err := s.repo.GetHistory(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, utils.NewNotFound("transaction not found")
+	}
+	return versions, nil
+}
+
+// newVersion records a change of a transaction owned by userID from before
+// to after; either is nil for created and deleted transactions. The request
+// ID is taken from ctx.
+func newVersion(ctx context.Context, userID, action string, before, after *model.Transaction) model.TransactionVersion {
+	tx := after
+	if tx == nil {
+		tx = before
+	}
+	return model.TransactionVersion{
+		TransactionID: tx.ID,
+		UserID:        tx.UserID,
+		ActorID:       userID,
+		Action:        action,
+		Changes:       fieldChanges(before, after),
+		RequestID:     requestid.FromContext(ctx),
+	}
+}
+
+// createdVersions records the creation of transactions.
+func createdVersions(ctx context.Context, userID string, txs ...*model.Transaction) []model.TransactionVersion {
+	versions := make([]model.TransactionVersion, len(txs))
+	for i, tx := range txs {
+		versions[i] = newVersion(ctx, userID, model.HistoryCreate, nil, tx)
+	}
+	return versions
+}
+
+// trashVersions records moving transactions to the trash or out of it as a
+// change of deleted_at.
+func trashVersions(ctx context.Context, userID, action string, txs []model.Transaction) []model.TransactionVersion {
+	now, _ := json.Marshal(time.Now())
+	versions := make([]model.TransactionVersion, len(txs))
+	for i, tx := range txs {
+		change := model.FieldChange{Before: json.RawMessage("null"), After: now}
+		if action == model.HistoryRestore {
+			deletedAt, _ := json.Marshal(tx.DeletedAt.Time)
+			change = model.FieldChange{Before: deletedAt, After: json.RawMessage("null")}
+		}
+		versions[i] = model.TransactionVersion{
+			TransactionID: tx.ID,
+			UserID:        tx.UserID,
+			ActorID:       userID,
+			Action:        action,
+			Changes:       model.FieldChanges{"deleted_at": change},
+			RequestID:     requestid.FromContext(ctx),
+		}
+	}
+	return versions
+}
+
+// fieldChanges lists the recorded fields that differ between before and
+// after. Empty values count as null.
+func fieldChanges(before, after *model.Transaction) model.FieldChanges {
+	b, a := historyValues(before), historyValues(after)
+	changes := model.FieldChanges{}
+	for _, name := range historyFields {
+		if !bytes.Equal(b[name], a[name]) {
+			changes[name] = model.FieldChange{Before: b[name], After: a[name]}
+		}
+	}
+	return changes
+}
+
+// historyValues encodes the recorded fields of tx as JSON. All fields of a
+// nil transaction are null.
+func historyValues(tx *model.Transaction) map[string]json.RawMessage {
+	values := make(map[string]json.RawMessage, len(historyFields))
+	for _, name := range historyFields {
+		values[name] = json.RawMessage("null")
+	}
+	if tx == nil {
+		return values
+	}
+	splits := make([]historySplit, len(tx.Splits))
+	for i, s := range tx.Splits {
+		splits[i] = historySplit{CategoryID: s.CategoryID, Amount: s.Amount, Note: s.Note}
+	}
+	fields := map[string]interface{}{
+		"amount":       tx.Amount,
+		"currency":     tx.Currency,
+		"type":         tx.Type,
+		"category_id":  tx.CategoryID,
+		"account_id":   tx.AccountID,
+		"transfer_id":  tx.TransferID,
+		"external_id":  tx.ExternalID,
+		"duplicate_of": tx.DuplicateOf,
+		"comment":      tx.Comment,
+		"created_at":   tx.CreatedAt,
+		"tags":         tx.TagNames,
+		"splits":       splits,
+	}
+	for name, v := range fields {
+		b, err := json.Marshal(v)
+		if err != nil || string(b) == `""` || string(b) == "[]" {
+			continue
+		}
+		values[name] = b
+	}
+	return values
+}
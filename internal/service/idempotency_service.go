@@ -0,0 +1,118 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/repository"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrIdempotencyKeyReused is returned when a key is sent again with a
+	// different request.
+	ErrIdempotencyKeyReused = errors.New("idempotency key was already used with a different request")
+	// ErrIdempotencyKeyInUse is returned while the first request with a key
+	// is still being processed.
+	ErrIdempotencyKeyInUse = errors.New("a request with this idempotency key is still being processed")
+)
+
+type IdempotencyService interface {
+	// Begin reserves key for the request identified by fingerprint. It
+	// returns the stored response when the key was used before with the same
+	// request, or nil when the request should be processed and then
+	// completed or aborted.
+	Begin(userID, key, fingerprint string) (*model.IdempotencyKey, error)
+	// Complete stores the response to return for retries with the key.
+	Complete(userID, key string, status int, contentType string, body []byte) error
+	// Abort releases a reserved key, so a retry is processed again.
+	Abort(userID, key string) error
+	// Start removes expired keys right away and then every interval until
+	// ctx is cancelled.
+	Start(ctx context.Context, interval time.Duration)
+}
+
+type idempotencyService struct {
+	repo repository.IdempotencyRepository
+	// ttl is how long a key and its response are kept.
+	ttl    time.Duration
+	logger *logrus.Logger
+}
+
+func NewIdempotencyService(r repository.IdempotencyRepository, ttl time.Duration, logger *logrus.Logger) IdempotencyService {
+	return &idempotencyService{r, ttl, logger}
+}
+
+func (s *idempotencyService) Begin(userID, key, fingerprint string) (*model.IdempotencyKey, error) {
+	for {
+		now := time.Now()
+		reserved, err := s.repo.Reserve(&model.IdempotencyKey{
+			UserID:      userID,
+			Key:         key,
+			Fingerprint: fingerprint,
+			ExpiresAt:   now.Add(s.ttl),
+		})
+		if err != nil || reserved {
+			return nil, err
+		}
+		stored, err := s.repo.Get(userID, key)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Released in the meantime, try again.
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if stored.ExpiresAt.Before(now) {
+			if _, err := s.repo.DeleteExpired(now); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		if stored.Fingerprint != fingerprint {
+			return nil, ErrIdempotencyKeyReused
+		}
+		if stored.StatusCode == 0 {
+			return nil, ErrIdempotencyKeyInUse
+		}
+		return stored, nil
+	}
+}
+
+func (s *idempotencyService) Complete(userID, key string, status int, contentType string, body []byte) error {
+	return s.repo.Complete(&model.IdempotencyKey{
+		UserID:      userID,
+		Key:         key,
+		StatusCode:  status,
+		ContentType: contentType,
+		Body:        body,
+	})
+}
+
+func (s *idempotencyService) Abort(userID, key string) error {
+	return s.repo.Delete(userID, key)
+}
+
+func (s *idempotencyService) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			removed, err := s.repo.DeleteExpired(time.Now())
+			if err != nil {
+				s.logger.WithError(err).Error("Failed to remove expired idempotency keys")
+			} else if removed > 0 {
+				s.logger.WithField("count", removed).Info("Expired idempotency keys removed")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,246 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"statistic_service/pkg/money"
+	"statistic_service/pkg/utils"
+)
+
+// MaxImportRows bounds the number of data rows in one import.
+const MaxImportRows = 10000
+
+// CSV import fields a column can be mapped to.
+const (
+	CSVDate     = "date"
+	CSVAmount   = "amount"
+	CSVType     = "type"
+	CSVCategory = "category"
+	CSVComment  = "comment"
+	CSVCurrency = "currency"
+	CSVAccount  = "account_id"
+)
+
+var csvFields = []string{CSVDate, CSVAmount, CSVType, CSVCategory, CSVComment, CSVCurrency, CSVAccount}
+
+// CSVOptions describes the layout of an uploaded CSV file.
+type CSVOptions struct {
+	// Mapping maps import fields to header names. Fields that are not mapped
+	// use the column whose header equals the field name, if any.
+	Mapping map[string]string
+	// Delimiter is detected from the header line when zero.
+	Delimiter rune
+	// DateFormat is one of the names in DateFormats, detected when empty.
+	DateFormat string
+}
+
+// DateFormats are the date layouts CSV import understands, in the order they
+// are tried when detecting the format of a file.
+var DateFormats = []struct {
+	Name   string
+	Layout string
+}{
+	{"RFC3339", time.RFC3339},
+	{"YYYY-MM-DDTHH:MM:SS", "2006-01-02T15:04:05"},
+	{"YYYY-MM-DD HH:MM:SS", "2006-01-02 15:04:05"},
+	{"YYYY-MM-DD HH:MM", "2006-01-02 15:04"},
+	{"YYYY-MM-DD", "2006-01-02"},
+	{"DD.MM.YYYY HH:MM", "02.01.2006 15:04"},
+	{"DD.MM.YYYY", "02.01.2006"},
+	{"DD/MM/YYYY", "02/01/2006"},
+	{"MM/DD/YYYY", "01/02/2006"},
+	{"YYYY/MM/DD", "2006/01/02"},
+	{"DD-MM-YYYY", "02-01-2006"},
+}
+
+// csvRow holds the raw values of one data row by import field.
+type csvRow struct {
+	line   int
+	values map[string]string
+}
+
+// readCSV reads the header and data rows of r and resolves the column mapping.
+func readCSV(r io.Reader, opts CSVOptions) ([]csvRow, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+	delimiter := opts.Delimiter
+	if delimiter == 0 {
+		delimiter = detectDelimiter(data)
+	}
+	cr := csv.NewReader(bytes.NewReader(data))
+	cr.Comma = delimiter
+	cr.FieldsPerRecord = -1
+	cr.TrimLeadingSpace = true
+
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, utils.NewValidation("file is empty")
+	}
+	if err != nil {
+		return nil, utils.NewValidation(fmt.Sprintf("invalid csv: %v", err))
+	}
+	columns, err := mapColumns(header, opts.Mapping)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []csvRow
+	for {
+		record, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, utils.NewValidation(fmt.Sprintf("invalid csv: %v", err))
+		}
+		line, _ := cr.FieldPos(0)
+		if isBlankRecord(record) {
+			continue
+		}
+		if len(rows) == MaxImportRows {
+			return nil, utils.NewValidation(fmt.Sprintf("file has more than %d rows", MaxImportRows))
+		}
+		row := csvRow{line: line, values: make(map[string]string, len(columns))}
+		for field, idx := range columns {
+			if idx < len(record) {
+				row.values[field] = strings.TrimSpace(record[idx])
+			}
+		}
+		rows = append(rows, row)
+	}
+	return rows, nil
+}
+
+// mapColumns returns the column index of every mapped import field. Date and
+// amount are required.
+func mapColumns(header []string, mapping map[string]string) (map[string]int, error) {
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		index[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+	for field := range mapping {
+		if !isCSVField(field) {
+			return nil, utils.NewValidation(fmt.Sprintf("unknown mapping field %q", field))
+		}
+	}
+	columns := make(map[string]int)
+	for _, field := range csvFields {
+		name, mapped := mapping[field]
+		if !mapped {
+			name = field
+		}
+		idx, ok := index[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			if mapped {
+				return nil, utils.NewValidation(fmt.Sprintf("column %q mapped to %s not found", name, field))
+			}
+			continue
+		}
+		columns[field] = idx
+	}
+	if _, ok := columns[CSVDate]; !ok {
+		return nil, utils.NewValidation("no column mapped to date")
+	}
+	if _, ok := columns[CSVAmount]; !ok {
+		return nil, utils.NewValidation("no column mapped to amount")
+	}
+	return columns, nil
+}
+
+func isCSVField(field string) bool {
+	for _, f := range csvFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// detectDelimiter picks the most frequent of the usual delimiters in the first line.
+func detectDelimiter(data []byte) rune {
+	line := data
+	if i := bytes.IndexByte(data, '\n'); i >= 0 {
+		line = data[:i]
+	}
+	best, bestCount := ',', 0
+	for _, d := range []rune{',', ';', '\t', '|'} {
+		if n := bytes.Count(line, []byte(string(d))); n > bestCount {
+			best, bestCount = d, n
+		}
+	}
+	return best
+}
+
+func isBlankRecord(record []string) bool {
+	for _, v := range record {
+		if strings.TrimSpace(v) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// dateLayout returns the layout of the named format, or detects the first
+// layout that parses every value. When none does, the layout parsing the most
+// values wins and the remaining rows are reported as errors.
+func dateLayout(name string, values []string) (string, error) {
+	if name != "" {
+		for _, f := range DateFormats {
+			if strings.EqualFold(f.Name, name) {
+				return f.Layout, nil
+			}
+		}
+		return "", utils.NewValidation(fmt.Sprintf("unknown date format %q", name))
+	}
+	best, bestCount := DateFormats[0].Layout, -1
+	for _, f := range DateFormats {
+		count := 0
+		for _, v := range values {
+			if _, err := time.Parse(f.Layout, v); err == nil {
+				count++
+			}
+		}
+		if count == len(values) {
+			return f.Layout, nil
+		}
+		if count > bestCount {
+			best, bestCount = f.Layout, count
+		}
+	}
+	return best, nil
+}
+
+// parseCSVAmount parses amounts such as "1,234.56", "1.234,56", "-12,5" or
+// "1 234". The last of '.' and ',' is the decimal separator when both occur;
+// a lone ',' is decimal when followed by one or two digits.
+func parseCSVAmount(s string) (money.Amount, error) {
+	s = strings.NewReplacer(" ", "", "\u00a0", "", "'", "").Replace(s)
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		s = "-" + s[1:len(s)-1]
+	}
+	dot, comma := strings.LastIndex(s, "."), strings.LastIndex(s, ",")
+	switch {
+	case dot >= 0 && comma >= 0:
+		if comma > dot {
+			s = strings.ReplaceAll(s, ".", "")
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	case comma >= 0:
+		if strings.Count(s, ",") == 1 && len(s)-comma-1 <= 2 {
+			s = strings.Replace(s, ",", ".", 1)
+		} else {
+			s = strings.ReplaceAll(s, ",", "")
+		}
+	}
+	return money.Parse(s)
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/repository"
+	"statistic_service/pkg/statement"
+	"statistic_service/pkg/utils"
+
+	"github.com/google/uuid"
+)
+
+// ImportRowError is a problem with one row of an imported file. Row is the
+// line number in a CSV file or the entry number in a bank statement.
+type ImportRowError struct {
+	Row   int    `json:"row"`
+	Error string `json:"error"`
+}
+
+// ImportReport is the result of an import. Nothing is stored when Errors is
+// not empty or on a dry run; a dry run lists the transactions it would create.
+// Skipped counts statement entries that were imported before or have a zero
+// amount, and Flagged the transactions marked as likely duplicates of
+// existing ones.
+type ImportReport struct {
+	DryRun       bool                `json:"dry_run"`
+	Rows         int                 `json:"rows"`
+	Imported     int                 `json:"imported"`
+	Skipped      int                 `json:"skipped"`
+	Flagged      int                 `json:"flagged"`
+	Errors       []ImportRowError    `json:"errors"`
+	Transactions []model.Transaction `json:"transactions,omitempty"`
+}
+
+type ImportService interface {
+	ImportCSV(ctx context.Context, userID string, r io.Reader, opts CSVOptions, onDuplicate DuplicatePolicy, dryRun bool) (*ImportReport, error)
+	ImportStatement(ctx context.Context, userID string, r io.Reader, format, accountID string, onDuplicate DuplicatePolicy, dryRun bool) (*ImportReport, error)
+}
+
+type importService struct {
+	txRepo       repository.TransactionRepository
+	txs          TransactionService
+	categoryRepo repository.CategoryRepository
+	ruleRepo     repository.RuleRepository
+}
+
+func NewImportService(r repository.TransactionRepository, txs TransactionService, c repository.CategoryRepository, rules repository.RuleRepository) ImportService {
+	return &importService{r, txs, c, rules}
+}
+
+// ImportCSV validates every row of a CSV file, applies the user's rules and,
+// unless dryRun is set or a row is invalid, stores all transactions in one DB transaction.
+func (s *importService) ImportCSV(ctx context.Context, userID string, r io.Reader, opts CSVOptions, onDuplicate DuplicatePolicy, dryRun bool) (*ImportReport, error) {
+	if err := validatePolicy(onDuplicate); err != nil {
+		return nil, err
+	}
+	rows, err := readCSV(r, opts)
+	if err != nil {
+		return nil, err
+	}
+	dates := make([]string, 0, len(rows))
+	for _, row := range rows {
+		if v := row.values[CSVDate]; v != "" {
+			dates = append(dates, v)
+		}
+	}
+	layout, err := dateLayout(opts.DateFormat, dates)
+	if err != nil {
+		return nil, err
+	}
+	categories, err := s.categoryNames(userID)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := loadRules(s.ruleRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ImportReport{DryRun: dryRun, Rows: len(rows), Errors: []ImportRowError{}}
+	txs := make([]*model.Transaction, 0, len(rows))
+	for _, row := range rows {
+		tx, err := s.csvTransaction(userID, row, layout, categories)
+		if err == nil {
+			if _, err = rules.applyChecked(tx); err == nil {
+				err = s.checkDuplicate(userID, tx, onDuplicate, report)
+			}
+		}
+		if err != nil {
+			var appErr *utils.AppError
+			if !errors.As(err, &appErr) {
+				return nil, err
+			}
+			report.Errors = append(report.Errors, ImportRowError{Row: row.line, Error: appErr.Message})
+			continue
+		}
+		txs = append(txs, tx)
+	}
+	return report, s.commit(ctx, userID, report, txs)
+}
+
+// ImportStatement imports the booked entries of an OFX/QFX, QIF or camt.053
+// statement, detecting the format when empty, into the given account, applying
+// the user's rules. Entries whose bank reference was already imported into the
+// account and entries with a zero amount are skipped.
+func (s *importService) ImportStatement(ctx context.Context, userID string, r io.Reader, format, accountID string, onDuplicate DuplicatePolicy, dryRun bool) (*ImportReport, error) {
+	if err := validatePolicy(onDuplicate); err != nil {
+		return nil, err
+	}
+	var account *string
+	if accountID != "" {
+		if _, err := uuid.Parse(accountID); err != nil {
+			return nil, utils.NewValidation("account not found")
+		}
+		account = &accountID
+	}
+	entries, err := statement.Parse(format, r)
+	if err != nil {
+		return nil, utils.NewValidation(err.Error())
+	}
+	if len(entries) > MaxImportRows {
+		return nil, utils.NewValidation(fmt.Sprintf("statement has more than %d entries", MaxImportRows))
+	}
+
+	ids := make([]string, len(entries))
+	for i, e := range entries {
+		ids[i] = e.ExternalID
+	}
+	existing, err := s.txRepo.ExistingExternalIDs(userID, account, ids)
+	if err != nil {
+		return nil, err
+	}
+	rules, err := loadRules(s.ruleRepo, userID)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool, len(entries))
+	for _, id := range existing {
+		seen[id] = true
+	}
+
+	report := &ImportReport{DryRun: dryRun, Rows: len(entries), Errors: []ImportRowError{}}
+	txs := make([]*model.Transaction, 0, len(entries))
+	for i, e := range entries {
+		// Zero amounts, e.g. balance lines, are no income or expense.
+		if seen[e.ExternalID] || e.Amount.IsZero() {
+			report.Skipped++
+			continue
+		}
+		seen[e.ExternalID] = true
+		tx := statementTransaction(userID, e, account)
+		err := s.txs.Validate(userID, tx)
+		if err == nil {
+			if _, err = rules.applyChecked(tx); err == nil {
+				err = s.checkDuplicate(userID, tx, onDuplicate, report)
+			}
+		}
+		if err != nil {
+			var appErr *utils.AppError
+			if !errors.As(err, &appErr) {
+				return nil, err
+			}
+			report.Errors = append(report.Errors, ImportRowError{Row: i + 1, Error: appErr.Message})
+			continue
+		}
+		txs = append(txs, tx)
+	}
+	return report, s.commit(ctx, userID, report, txs)
+}
+
+// statementTransaction maps a statement entry to a transaction: debits become
+// expenses, the booking date the creation time and the memo the comment.
+func statementTransaction(userID string, e statement.Entry, accountID *string) *model.Transaction {
+	txType, amount := "income", e.Amount
+	if amount.IsNegative() {
+		txType, amount = "expense", amount.Neg()
+	}
+	externalID := e.ExternalID
+	return &model.Transaction{
+		UserID:     userID,
+		Amount:     amount,
+		Currency:   e.Currency,
+		Type:       txType,
+		AccountID:  accountID,
+		ExternalID: &externalID,
+		Comment:    e.Memo,
+		CreatedAt:  e.Date,
+	}
+}
+
+// checkDuplicate compares a valid imported transaction with the existing ones.
+// Rejected duplicates become row errors and flagged ones are counted.
+// Duplicates among the imported rows themselves are not detected.
+func (s *importService) checkDuplicate(userID string, tx *model.Transaction, onDuplicate DuplicatePolicy, report *ImportReport) error {
+	if onDuplicate == DuplicateIgnore {
+		return nil
+	}
+	duplicates, err := s.txs.FindDuplicates(userID, tx)
+	if err != nil || len(duplicates) == 0 {
+		return err
+	}
+	if onDuplicate == DuplicateReject {
+		return utils.NewValidation(fmt.Sprintf("possible duplicate of transaction %s", duplicates[0].ID))
+	}
+	tx.DuplicateOf = &duplicates[0].ID
+	report.Flagged++
+	return nil
+}
+
+// commit stores the transactions unless the report has errors or is a dry run.
+func (s *importService) commit(ctx context.Context, userID string, report *ImportReport, txs []*model.Transaction) error {
+	if len(report.Errors) > 0 {
+		return nil
+	}
+	if report.DryRun {
+		report.Transactions = make([]model.Transaction, len(txs))
+		for i, tx := range txs {
+			report.Transactions[i] = *tx
+		}
+		return nil
+	}
+	if len(txs) > 0 {
+		err := s.txRepo.WithTx(func(r repository.TransactionRepository) error {
+			if err := r.CreateBatch(txs); err != nil {
+				return err
+			}
+			return r.AddVersions(createdVersions(ctx, userID, txs...))
+		})
+		if err != nil {
+			return err
+		}
+	}
+	report.Imported = len(txs)
+	return nil
+}
+
+func (s *importService) csvTransaction(userID string, row csvRow, layout string, categories map[string][]model.Category) (*model.Transaction, error) {
+	v := row.values
+	if v[CSVDate] == "" {
+		return nil, utils.NewValidation("date is required")
+	}
+	createdAt, err := time.Parse(layout, v[CSVDate])
+	if err != nil {
+		return nil, utils.NewValidation(fmt.Sprintf("invalid date %q", v[CSVDate]))
+	}
+	amount, err := parseCSVAmount(v[CSVAmount])
+	if err != nil {
+		return nil, utils.NewValidation(fmt.Sprintf("invalid amount %q", v[CSVAmount]))
+	}
+
+	// Without a type column the sign of the amount tells expenses from income.
+	txType := strings.ToLower(v[CSVType])
+	if txType == "" {
+		txType = "income"
+		if amount.IsNegative() {
+			txType = "expense"
+		}
+	}
+	if amount.IsNegative() {
+		amount = amount.Neg()
+	}
+
+	tx := &model.Transaction{
+		UserID:    userID,
+		Amount:    amount,
+		Currency:  v[CSVCurrency],
+		Type:      txType,
+		Comment:   v[CSVComment],
+		CreatedAt: createdAt,
+	}
+	if name := v[CSVCategory]; name != "" {
+		id, err := lookupCategory(categories, name, txType)
+		if err != nil {
+			return nil, err
+		}
+		tx.CategoryID = &id
+	}
+	if account := v[CSVAccount]; account != "" {
+		tx.AccountID = &account
+	}
+	if err := s.txs.Validate(userID, tx); err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// categoryNames indexes the user's categories by lower-case name and by full
+// path such as "transport > fuel".
+func (s *importService) categoryNames(userID string) (map[string][]model.Category, error) {
+	list, err := s.categoryRepo.GetByUser(userID, "")
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]model.Category, len(list))
+	for _, c := range list {
+		byID[c.ID] = c
+	}
+	names := make(map[string][]model.Category, 2*len(list))
+	for _, c := range list {
+		path := c.Name
+		for p, depth := c.ParentID, 0; p != nil && depth < model.MaxCategoryDepth; depth++ {
+			parent, ok := byID[*p]
+			if !ok {
+				break
+			}
+			path = parent.Name + " > " + path
+			p = parent.ParentID
+		}
+		names[strings.ToLower(c.Name)] = append(names[strings.ToLower(c.Name)], c)
+		if path != c.Name {
+			names[strings.ToLower(path)] = append(names[strings.ToLower(path)], c)
+		}
+	}
+	return names, nil
+}
+
+// lookupCategory resolves a category name or path of the given type.
+func lookupCategory(categories map[string][]model.Category, name, txType string) (string, error) {
+	key := strings.ToLower(strings.Join(strings.Fields(strings.ReplaceAll(name, ">", " > ")), " "))
+	var found []string
+	for _, c := range categories[key] {
+		if c.Type == txType {
+			found = append(found, c.ID)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return "", utils.NewValidation(fmt.Sprintf("category %q not found", name))
+	case 1:
+		return found[0], nil
+	}
+	return "", utils.NewValidation(fmt.Sprintf("category %q is ambiguous, use the full path", name))
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/repository"
+	"statistic_service/pkg/jwt"
+	"statistic_service/pkg/mail"
+	"statistic_service/pkg/utils"
+
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+type PasswordService interface {
+	// Forgot emails a reset token to the user with the email, at most once
+	// per reset interval. Unknown emails and skipped requests are not
+	// reported and the email is sent in the background, so neither the
+	// response nor its timing reveals who is registered.
+	Forgot(ctx context.Context, email string) error
+	// Reset sets a new password with a reset token and ends all sessions of
+	// the user.
+	Reset(token, password string) error
+}
+
+type passwordService struct {
+	users  repository.UserRepository
+	resets repository.PasswordResetRepository
+	mailer mail.Mailer
+	secret string
+	// ttl is how long a reset token can be used.
+	ttl time.Duration
+	// interval is the least time between two emails to a user.
+	interval time.Duration
+	// resetURL is the page that sets the new password; the token is added
+	// as the token query parameter. The email only has the token when empty.
+	resetURL string
+	logger   *logrus.Logger
+}
+
+func NewPasswordService(users repository.UserRepository, resets repository.PasswordResetRepository, mailer mail.Mailer, secret string, ttl, interval time.Duration, resetURL string, logger *logrus.Logger) PasswordService {
+	return &passwordService{users, resets, mailer, secret, ttl, interval, resetURL, logger}
+}
+
+func (s *passwordService) Forgot(ctx context.Context, email string) error {
+	user, err := s.users.GetByEmail(email)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.Info("Password reset requested for unknown email")
+		return nil
+	}
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get user by email")
+		return err
+	}
+	latest, err := s.resets.GetLatest(user.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.WithError(err).Error("Failed to get reset token")
+		return err
+	}
+	if err == nil && time.Since(latest.CreatedAt) < s.interval {
+		s.logger.WithField("userID", user.ID).Warn("Password reset requested again too soon")
+		return nil
+	}
+	// Storing and sending the token would make the response slower than for
+	// an unknown email.
+	go s.sendReset(context.WithoutCancel(ctx), user)
+	return nil
+}
+
+// sendReset stores a new reset token for the user and emails it. Failures
+// are only logged, the response has already been sent.
+func (s *passwordService) sendReset(ctx context.Context, user *model.User) {
+	token, err := jwt.GenerateOneTimeToken()
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to generate reset token")
+		return
+	}
+	reset := &model.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: jwt.HashToken(token, s.secret),
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+	if err := s.resets.Create(reset); err != nil {
+		s.logger.WithError(err).Error("Failed to save reset token")
+		return
+	}
+
+	msg := mail.Message{To: user.Email, Subject: "Reset your password", Body: s.resetBody(token)}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		s.logger.WithError(err).WithField("userID", user.ID).Error("Failed to send password reset email")
+		return
+	}
+	s.logger.WithField("userID", user.ID).Info("Password reset email sent")
+}
+
+func (s *passwordService) Reset(token, password string) error {
+	if !isPasswordComplex(password) {
+		return utils.NewValidation("password must contain at least one uppercase letter, one lowercase letter, one number, and one special character")
+	}
+	invalid := utils.NewValidation("invalid or expired reset token")
+
+	tokenHash := jwt.HashToken(token, s.secret)
+	reset, err := s.resets.GetByHash(tokenHash)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return invalid
+	}
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get reset token")
+		return err
+	}
+	now := time.Now()
+	if subtle.ConstantTimeCompare([]byte(reset.TokenHash), []byte(tokenHash)) != 1 || reset.UsedAt != nil || now.After(reset.ExpiresAt) {
+		return invalid
+	}
+
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to hash password")
+		return err
+	}
+	used, err := s.resets.Use(reset, string(hashed), now, now.Add(jwt.AccessTokenTTL))
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to reset password")
+		return err
+	}
+	if !used {
+		return invalid
+	}
+	s.logger.WithField("userID", reset.UserID).Info("Password reset")
+	return nil
+}
+
+// resetBody is the text of the password reset email.
+func (s *passwordService) resetBody(token string) string {
+	link := ""
+	if u, err := url.Parse(s.resetURL); err == nil && s.resetURL != "" {
+		q := u.Query()
+		q.Set("token", token)
+		u.RawQuery = q.Encode()
+		link = fmt.Sprintf("Open this link to choose a new password:\n%s\n\n", u)
+	}
+	return fmt.Sprintf("Someone asked to reset the password of your account.\n\n%sReset token: %s\n\n"+
+		"The token can be used once within %s. If you did not ask for it, ignore this email; your password stays the same.\n",
+		link, token, s.ttl)
+}
@@ -0,0 +1,259 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/repository"
+	"statistic_service/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// dueBatchSize is how many due templates the scheduler loads at once.
+const dueBatchSize = 100
+
+// maxBackfill is how far in the past a new template may start. Every missed
+// occurrence is created on the next run, so an older start would flood the
+// user with transactions.
+const maxBackfill = 365 * 24 * time.Hour
+
+type RecurringService interface {
+	Create(userID string, input *model.RecurringTransaction) error
+	List(userID string) ([]model.RecurringTransaction, error)
+	Update(id, userID string, input *model.RecurringTransaction) (*model.RecurringTransaction, error)
+	Delete(id, userID string) error
+	// RunDue creates the transactions of every occurrence due at or before now
+	// and returns how many were created.
+	RunDue(now time.Time) (int, error)
+	// Start runs RunDue right away, catching up on occurrences missed while the
+	// service was down, and then every interval until ctx is cancelled.
+	Start(ctx context.Context, interval time.Duration)
+}
+
+type recurringService struct {
+	repo   repository.RecurringRepository
+	txRepo repository.TransactionRepository
+	txs    TransactionService
+	logger *logrus.Logger
+}
+
+func NewRecurringService(r repository.RecurringRepository, txRepo repository.TransactionRepository, txs TransactionService, logger *logrus.Logger) RecurringService {
+	return &recurringService{r, txRepo, txs, logger}
+}
+
+func (s *recurringService) Create(userID string, input *model.RecurringTransaction) error {
+	if input.Interval == 0 {
+		input.Interval = 1
+	}
+	input.StartAt = input.StartAt.UTC().Truncate(time.Second)
+	if err := validateSchedule(input); err != nil {
+		return err
+	}
+	if input.StartAt.Before(time.Now().Add(-maxBackfill)) {
+		return utils.NewValidation("start_at must not be more than a year ago")
+	}
+	if err := s.validateTemplate(userID, input); err != nil {
+		return err
+	}
+	input.ID = ""
+	input.UserID = userID
+	input.Occurrences = 0
+	input.NextAt = nextOccurrence(input)
+	return s.repo.Create(input)
+}
+
+func (s *recurringService) List(userID string) ([]model.RecurringTransaction, error) {
+	return s.repo.GetByUser(userID)
+}
+
+// Update changes what future occurrences create and when the schedule ends.
+// The frequency and start of the schedule are fixed.
+func (s *recurringService) Update(id, userID string, input *model.RecurringTransaction) (*model.RecurringTransaction, error) {
+	rec, err := s.getOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	rec.Amount = input.Amount
+	rec.Currency = input.Currency
+	rec.Type = input.Type
+	rec.CategoryID = input.CategoryID
+	rec.AccountID = input.AccountID
+	rec.Comment = input.Comment
+	rec.EndAt = input.EndAt
+	rec.Count = input.Count
+	if err := validateSchedule(rec); err != nil {
+		return nil, err
+	}
+	if err := s.validateTemplate(userID, rec); err != nil {
+		return nil, err
+	}
+	rec.NextAt = nextOccurrence(rec)
+	if err := s.repo.Update(rec); err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+// Delete removes a template. Transactions it already created are kept.
+func (s *recurringService) Delete(id, userID string) error {
+	if _, err := s.getOwned(id, userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+func (s *recurringService) RunDue(now time.Time) (int, error) {
+	created := 0
+	for {
+		due, err := s.repo.Due(now, dueBatchSize)
+		if err != nil {
+			return created, err
+		}
+		progressed := false
+		for i := range due {
+			n, err := s.materialize(&due[i], now)
+			created += n
+			if err != nil {
+				s.logger.WithError(err).WithField("recurringID", due[i].ID).Warn("Failed to materialize recurring transaction")
+				continue
+			}
+			progressed = true
+		}
+		// Templates that failed stay due, so stop once a batch makes no progress.
+		if len(due) < dueBatchSize || !progressed {
+			return created, nil
+		}
+	}
+}
+
+func (s *recurringService) Start(ctx context.Context, interval time.Duration) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			created, err := s.RunDue(time.Now())
+			if err != nil {
+				s.logger.WithError(err).Error("Failed to run recurring transactions")
+			} else if created > 0 {
+				s.logger.WithField("count", created).Info("Recurring transactions created")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
+
+// materialize creates the transactions of all occurrences of rec due at or
+// before now, saving progress after each one. An occurrence that already has
+// a transaction, e.g. after a crash before progress was saved, is skipped.
+// It stops when the template was changed meanwhile; the next run continues
+// with the stored template.
+func (s *recurringService) materialize(rec *model.RecurringTransaction, now time.Time) (int, error) {
+	created := 0
+	for rec.NextAt != nil && !rec.NextAt.After(now) {
+		at := *rec.NextAt
+		done, err := s.txRepo.HasOccurrence(rec.ID, at)
+		if err != nil {
+			return created, err
+		}
+		if !done {
+			if err := s.txs.Create(context.Background(), rec.UserID, occurrence(rec, at), DuplicateIgnore); err != nil {
+				return created, err
+			}
+			created++
+		}
+		rec.Occurrences++
+		rec.NextAt = nextOccurrence(rec)
+		advanced, err := s.repo.Advance(rec, at)
+		if err != nil || !advanced {
+			return created, err
+		}
+	}
+	return created, nil
+}
+
+// validateTemplate checks the template with the same rules as a new
+// transaction and fills in the default currency.
+func (s *recurringService) validateTemplate(userID string, rec *model.RecurringTransaction) error {
+	tx := occurrence(rec, rec.StartAt)
+	if err := s.txs.Validate(userID, tx); err != nil {
+		return err
+	}
+	rec.Currency = tx.Currency
+	rec.CategoryID = tx.CategoryID
+	rec.AccountID = tx.AccountID
+	return nil
+}
+
+// getOwned loads a template and hides templates of other users behind not found.
+func (s *recurringService) getOwned(id, userID string) (*model.RecurringTransaction, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, utils.NewNotFound("recurring transaction not found")
+	}
+	rec, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && rec.UserID != userID) {
+		return nil, utils.NewNotFound("recurring transaction not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rec, nil
+}
+
+func validateSchedule(rec *model.RecurringTransaction) error {
+	if rec.StartAt.IsZero() {
+		return utils.NewValidation("start_at is required")
+	}
+	if err := rec.Rule().Validate(); err != nil {
+		return utils.NewValidation(err.Error())
+	}
+	if rec.Count < 0 {
+		return utils.NewValidation("count must not be negative")
+	}
+	if rec.EndAt != nil && rec.EndAt.Before(rec.StartAt) {
+		return utils.NewValidation("end_at must not be before start_at")
+	}
+	return nil
+}
+
+// nextOccurrence returns the time of the occurrence after the ones already
+// created, or nil when the count or end time has been reached.
+func nextOccurrence(rec *model.RecurringTransaction) *time.Time {
+	if rec.Count > 0 && rec.Occurrences >= rec.Count {
+		return nil
+	}
+	at := rec.Rule().At(rec.Occurrences)
+	if rec.EndAt != nil && at.After(*rec.EndAt) {
+		return nil
+	}
+	return &at
+}
+
+// occurrence builds the transaction a template creates at the given time.
+func occurrence(rec *model.RecurringTransaction, at time.Time) *model.Transaction {
+	return &model.Transaction{
+		Amount:      rec.Amount,
+		Currency:    rec.Currency,
+		Type:        rec.Type,
+		CategoryID:  rec.CategoryID,
+		AccountID:   rec.AccountID,
+		RecurringID: nilIfEmpty(rec.ID),
+		Comment:     rec.Comment,
+		CreatedAt:   at,
+	}
+}
+
+func nilIfEmpty(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
@@ -0,0 +1,445 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/repository"
+	"statistic_service/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	// MaxPreviewChanges bounds the changes listed by a rule preview.
+	MaxPreviewChanges = 100
+	// maxCommentPattern bounds the length of a rule's regular expression.
+	maxCommentPattern = 500
+)
+
+// RuleChange is what the rules would change on one transaction. Fields that
+// stay the same are empty.
+type RuleChange struct {
+	TransactionID string   `json:"transaction_id"`
+	CategoryID    *string  `json:"category_id,omitempty"`
+	OldComment    *string  `json:"old_comment,omitempty"`
+	Comment       *string  `json:"comment,omitempty"`
+	AddTags       []string `json:"add_tags,omitempty"`
+}
+
+// RulePreview lists the existing transactions a rule would change. Matched
+// counts all of them, Changes holds at most MaxPreviewChanges.
+type RulePreview struct {
+	Matched int          `json:"matched"`
+	Changes []RuleChange `json:"changes"`
+}
+
+type RuleService interface {
+	Create(userID string, input *model.Rule) error
+	List(userID string) ([]model.Rule, error)
+	Update(id, userID string, input *model.Rule) (*model.Rule, error)
+	Delete(id, userID string) error
+	Reorder(userID string, ids []string) error
+	Preview(userID string, input *model.Rule, f repository.TransactionFilter) (*RulePreview, error)
+	Apply(ctx context.Context, userID string, f repository.TransactionFilter) (int, error)
+}
+
+type ruleService struct {
+	repo         repository.RuleRepository
+	categoryRepo repository.CategoryRepository
+	accountRepo  repository.AccountRepository
+	tagRepo      repository.TagRepository
+	txRepo       repository.TransactionRepository
+	txs          TransactionService
+}
+
+func NewRuleService(r repository.RuleRepository, c repository.CategoryRepository, a repository.AccountRepository, tags repository.TagRepository, txRepo repository.TransactionRepository, txs TransactionService) RuleService {
+	return &ruleService{r, c, a, tags, txRepo, txs}
+}
+
+// Create validates a rule and appends it after the user's other rules.
+func (s *ruleService) Create(userID string, input *model.Rule) error {
+	if err := s.validate(userID, input); err != nil {
+		return err
+	}
+	tags, err := s.tagRepo.Ensure(userID, input.TagNames)
+	if err != nil {
+		return err
+	}
+	input.Tags = tags
+	rules, err := s.repo.GetByUser(userID)
+	if err != nil {
+		return err
+	}
+	input.ID = ""
+	input.UserID = userID
+	input.Position = 0
+	if len(rules) > 0 {
+		input.Position = rules[len(rules)-1].Position + 1
+	}
+	return s.repo.Create(input)
+}
+
+func (s *ruleService) List(userID string) ([]model.Rule, error) {
+	return s.repo.GetByUser(userID)
+}
+
+// Update replaces the conditions and actions of a rule, keeping its position.
+func (s *ruleService) Update(id, userID string, input *model.Rule) (*model.Rule, error) {
+	rule, err := s.getOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.validate(userID, input); err != nil {
+		return nil, err
+	}
+	if input.Tags, err = s.tagRepo.Ensure(userID, input.TagNames); err != nil {
+		return nil, err
+	}
+	input.ID = rule.ID
+	input.UserID = rule.UserID
+	input.Position = rule.Position
+	input.CreatedAt = rule.CreatedAt
+	if err := s.repo.Update(input); err != nil {
+		return nil, err
+	}
+	return input, nil
+}
+
+func (s *ruleService) Delete(id, userID string) error {
+	if _, err := s.getOwned(id, userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// Reorder sets the order rules are applied in. ids must list every rule of
+// the user exactly once.
+func (s *ruleService) Reorder(userID string, ids []string) error {
+	rules, err := s.repo.GetByUser(userID)
+	if err != nil {
+		return err
+	}
+	owned := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		owned[r.ID] = true
+	}
+	if len(ids) != len(rules) {
+		return utils.NewValidation("ids must list every rule exactly once")
+	}
+	for _, id := range ids {
+		if !owned[id] {
+			return utils.NewValidation("ids must list every rule exactly once")
+		}
+		delete(owned, id)
+	}
+	return s.repo.Reorder(ids)
+}
+
+// Preview runs an unsaved rule against the user's transactions matching f
+// without changing them. Tags that do not exist yet are not created.
+func (s *ruleService) Preview(userID string, input *model.Rule, f repository.TransactionFilter) (*RulePreview, error) {
+	if err := s.validate(userID, input); err != nil {
+		return nil, err
+	}
+	input.Tags = make([]model.Tag, len(input.TagNames))
+	for i, name := range input.TagNames {
+		input.Tags[i] = model.Tag{Name: name}
+	}
+	rules := compileRules([]model.Rule{*input})
+	preview := &RulePreview{Changes: []RuleChange{}}
+	err := s.txs.Each(userID, f, func(tx *model.Transaction) error {
+		before := *tx
+		if !rules.apply(tx) {
+			return nil
+		}
+		preview.Matched++
+		if len(preview.Changes) < MaxPreviewChanges {
+			preview.Changes = append(preview.Changes, ruleChange(&before, tx))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return preview, nil
+}
+
+// Apply runs the user's rules against the existing transactions matching f
+// and stores the changed fields in one DB transaction, recording the changes
+// in their history. It returns how many transactions were changed.
+func (s *ruleService) Apply(ctx context.Context, userID string, f repository.TransactionFilter) (int, error) {
+	rules, err := loadRules(s.repo, userID)
+	if err != nil {
+		return 0, err
+	}
+	var changed []repository.TransactionUpdate
+	var versions []model.TransactionVersion
+	err = s.txs.Each(userID, f, func(tx *model.Transaction) error {
+		before := *tx
+		ok, err := rules.applyChecked(tx)
+		if err != nil || !ok {
+			return err
+		}
+		c := *tx
+		u := repository.TransactionUpdate{Transaction: &c, Tags: c.Tags[len(before.Tags):]}
+		if before.CategoryID == nil && c.CategoryID != nil {
+			u.Columns = append(u.Columns, "category_id")
+		}
+		if before.Comment != c.Comment {
+			u.Columns = append(u.Columns, "comment")
+		}
+		changed = append(changed, u)
+		versions = append(versions, newVersion(ctx, userID, model.HistoryUpdate, &before, &c))
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(changed) == 0 {
+		return 0, nil
+	}
+	err = s.txRepo.WithTx(func(r repository.TransactionRepository) error {
+		if err := r.UpdateBatch(changed); err != nil {
+			return err
+		}
+		return r.AddVersions(versions)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(changed), nil
+}
+
+// validate checks that a rule has a condition, an action and valid values.
+// The referenced category is loaded into the rule and tag names are normalized.
+func (s *ruleService) validate(userID string, input *model.Rule) error {
+	input.Name = strings.TrimSpace(input.Name)
+	input.CommentContains = strings.TrimSpace(input.CommentContains)
+	if input.Name == "" {
+		return utils.NewValidation("name is required")
+	}
+	if input.Type != "" && !isValidTxType(input.Type) {
+		return utils.NewValidation("transaction type must be income or expense")
+	}
+	if input.AccountID != nil && *input.AccountID == "" {
+		input.AccountID = nil
+	}
+	if input.CategoryID != nil && *input.CategoryID == "" {
+		input.CategoryID = nil
+	}
+	if input.CommentContains == "" && input.CommentPattern == "" && input.MinAmount == nil &&
+		input.MaxAmount == nil && input.Type == "" && input.AccountID == nil {
+		return utils.NewValidation("rule needs at least one condition")
+	}
+	if input.CategoryID == nil && input.SetComment == nil && len(input.TagNames) == 0 {
+		return utils.NewValidation("rule needs category_id, set_comment or add_tags")
+	}
+	names, err := normalizeTags(input.TagNames)
+	if err != nil {
+		return err
+	}
+	if input.CommentPattern != "" {
+		if len(input.CommentPattern) > maxCommentPattern {
+			return utils.NewValidation(fmt.Sprintf("comment_pattern must be at most %d characters long", maxCommentPattern))
+		}
+		if _, err := regexp.Compile(input.CommentPattern); err != nil {
+			return utils.NewValidation("invalid comment_pattern: " + err.Error())
+		}
+	}
+	if (input.MinAmount != nil && input.MinAmount.IsNegative()) || (input.MaxAmount != nil && input.MaxAmount.IsNegative()) {
+		return utils.NewValidation("amounts must not be negative")
+	}
+	if input.MinAmount != nil && input.MaxAmount != nil && *input.MinAmount > *input.MaxAmount {
+		return utils.NewValidation("min_amount must not be greater than max_amount")
+	}
+	if input.AccountID != nil {
+		if err := s.checkAccount(userID, *input.AccountID); err != nil {
+			return err
+		}
+	}
+	input.Category = nil
+	if input.CategoryID != nil {
+		category, err := s.getCategory(userID, *input.CategoryID)
+		if err != nil {
+			return err
+		}
+		if input.Type != "" && category.Type != input.Type {
+			return utils.NewValidation("category type does not match transaction type")
+		}
+		input.Category = category
+	}
+	input.TagNames = names
+	return nil
+}
+
+func (s *ruleService) checkAccount(userID, id string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return utils.NewValidation("account not found")
+	}
+	account, err := s.accountRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && account.UserID != userID) {
+		return utils.NewValidation("account not found")
+	}
+	return err
+}
+
+func (s *ruleService) getCategory(userID, id string) (*model.Category, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, utils.NewValidation("category not found")
+	}
+	category, err := s.categoryRepo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && category.UserID != userID) {
+		return nil, utils.NewValidation("category not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return category, nil
+}
+
+// getOwned loads a rule and hides rules of other users behind not found.
+func (s *ruleService) getOwned(id, userID string) (*model.Rule, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, utils.NewNotFound("rule not found")
+	}
+	rule, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && rule.UserID != userID) {
+		return nil, utils.NewNotFound("rule not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func ruleChange(before, after *model.Transaction) RuleChange {
+	change := RuleChange{TransactionID: after.ID}
+	if before.CategoryID == nil && after.CategoryID != nil {
+		change.CategoryID = after.CategoryID
+	}
+	if before.Comment != after.Comment {
+		change.OldComment = &before.Comment
+		change.Comment = &after.Comment
+	}
+	if len(after.TagNames) > len(before.TagNames) {
+		change.AddTags = after.TagNames[len(before.TagNames):]
+	}
+	return change
+}
+
+// ruleSet is a user's rules in the order they are applied.
+type ruleSet []compiledRule
+
+type compiledRule struct {
+	model.Rule
+	contains string
+	pattern  *regexp.Regexp
+}
+
+// loadRules loads and compiles the rules of a user.
+func loadRules(repo repository.RuleRepository, userID string) (ruleSet, error) {
+	rules, err := repo.GetByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return compileRules(rules), nil
+}
+
+// compileRules prepares rules for matching. Rules with a pattern that does
+// not compile are left out; patterns are checked when a rule is saved.
+func compileRules(rules []model.Rule) ruleSet {
+	set := make(ruleSet, 0, len(rules))
+	for _, r := range rules {
+		c := compiledRule{Rule: r, contains: strings.ToLower(r.CommentContains)}
+		if r.CommentPattern != "" {
+			p, err := regexp.Compile(r.CommentPattern)
+			if err != nil {
+				continue
+			}
+			c.pattern = p
+		}
+		set = append(set, c)
+	}
+	return set
+}
+
+// apply runs the rules against a transaction and reports whether it changed.
+// Conditions see the transaction as it was before any rule ran. The first
+// matching rule with an action decides each field: a category is only
+// assigned to a transaction without one and of the category's type, and a
+// comment is only rewritten once. Tags of all matching rules are added.
+// Transfer legs are left alone.
+func (rs ruleSet) apply(tx *model.Transaction) bool {
+	if tx.TransferID != nil {
+		return false
+	}
+	original := *tx
+	// Split transactions keep their categories on the split lines.
+	categorySet, commentSet := tx.CategoryID != nil || len(tx.Splits) > 0, false
+	for i := range rs {
+		r := &rs[i]
+		if !r.matches(&original) {
+			continue
+		}
+		if !categorySet && r.CategoryID != nil && r.Category != nil && r.Category.Type == tx.Type {
+			id := *r.CategoryID
+			tx.CategoryID = &id
+			categorySet = true
+		}
+		if !commentSet && r.SetComment != nil {
+			if r.pattern != nil {
+				tx.Comment = r.pattern.ReplaceAllString(original.Comment, *r.SetComment)
+			} else {
+				tx.Comment = *r.SetComment
+			}
+			commentSet = true
+		}
+		for _, tag := range r.Tags {
+			addTag(tx, tag)
+		}
+	}
+	return (original.CategoryID == nil) != (tx.CategoryID == nil) || original.Comment != tx.Comment ||
+		len(original.TagNames) != len(tx.TagNames)
+}
+
+// applyChecked runs apply and checks that the rules kept the transaction
+// within the limits on tags and comment length.
+func (rs ruleSet) applyChecked(tx *model.Transaction) (bool, error) {
+	changed := rs.apply(tx)
+	if len(tx.TagNames) > MaxTagsPerTransaction {
+		return false, utils.NewValidation(fmt.Sprintf("rules add tags beyond the limit of %d", MaxTagsPerTransaction))
+	}
+	if utf8.RuneCountInString(tx.Comment) > MaxCommentLength {
+		return false, utils.NewValidation(fmt.Sprintf("rules make the comment longer than %d characters", MaxCommentLength))
+	}
+	return changed, nil
+}
+
+func (r *compiledRule) matches(tx *model.Transaction) bool {
+	if r.Type != "" && tx.Type != r.Type {
+		return false
+	}
+	if r.AccountID != nil && (tx.AccountID == nil || *tx.AccountID != *r.AccountID) {
+		return false
+	}
+	if r.MinAmount != nil && tx.Amount < *r.MinAmount {
+		return false
+	}
+	if r.MaxAmount != nil && tx.Amount > *r.MaxAmount {
+		return false
+	}
+	if r.contains != "" && !strings.Contains(strings.ToLower(tx.Comment), r.contains) {
+		return false
+	}
+	if r.pattern != nil && !r.pattern.MatchString(tx.Comment) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,165 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/repository"
+	"statistic_service/pkg/utils"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// MaxTagsPerTransaction bounds the number of tags on one transaction or rule.
+const MaxTagsPerTransaction = 20
+
+type TagService interface {
+	Create(userID string, input *model.Tag) error
+	List(userID string) ([]model.Tag, error)
+	Rename(id, userID, name string) (*model.Tag, error)
+	Delete(id, userID string) error
+}
+
+type tagService struct {
+	repo repository.TagRepository
+}
+
+func NewTagService(r repository.TagRepository) TagService {
+	return &tagService{r}
+}
+
+func (s *tagService) Create(userID string, input *model.Tag) error {
+	name, err := normalizeTag(input.Name)
+	if err != nil {
+		return err
+	}
+	if err := s.ensureNameFree(userID, name, ""); err != nil {
+		return err
+	}
+	input.ID = ""
+	input.UserID = userID
+	input.Name = name
+	return tagNameTaken(s.repo.Create(input))
+}
+
+func (s *tagService) List(userID string) ([]model.Tag, error) {
+	return s.repo.GetByUser(userID)
+}
+
+// Rename changes the name of a tag on all its transactions and rules.
+func (s *tagService) Rename(id, userID, name string) (*model.Tag, error) {
+	tag, err := s.getOwned(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if name, err = normalizeTag(name); err != nil {
+		return nil, err
+	}
+	if err := s.ensureNameFree(userID, name, tag.ID); err != nil {
+		return nil, err
+	}
+	tag.Name = name
+	if err := s.repo.Update(tag); err != nil {
+		return nil, tagNameTaken(err)
+	}
+	return tag, nil
+}
+
+// Delete removes a tag from all transactions and rules.
+func (s *tagService) Delete(id, userID string) error {
+	if _, err := s.getOwned(id, userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// getOwned loads a tag and hides tags of other users behind not found.
+func (s *tagService) getOwned(id, userID string) (*model.Tag, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, utils.NewNotFound("tag not found")
+	}
+	tag, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && tag.UserID != userID) {
+		return nil, utils.NewNotFound("tag not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tag, nil
+}
+
+func (s *tagService) ensureNameFree(userID, name, exceptID string) error {
+	existing, err := s.repo.GetByName(userID, name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if existing.ID != exceptID {
+		return utils.NewConflict("tag with this name already exists")
+	}
+	return nil
+}
+
+// tagNameTaken reports a name taken by a concurrent request after
+// ensureNameFree passed with the same conflict.
+func tagNameTaken(err error) error {
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return utils.NewConflict("tag with this name already exists")
+	}
+	return err
+}
+
+// normalizeTag trims and lower-cases a tag name and checks its length.
+// Commas are not allowed as they separate tags in query parameters.
+func normalizeTag(name string) (string, error) {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if name == "" {
+		return "", utils.NewValidation("tag name must not be empty")
+	}
+	if utf8.RuneCountInString(name) > model.MaxTagLength {
+		return "", utils.NewValidation(fmt.Sprintf("tag name must be at most %d characters long", model.MaxTagLength))
+	}
+	if strings.Contains(name, ",") {
+		return "", utils.NewValidation("tag name must not contain commas")
+	}
+	return name, nil
+}
+
+// normalizeTags normalizes tag names and drops repeated ones.
+func normalizeTags(names []string) ([]string, error) {
+	if len(names) > MaxTagsPerTransaction {
+		return nil, utils.NewValidation(fmt.Sprintf("at most %d tags are allowed", MaxTagsPerTransaction))
+	}
+	seen := make(map[string]bool, len(names))
+	list := make([]string, 0, len(names))
+	for _, n := range names {
+		name, err := normalizeTag(n)
+		if err != nil {
+			return nil, err
+		}
+		if !seen[name] {
+			seen[name] = true
+			list = append(list, name)
+		}
+	}
+	return list, nil
+}
+
+// addTag adds a tag to a transaction unless it already has one with the same
+// name, and reports whether it did.
+func addTag(tx *model.Transaction, tag model.Tag) bool {
+	for _, name := range tx.TagNames {
+		if name == tag.Name {
+			return false
+		}
+	}
+	tx.Tags = append(tx.Tags, tag)
+	tx.TagNames = append(tx.TagNames, tag.Name)
+	return true
+}
@@ -1,57 +1,491 @@
 package service
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"statistic_service/internal/model"
 	"statistic_service/internal/repository"
+	"statistic_service/pkg/money"
+	"statistic_service/pkg/storage"
+	"statistic_service/pkg/utils"
+	"strings"
 	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+const (
+	DefaultPageSize = 50
+	MaxPageSize     = 200
+	// MaxCommentLength is the longest comment a transaction may have, in characters.
+	MaxCommentLength = 1000
+	// eachBatchSize is how many transactions Each loads at a time.
+	eachBatchSize = 500
 )
 
+// TransactionPage is one page of a keyset-paginated transaction listing.
+// NextCursor is empty on the last page.
+type TransactionPage struct {
+	Items      []model.Transaction `json:"items"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
 type TransactionService interface {
-	Create(userID string, input *model.Transaction) error
-	List(userID string, from, to *time.Time, txType string) ([]model.Transaction, error)
-	Update(id string, userID string, input *model.Transaction) error
-	Delete(id, userID string) error
-	Summary(userID string, from, to *time.Time) (float64, float64, error)
-	ByCategory(userID string, from, to *time.Time) (map[string]float64, error)
+	Create(ctx context.Context, userID string, input *model.Transaction, onDuplicate DuplicatePolicy) error
+	Validate(userID string, input *model.Transaction) error
+	List(userID string, from, to *time.Time, txType, categoryID string) ([]model.Transaction, error)
+	ListPage(userID string, f repository.TransactionFilter, cursor string, limit int, desc bool) (*TransactionPage, error)
+	Each(userID string, f repository.TransactionFilter, fn func(*model.Transaction) error) error
+	Update(ctx context.Context, id string, userID string, input *model.Transaction) error
+	Delete(ctx context.Context, id, userID string) error
+	Trash(userID string) ([]model.Transaction, error)
+	Restore(ctx context.Context, id, userID string) error
+	Purge(before time.Time) (int, error)
+	Summary(userID string, from, to *time.Time) (money.Amount, money.Amount, error)
+	ByCategory(userID string, from, to *time.Time, depth int) ([]model.CategoryTotal, error)
+	// SpentByPeriod sums the expenses of a category and its subcategories in
+	// [from, to) per week or month.
+	SpentByPeriod(userID, categoryID, period string, from, to time.Time) ([]model.PeriodTotal, error)
+	ByTag(userID string, from, to *time.Time) ([]model.TagTotal, error)
+	FindDuplicates(userID string, tx *model.Transaction) ([]model.Transaction, error)
+	Duplicates(userID string) ([]DuplicatePair, error)
+	Merge(ctx context.Context, id, userID string) (*model.Transaction, error)
+	Dismiss(ctx context.Context, id, userID string) error
+	History(id, userID string) ([]model.TransactionVersion, error)
 }
 
 type txService struct {
-	repo repository.TransactionRepository
+	repo         repository.TransactionRepository
+	categoryRepo repository.CategoryRepository
+	userRepo     repository.UserRepository
+	currencies   CurrencyService
+	accountRepo  repository.AccountRepository
+	ruleRepo     repository.RuleRepository
+	tagRepo      repository.TagRepository
+	// blobs holds the attachment content removed when transactions are purged.
+	blobs storage.Storage
+	// duplicateWindow is how far apart in time two transactions may be
+	// created and still count as duplicates.
+	duplicateWindow time.Duration
 }
 
-func NewTransactionService(r repository.TransactionRepository) TransactionService {
-	return &txService{r}
+func NewTransactionService(r repository.TransactionRepository, c repository.CategoryRepository, u repository.UserRepository, cur CurrencyService, a repository.AccountRepository, rules repository.RuleRepository, tags repository.TagRepository, blobs storage.Storage, duplicateWindow time.Duration) TransactionService {
+	return &txService{r, c, u, cur, a, rules, tags, blobs, duplicateWindow}
 }
 
-func (s *txService) Create(userID string, input *model.Transaction) error {
+// Create applies the user's rules and stores a new transaction, creating
+// tags that do not exist yet. A zero CreatedAt defaults to now. onDuplicate
+// decides what happens when it looks like a duplicate. The creation is
+// recorded in the transaction history with the request ID from ctx.
+func (s *txService) Create(ctx context.Context, userID string, input *model.Transaction, onDuplicate DuplicatePolicy) error {
+	if err := validatePolicy(onDuplicate); err != nil {
+		return err
+	}
+	if err := s.Validate(userID, input); err != nil {
+		return err
+	}
+	input.ID = ""
 	input.UserID = userID
-	input.CreatedAt = time.Now()
-	return s.repo.Create(input)
+	input.TransferID = nil
+	input.DuplicateOf = nil
+	input.DeletedAt = gorm.DeletedAt{}
+	input.Tags = nil
+	if input.CreatedAt.IsZero() {
+		input.CreatedAt = time.Now()
+	}
+	rules, err := loadRules(s.ruleRepo, userID)
+	if err != nil {
+		return err
+	}
+	if _, err := rules.applyChecked(input); err != nil {
+		return err
+	}
+	if onDuplicate != DuplicateIgnore {
+		duplicates, err := s.FindDuplicates(userID, input)
+		if err != nil {
+			return err
+		}
+		if len(duplicates) > 0 {
+			if onDuplicate == DuplicateReject {
+				return &DuplicateError{Candidates: duplicates}
+			}
+			input.DuplicateOf = &duplicates[0].ID
+		}
+	}
+	tags, err := s.tagRepo.Ensure(userID, input.TagNames)
+	if err != nil {
+		return err
+	}
+	input.Tags = tags
+	return s.repo.WithTx(func(r repository.TransactionRepository) error {
+		if err := r.Create(input); err != nil {
+			return err
+		}
+		return r.AddVersions(createdVersions(ctx, userID, input))
+	})
+}
+
+// Validate runs the checks of Create without storing anything, fills in
+// the default currency and normalizes the tag names.
+func (s *txService) Validate(userID string, input *model.Transaction) error {
+	if err := s.validate(userID, input); err != nil {
+		return err
+	}
+	names, err := normalizeTags(input.TagNames)
+	if err != nil {
+		return err
+	}
+	input.TagNames = names
+	return s.resolveCurrency(userID, input, "")
 }
-func (s *txService) List(userID string, from, to *time.Time, txType string) ([]model.Transaction, error) {
-	return s.repo.GetByUser(userID, from, to, txType)
+
+// List returns the user's transactions for statistics. With categoryID a
+// split transaction counts only with the share of that category.
+func (s *txService) List(userID string, from, to *time.Time, txType, categoryID string) ([]model.Transaction, error) {
+	if categoryID != "" {
+		if _, err := uuid.Parse(categoryID); err != nil {
+			return nil, utils.NewValidation("invalid category_id")
+		}
+	}
+	return s.repo.GetByUser(userID, from, to, txType, categoryID)
 }
-func (s *txService) Update(id, userID string, input *model.Transaction) error {
-	existing, err := s.repo.GetByID(id)
-	if err != nil || existing.UserID != userID {
+
+// ListPage returns up to limit transactions after the position encoded in cursor.
+// A cursor is only valid with the sort direction it was issued for.
+func (s *txService) ListPage(userID string, f repository.TransactionFilter, cursor string, limit int, desc bool) (*TransactionPage, error) {
+	if limit == 0 {
+		limit = DefaultPageSize
+	}
+	if limit < 0 || limit > MaxPageSize {
+		return nil, utils.NewValidation(fmt.Sprintf("limit must be between 1 and %d", MaxPageSize))
+	}
+	if err := validateFilter(f); err != nil {
+		return nil, err
+	}
+
+	p := repository.PageQuery{Limit: limit + 1, Desc: desc}
+	if cursor != "" {
+		c, err := decodeCursor(cursor)
+		if err != nil || c.Desc != desc {
+			return nil, utils.NewValidation("invalid cursor")
+		}
+		p.AfterTime = &c.CreatedAt
+		p.AfterID = c.ID
+	}
+
+	items, err := s.repo.GetPage(userID, f, p)
+	if err != nil {
+		return nil, err
+	}
+	page := &TransactionPage{Items: items}
+	if len(items) > limit {
+		page.Items = items[:limit]
+		last := page.Items[limit-1]
+		page.NextCursor = encodeCursor(pageCursor{CreatedAt: last.CreatedAt, ID: last.ID, Desc: desc})
+	}
+	if page.Items == nil {
+		page.Items = []model.Transaction{}
+	}
+	return page, nil
+}
+
+// Each calls fn for every transaction matching f, oldest first. Transactions
+// are loaded in batches, so the whole history is never held in memory.
+func (s *txService) Each(userID string, f repository.TransactionFilter, fn func(*model.Transaction) error) error {
+	if err := validateFilter(f); err != nil {
+		return err
+	}
+	p := repository.PageQuery{Limit: eachBatchSize}
+	for {
+		items, err := s.repo.GetPage(userID, f, p)
+		if err != nil {
+			return err
+		}
+		for i := range items {
+			if err := fn(&items[i]); err != nil {
+				return err
+			}
+		}
+		if len(items) < p.Limit {
+			return nil
+		}
+		last := items[len(items)-1]
+		p.AfterTime = &last.CreatedAt
+		p.AfterID = last.ID
+	}
+}
+
+// Update replaces the fields of a transaction and records the changed ones
+// in its history.
+func (s *txService) Update(ctx context.Context, id, userID string, input *model.Transaction) error {
+	existing, err := s.getOwned(id, userID)
+	if err != nil {
+		return err
+	}
+	before := *existing
+	if existing.TransferID != nil {
+		return utils.NewValidation("transfer transactions cannot be edited, delete the transfer instead")
+	}
+	// Without splits in the input the split lines stay as they are.
+	if input.Splits == nil {
+		input.Splits = existing.Splits
+	}
+	if err := s.validate(userID, input); err != nil {
+		return err
+	}
+	if err := s.resolveCurrency(userID, input, existing.Currency); err != nil {
 		return err
 	}
 	existing.Amount = input.Amount
+	existing.Currency = input.Currency
 	existing.Type = input.Type
-	existing.Category = input.Category
+	existing.CategoryID = input.CategoryID
+	existing.AccountID = input.AccountID
 	existing.Comment = input.Comment
-	return s.repo.Update(existing)
+	existing.Splits = input.Splits
+	// Without tags in the input the tags stay as they are.
+	existing.Tags = nil
+	if input.TagNames != nil {
+		names, err := normalizeTags(input.TagNames)
+		if err != nil {
+			return err
+		}
+		if existing.Tags, err = s.tagRepo.Ensure(userID, names); err != nil {
+			return err
+		}
+		existing.TagNames = names
+	}
+	return s.repo.WithTx(func(r repository.TransactionRepository) error {
+		if err := r.Update(existing); err != nil {
+			return err
+		}
+		return r.AddVersions([]model.TransactionVersion{newVersion(ctx, userID, model.HistoryUpdate, &before, existing)})
+	})
 }
-func (s *txService) Delete(id, userID string) error {
-	tx, err := s.repo.GetByID(id)
-	if err != nil || tx.UserID != userID {
+
+// Delete moves a transaction to the trash, where it stays restorable until
+// it is purged. Deleting one leg of a transfer deletes both.
+func (s *txService) Delete(ctx context.Context, id, userID string) error {
+	tx, err := s.getOwned(id, userID)
+	if err != nil {
 		return err
 	}
-	return s.repo.Delete(id)
+	deleted := []model.Transaction{*tx}
+	if tx.TransferID != nil {
+		if deleted, err = s.repo.GetTransfer(*tx.TransferID); err != nil {
+			return err
+		}
+	}
+	return s.repo.WithTx(func(r repository.TransactionRepository) error {
+		if tx.TransferID != nil {
+			err = r.DeleteTransfer(*tx.TransferID)
+		} else {
+			err = r.Delete(id)
+		}
+		if err != nil {
+			return err
+		}
+		return r.AddVersions(trashVersions(ctx, userID, model.HistoryDelete, deleted))
+	})
 }
-func (s *txService) Summary(userID string, from, to *time.Time) (float64, float64, error) {
+
+func (s *txService) Summary(userID string, from, to *time.Time) (money.Amount, money.Amount, error) {
 	return s.repo.Summary(userID, from, to)
 }
-func (s *txService) ByCategory(userID string, from, to *time.Time) (map[string]float64, error) {
-	return s.repo.ByCategory(userID, from, to)
+func (s *txService) ByTag(userID string, from, to *time.Time) ([]model.TagTotal, error) {
+	return s.repo.ByTag(userID, from, to)
+}
+func (s *txService) ByCategory(userID string, from, to *time.Time, depth int) ([]model.CategoryTotal, error) {
+	if depth < 0 || depth > model.MaxCategoryDepth {
+		return nil, utils.NewValidation(fmt.Sprintf("depth must be between 0 and %d", model.MaxCategoryDepth))
+	}
+	return s.repo.ByCategory(userID, from, to, depth)
+}
+func (s *txService) SpentByPeriod(userID, categoryID, period string, from, to time.Time) ([]model.PeriodTotal, error) {
+	return s.repo.SpentByPeriod(userID, categoryID, period, from, to)
+}
+
+// validate checks the transaction type, that the referenced category belongs
+// to the same user and has the same type, that split lines add up to the
+// amount, and that the referenced account belongs to the same user and is
+// kept in the transaction currency.
+func (s *txService) validate(userID string, input *model.Transaction) error {
+	if !isValidTxType(input.Type) {
+		return utils.NewValidation("transaction type must be income or expense")
+	}
+	if !input.Amount.IsPositive() {
+		return utils.NewValidation("amount must be positive")
+	}
+	if utf8.RuneCountInString(input.Comment) > MaxCommentLength {
+		return utils.NewValidation(fmt.Sprintf("comment must not be longer than %d characters", MaxCommentLength))
+	}
+	if err := s.validateCategory(userID, &input.CategoryID, input.Type); err != nil {
+		return err
+	}
+	if err := s.validateSplits(userID, input); err != nil {
+		return err
+	}
+	return s.validateAccount(userID, input)
+}
+
+// validateCategory clears an empty category ID.
+func (s *txService) validateCategory(userID string, categoryID **string, txType string) error {
+	if *categoryID == nil || **categoryID == "" {
+		*categoryID = nil
+		return nil
+	}
+	if _, err := uuid.Parse(**categoryID); err != nil {
+		return utils.NewValidation("category not found")
+	}
+	category, err := s.categoryRepo.GetByID(**categoryID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && category.UserID != userID) {
+		return utils.NewValidation("category not found")
+	}
+	if err != nil {
+		return err
+	}
+	if category.Type != txType {
+		return utils.NewValidation("category type does not match transaction type")
+	}
+	return nil
+}
+
+// validateSplits checks that a split transaction has no category of its own
+// and at least two lines with positive amounts adding up to the amount.
+// Lines without a category count as uncategorized.
+func (s *txService) validateSplits(userID string, input *model.Transaction) error {
+	if len(input.Splits) == 0 {
+		return nil
+	}
+	if input.CategoryID != nil {
+		return utils.NewValidation("a split transaction cannot have a category, set it on the splits instead")
+	}
+	if len(input.Splits) < 2 || len(input.Splits) > model.MaxSplits {
+		return utils.NewValidation(fmt.Sprintf("a transaction can be split into 2 to %d parts", model.MaxSplits))
+	}
+	var total money.Amount
+	for i := range input.Splits {
+		split := &input.Splits[i]
+		if !split.Amount.IsPositive() {
+			return utils.NewValidation("split amount must be positive")
+		}
+		if err := s.validateCategory(userID, &split.CategoryID, input.Type); err != nil {
+			return err
+		}
+		split.ID = ""
+		split.TransactionID = ""
+		split.Category = nil
+		split.Position = i
+		total = total.Add(split.Amount)
+	}
+	if total != input.Amount {
+		return utils.NewValidation(fmt.Sprintf("split amounts add up to %s instead of %s", total, input.Amount))
+	}
+	return nil
+}
+
+// validateAccount defaults an empty currency to the account currency.
+func (s *txService) validateAccount(userID string, input *model.Transaction) error {
+	if input.AccountID == nil || *input.AccountID == "" {
+		input.AccountID = nil
+		return nil
+	}
+	if _, err := uuid.Parse(*input.AccountID); err != nil {
+		return utils.NewValidation("account not found")
+	}
+	account, err := s.accountRepo.GetByID(*input.AccountID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && account.UserID != userID) {
+		return utils.NewValidation("account not found")
+	}
+	if err != nil {
+		return err
+	}
+	currency := strings.ToUpper(strings.TrimSpace(input.Currency))
+	if currency == "" {
+		input.Currency = account.Currency
+	} else if currency != account.Currency {
+		return utils.NewValidation("currency must match the account currency")
+	}
+	return nil
+}
+
+// resolveCurrency defaults an empty currency to fallback, or to the user's base
+// currency when fallback is empty, and checks it converts into the base currency.
+func (s *txService) resolveCurrency(userID string, input *model.Transaction, fallback string) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return err
+	}
+	input.Currency = strings.ToUpper(strings.TrimSpace(input.Currency))
+	if input.Currency == "" {
+		input.Currency = fallback
+	}
+	if input.Currency == "" {
+		input.Currency = user.BaseCurrency
+	}
+	return s.currencies.CheckConvertible(input.Currency, user.BaseCurrency)
+}
+
+func validateFilter(f repository.TransactionFilter) error {
+	if f.Type != "" && !isValidTxType(f.Type) {
+		return utils.NewValidation("transaction type must be income or expense")
+	}
+	if f.CategoryID != "" {
+		if _, err := uuid.Parse(f.CategoryID); err != nil {
+			return utils.NewValidation("invalid category_id")
+		}
+	}
+	if f.AccountID != "" {
+		if _, err := uuid.Parse(f.AccountID); err != nil {
+			return utils.NewValidation("invalid account_id")
+		}
+	}
+	return nil
+}
+
+// getOwned loads a transaction and hides transactions of other users behind not found.
+func (s *txService) getOwned(id, userID string) (*model.Transaction, error) {
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, utils.NewNotFound("transaction not found")
+	}
+	tx, err := s.repo.GetByID(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && tx.UserID != userID) {
+		return nil, utils.NewNotFound("transaction not found")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// pageCursor is the keyset position behind the opaque cursor string.
+type pageCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        string    `json:"id"`
+	Desc      bool      `json:"d"`
+}
+
+func encodeCursor(c pageCursor) string {
+	b, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeCursor(s string) (pageCursor, error) {
+	var c pageCursor
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return c, err
+	}
+	if err := json.Unmarshal(b, &c); err != nil {
+		return c, err
+	}
+	if _, err := uuid.Parse(c.ID); err != nil {
+		return c, err
+	}
+	return c, nil
 }
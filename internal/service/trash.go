@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/repository"
+	"statistic_service/pkg/utils"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Trash lists the user's deleted transactions, most recently deleted first.
+func (s *txService) Trash(userID string) ([]model.Transaction, error) {
+	return s.repo.GetTrash(userID)
+}
+
+// Restore takes a transaction out of the trash. Restoring one leg of a
+// transfer restores both.
+func (s *txService) Restore(ctx context.Context, id, userID string) error {
+	if _, err := uuid.Parse(id); err != nil {
+		return utils.NewNotFound("transaction not found in trash")
+	}
+	tx, err := s.repo.GetDeleted(id)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && tx.UserID != userID) {
+		return utils.NewNotFound("transaction not found in trash")
+	}
+	if err != nil {
+		return err
+	}
+	restored := []model.Transaction{*tx}
+	if tx.TransferID != nil {
+		if restored, err = s.repo.GetTransfer(*tx.TransferID); err != nil {
+			return err
+		}
+	}
+	err = s.repo.WithTx(func(r repository.TransactionRepository) error {
+		if tx.TransferID != nil {
+			err = r.RestoreTransfer(*tx.TransferID)
+		} else {
+			err = r.Restore(id)
+		}
+		if err != nil {
+			return err
+		}
+		return r.AddVersions(trashVersions(ctx, userID, model.HistoryRestore, restored))
+	})
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.NewNotFound("transaction not found in trash")
+	}
+	return err
+}
+
+// Purge permanently deletes all transactions deleted before the given time,
+// together with the stored content of their attachments, and returns how
+// many it deleted. Their history is kept.
+func (s *txService) Purge(before time.Time) (int, error) {
+	total := 0
+	for {
+		n, attachments, err := s.repo.Purge(before)
+		total += n
+		if err != nil {
+			return total, err
+		}
+		if err := removeBlobs(s.blobs, attachments); err != nil {
+			return total, err
+		}
+		if n == 0 {
+			return total, nil
+		}
+	}
+}
+
+// StartPurge permanently deletes transactions that have been in the trash
+// longer than retention, right away and then every interval until ctx is done.
+func StartPurge(ctx context.Context, txs TransactionService, retention, interval time.Duration, logger *logrus.Logger) {
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			purged, err := txs.Purge(time.Now().Add(-retention))
+			if err != nil {
+				logger.WithError(err).Error("Failed to purge deleted transactions")
+			} else if purged > 0 {
+				logger.WithField("count", purged).Info("Deleted transactions purged")
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"statistic_service/internal/model"
+	"statistic_service/internal/repository"
+	"statistic_service/pkg/jwt"
+	"statistic_service/pkg/mail"
+	"statistic_service/pkg/utils"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+type VerificationService interface {
+	// Send emails the user a link to verify the address.
+	Send(ctx context.Context, user *model.User) error
+	// Resend emails a new link to a user whose address is not verified yet,
+	// at most once per resend interval.
+	Resend(ctx context.Context, userID string) error
+	// Verify marks the address of the token's user verified.
+	Verify(token string) error
+	// Verified reports whether the user's address is verified.
+	Verified(userID string) (bool, error)
+}
+
+type verificationService struct {
+	users  repository.UserRepository
+	tokens repository.VerificationRepository
+	mailer mail.Mailer
+	secret string
+	// ttl is how long a verification link can be used.
+	ttl time.Duration
+	// resendInterval is the least time between two emails to a user.
+	resendInterval time.Duration
+	// verifyURL is the verification endpoint; the link adds the token as the
+	// token query parameter.
+	verifyURL string
+	logger    *logrus.Logger
+}
+
+func NewVerificationService(users repository.UserRepository, tokens repository.VerificationRepository, mailer mail.Mailer, secret string, ttl, resendInterval time.Duration, verifyURL string, logger *logrus.Logger) VerificationService {
+	return &verificationService{users, tokens, mailer, secret, ttl, resendInterval, verifyURL, logger}
+}
+
+func (s *verificationService) Send(ctx context.Context, user *model.User) error {
+	token, err := jwt.GenerateOneTimeToken()
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to generate verification token")
+		return err
+	}
+	verification := &model.EmailVerificationToken{
+		UserID:    user.ID,
+		TokenHash: jwt.HashToken(token, s.secret),
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+	if err := s.tokens.Create(verification); err != nil {
+		s.logger.WithError(err).Error("Failed to save verification token")
+		return err
+	}
+	msg := mail.Message{To: user.Email, Subject: "Verify your email address", Body: s.verifyBody(token)}
+	if err := s.mailer.Send(ctx, msg); err != nil {
+		return err
+	}
+	s.logger.WithField("userID", user.ID).Info("Verification email sent")
+	return nil
+}
+
+func (s *verificationService) Resend(ctx context.Context, userID string) error {
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get user by ID")
+		return err
+	}
+	if user.EmailVerified {
+		return utils.NewConflict("email address is already verified")
+	}
+	latest, err := s.tokens.GetLatest(userID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		s.logger.WithError(err).Error("Failed to get verification token")
+		return err
+	}
+	if err == nil {
+		if wait := s.resendInterval - time.Since(latest.CreatedAt); wait > 0 {
+			return utils.NewTooManyRequests(fmt.Sprintf("verification email was sent recently, try again in %s", wait.Round(time.Second)))
+		}
+	}
+	if err := s.Send(ctx, user); err != nil {
+		s.logger.WithError(err).Error("Failed to send verification email")
+		return err
+	}
+	return nil
+}
+
+func (s *verificationService) Verify(token string) error {
+	invalid := utils.NewValidation("invalid or expired verification token")
+
+	tokenHash := jwt.HashToken(token, s.secret)
+	verification, err := s.tokens.GetByHash(tokenHash)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return invalid
+	}
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to get verification token")
+		return err
+	}
+	now := time.Now()
+	if subtle.ConstantTimeCompare([]byte(verification.TokenHash), []byte(tokenHash)) != 1 || now.After(verification.ExpiresAt) {
+		return invalid
+	}
+	verified, err := s.tokens.Verify(verification, now)
+	if err != nil {
+		s.logger.WithError(err).Error("Failed to verify email")
+		return err
+	}
+	if !verified {
+		return invalid
+	}
+	s.logger.WithField("userID", verification.UserID).Info("Email verified")
+	return nil
+}
+
+func (s *verificationService) Verified(userID string) (bool, error) {
+	user, err := s.users.GetByID(userID)
+	if err != nil {
+		return false, err
+	}
+	return user.EmailVerified, nil
+}
+
+// verifyBody is the text of the verification email.
+func (s *verificationService) verifyBody(token string) string {
+	link := token
+	if u, err := url.Parse(s.verifyURL); err == nil {
+		q := u.Query()
+		q.Set("token", token)
+		u.RawQuery = q.Encode()
+		link = u.String()
+	}
+	return fmt.Sprintf("Welcome! Open this link to verify your email address:\n%s\n\n"+
+		"The link is valid for %s. If you did not create an account, ignore this email.\n",
+		link, s.ttl)
+}
@@ -9,10 +9,14 @@ import (
 	"path/filepath"
 	"statistic_service/internal/config"
 	"statistic_service/internal/handler"
+	"statistic_service/internal/middleware"
 	"statistic_service/internal/model"
 	"statistic_service/internal/repository"
 	"statistic_service/internal/service"
+	"statistic_service/pkg/jwt"
+	"statistic_service/pkg/mail"
 	"statistic_service/pkg/utils"
+	"strings"
 	"testing"
 	"time"
 
@@ -33,7 +37,7 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	}
 
 	// Auto-migrate the User and RefreshToken models
-	err = db.AutoMigrate(&model.User{}, &model.RefreshToken{})
+	err = db.AutoMigrate(&model.User{}, &model.RefreshToken{}, &model.RevokedSession{}, &model.SecurityEvent{}, &model.PasswordResetToken{}, &model.EmailVerificationToken{})
 	if err != nil {
 		t.Fatalf("Failed to migrate test database: %v", err)
 	}
@@ -41,6 +45,10 @@ func setupTestDB(t *testing.T) *gorm.DB {
 	// Clean up existing data to ensure test isolation
 	db.Exec("DELETE FROM users")
 	db.Exec("DELETE FROM refresh_tokens")
+	db.Exec("DELETE FROM revoked_sessions")
+	db.Exec("DELETE FROM security_events")
+	db.Exec("DELETE FROM password_reset_tokens")
+	db.Exec("DELETE FROM email_verification_tokens")
 
 	return db
 }
@@ -76,6 +84,13 @@ func setupRouter(t *testing.T, db *gorm.DB, logger *logrus.Logger) (*gin.Engine,
 	r.POST("/login", authHandler.Login)
 	r.POST("/refresh", authHandler.Refresh)
 
+	authMiddleware := middleware.JWTAuth(cfg.JWTSecret, authService)
+	r.GET("/me", authMiddleware, authHandler.GetProfile)
+	r.POST("/logout", authMiddleware, authHandler.Logout)
+	r.GET("/sessions", authMiddleware, authHandler.Sessions)
+	r.DELETE("/sessions", authMiddleware, authHandler.RevokeAllSessions)
+	r.DELETE("/sessions/:id", authMiddleware, authHandler.RevokeSession)
+
 	return r, authHandler
 }
 
@@ -339,18 +354,22 @@ func TestRefresh(t *testing.T) {
 	}
 
 	validRefreshToken := "valid-refresh-token"
+	validSessionID := uuid.New().String()
 	db.Create(&model.RefreshToken{
-		ID:        uuid.New().String(),
+		ID:        validSessionID,
+		FamilyID:  validSessionID,
 		UserID:    user.ID,
-		Token:     validRefreshToken,
+		TokenHash: jwt.HashToken(validRefreshToken, "test_secret"),
 		ExpiresAt: time.Now().Add(24 * time.Hour),
 	})
 
 	expiredRefreshToken := "expired-refresh-token"
+	expiredSessionID := uuid.New().String()
 	db.Create(&model.RefreshToken{
-		ID:        uuid.New().String(),
+		ID:        expiredSessionID,
+		FamilyID:  expiredSessionID,
 		UserID:    user.ID,
-		Token:     expiredRefreshToken,
+		TokenHash: jwt.HashToken(expiredRefreshToken, "test_secret"),
 		ExpiresAt: time.Now().Add(-24 * time.Hour),
 	})
 
@@ -462,4 +481,455 @@ func TestRefresh(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestSessions(t *testing.T) {
+	db := setupTestDB(t)
+	logger := setupTestLogger(t)
+	router, _ := setupRouter(t, db, logger)
+
+	send := func(method, path, token, userAgent string, body interface{}) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		if userAgent != "" {
+			req.Header.Set("User-Agent", userAgent)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	credentials := map[string]string{"email": "sessions@example.com", "password": "Password123!"}
+	if w := send("POST", "/register", "", "", credentials); w.Code != http.StatusCreated {
+		t.Fatalf("Failed to register user, status: %d", w.Code)
+	}
+	login := func(userAgent string) (string, string) {
+		w := send("POST", "/login", "", userAgent, credentials)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Failed to log in, status: %d", w.Code)
+		}
+		var tokens map[string]string
+		json.Unmarshal(w.Body.Bytes(), &tokens)
+		return tokens["access_token"], tokens["refresh_token"]
+	}
+	listSessions := func(token string) []service.Session {
+		w := send("GET", "/sessions", token, "", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("Expected status 200 for sessions, got %d: %s", w.Code, w.Body.String())
+		}
+		var sessions []service.Session
+		json.Unmarshal(w.Body.Bytes(), &sessions)
+		return sessions
+	}
+
+	phoneAccess, phoneRefresh := login("phone")
+	laptopAccess, _ := login("laptop")
+
+	// Both sessions are listed, the one of the request is current
+	sessions := listSessions(laptopAccess)
+	if len(sessions) != 2 {
+		t.Fatalf("Expected 2 sessions, got %d", len(sessions))
+	}
+	var phoneID string
+	for _, s := range sessions {
+		if s.UserAgent == "laptop" && !s.Current || s.UserAgent == "phone" && s.Current {
+			t.Errorf("Unexpected current flag on session %+v", s)
+		}
+		if s.UserAgent == "phone" {
+			phoneID = s.ID
+		}
+	}
+
+	// Refreshing keeps the session
+	w := send("POST", "/refresh", "", "phone", map[string]string{"refresh_token": phoneRefresh})
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for refresh, got %d", w.Code)
+	}
+	var refreshed map[string]string
+	json.Unmarshal(w.Body.Bytes(), &refreshed)
+	if len(listSessions(laptopAccess)) != 2 {
+		t.Errorf("Expected refresh to keep 2 sessions")
+	}
+
+	// Revoking the phone session rejects its access and refresh tokens
+	if w := send("DELETE", "/sessions/"+phoneID, laptopAccess, "", nil); w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204 for revoke, got %d", w.Code)
+	}
+	if w := send("GET", "/me", phoneAccess, "", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for revoked access token, got %d", w.Code)
+	}
+	if w := send("GET", "/me", refreshed["access_token"], "", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for refreshed access token, got %d", w.Code)
+	}
+	if w := send("POST", "/refresh", "", "", map[string]string{"refresh_token": refreshed["refresh_token"]}); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for revoked refresh token, got %d", w.Code)
+	}
+	if w := send("DELETE", "/sessions/"+phoneID, laptopAccess, "", nil); w.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for revoked session, got %d", w.Code)
+	}
+
+	// Logout ends the current session
+	if w := send("POST", "/logout", laptopAccess, "", nil); w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204 for logout, got %d", w.Code)
+	}
+	if w := send("GET", "/me", laptopAccess, "", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 after logout, got %d", w.Code)
+	}
+
+	// Revoking all sessions ends every session
+	access, _ := login("phone")
+	login("laptop")
+	w = send("DELETE", "/sessions", access, "", nil)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for revoke all, got %d", w.Code)
+	}
+	var revoked map[string]int
+	json.Unmarshal(w.Body.Bytes(), &revoked)
+	if revoked["revoked"] != 2 {
+		t.Errorf("Expected 2 revoked sessions, got %d", revoked["revoked"])
+	}
+	if w := send("GET", "/sessions", access, "", nil); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 after revoking all sessions, got %d", w.Code)
+	}
+}
+
+func TestRefresh_Reuse(t *testing.T) {
+	db := setupTestDB(t)
+	logger := setupTestLogger(t)
+	router, _ := setupRouter(t, db, logger)
+
+	send := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	refresh := func(refreshToken string) (int, map[string]string) {
+		w := send("POST", "/refresh", "", map[string]string{"refresh_token": refreshToken})
+		var response map[string]string
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return w.Code, response
+	}
+
+	credentials := map[string]string{"email": "reuse@example.com", "password": "Password123!"}
+	send("POST", "/register", "", credentials)
+	w := send("POST", "/login", "", credentials)
+	if w.Code != http.StatusOK {
+		t.Fatalf("Failed to log in, status: %d", w.Code)
+	}
+	var login map[string]string
+	json.Unmarshal(w.Body.Bytes(), &login)
+
+	// Only the hash of the refresh token is stored
+	var stored int64
+	db.Model(&model.RefreshToken{}).Where("token_hash = ?", login["refresh_token"]).Count(&stored)
+	if stored != 0 || len(login["refresh_token"]) < 43 {
+		t.Errorf("Expected a random refresh token stored as a hash, got %q", login["refresh_token"])
+	}
+
+	// Rotating twice keeps one session
+	code, first := refresh(login["refresh_token"])
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200 for refresh, got %d", code)
+	}
+	code, second := refresh(first["refresh_token"])
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200 for second refresh, got %d", code)
+	}
+	if w := send("GET", "/sessions", second["access_token"], nil); !strings.Contains(w.Body.String(), `"current":true`) || strings.Count(w.Body.String(), `"id"`) != 1 {
+		t.Errorf("Expected one current session, got %s", w.Body.String())
+	}
+
+	// Replaying a rotated-out token revokes the whole family
+	code, response := refresh(login["refresh_token"])
+	if code != http.StatusUnauthorized || response["type"] != string(utils.ErrRefreshTokenReused) {
+		t.Errorf("Expected reuse to be rejected, got %d: %v", code, response)
+	}
+	if code, _ := refresh(second["refresh_token"]); code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for the latest token of a revoked family, got %d", code)
+	}
+	for _, access := range []string{login["access_token"], second["access_token"]} {
+		if w := send("GET", "/me", access, nil); w.Code != http.StatusUnauthorized {
+			t.Errorf("Expected status 401 for access token of a revoked family, got %d", w.Code)
+		}
+	}
+
+	// The reuse is recorded as a security event
+	var events []model.SecurityEvent
+	db.Where("type = ?", model.SecurityEventRefreshTokenReuse).Find(&events)
+	if len(events) != 1 {
+		t.Errorf("Expected 1 security event, got %d", len(events))
+	}
+}
+
+func setupPasswordRouter(t *testing.T, db *gorm.DB, logger *logrus.Logger, mailFile string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{JWTSecret: "test_secret"}
+	userRepo := repository.NewUserRepository(db)
+	authService := service.NewAuthService(userRepo, cfg.JWTSecret, logger)
+	mailer, err := mail.NewFile(mailFile, "Service <no-reply@example.com>")
+	if err != nil {
+		t.Fatalf("Failed to open mail file: %v", err)
+	}
+	passwordService := service.NewPasswordService(userRepo, repository.NewPasswordResetRepository(db), mailer, cfg.JWTSecret, time.Hour, time.Hour, "https://app.example.com/reset", logger)
+	authHandler := handler.NewAuthHandler(authService, logger)
+	passwordHandler := handler.NewPasswordHandler(passwordService, logger)
+
+	r := gin.Default()
+	r.POST("/register", authHandler.Register)
+	r.POST("/login", authHandler.Login)
+	r.POST("/password/forgot", passwordHandler.Forgot)
+	r.POST("/password/reset", passwordHandler.Reset)
+	r.GET("/me", middleware.JWTAuth(cfg.JWTSecret, authService), authHandler.GetProfile)
+	return r
+}
+
+func TestPasswordReset(t *testing.T) {
+	db := setupTestDB(t)
+	logger := setupTestLogger(t)
+	mailFile := filepath.Join(t.TempDir(), "mail.log")
+	router := setupPasswordRouter(t, db, logger, mailFile)
+
+	send := func(path string, body interface{}) *httptest.ResponseRecorder {
+		bodyBytes, _ := json.Marshal(body)
+		req, _ := http.NewRequest("POST", path, bytes.NewBuffer(bodyBytes))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	// resetToken waits for the n-th email, which is sent in the background,
+	// and returns the token of the last email in the mail file
+	resetToken := func(n int) string {
+		var b []byte
+		for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+			if b, _ = os.ReadFile(mailFile); strings.Count(string(b), "Reset token: ") >= n {
+				i := strings.LastIndex(string(b), "Reset token: ")
+				return strings.Fields(string(b)[i+len("Reset token: "):])[0]
+			}
+		}
+		t.Fatalf("Expected %d reset emails, got %q", n, b)
+		return ""
+	}
+
+	credentials := map[string]string{"email": "forgot@example.com", "password": "Password123!"}
+	send("/register", credentials)
+	w := send("/login", credentials)
+	var login map[string]string
+	json.Unmarshal(w.Body.Bytes(), &login)
+
+	// Unknown emails get the same answer and no email
+	if w := send("/password/forgot", map[string]string{"email": "nobody@example.com"}); w.Code != http.StatusAccepted {
+		t.Errorf("Expected status 202 for unknown email, got %d", w.Code)
+	}
+	if _, err := os.Stat(mailFile); err == nil {
+		t.Errorf("Expected no email for an unknown address")
+	}
+
+	// The email has the token and a link; only its hash is stored
+	if w := send("/password/forgot", map[string]string{"email": "forgot@example.com"}); w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202, got %d", w.Code)
+	}
+	token := resetToken(1)
+	if b, _ := os.ReadFile(mailFile); !strings.Contains(string(b), "https://app.example.com/reset?token="+token) {
+		t.Errorf("Expected a reset link in %q", b)
+	}
+	var stored int64
+	db.Model(&model.PasswordResetToken{}).Where("token_hash = ?", token).Count(&stored)
+	if stored != 0 {
+		t.Errorf("Expected the reset token to be stored hashed")
+	}
+
+	// Another request within the interval gets the same answer and no email
+	if w := send("/password/forgot", map[string]string{"email": "forgot@example.com"}); w.Code != http.StatusAccepted {
+		t.Errorf("Expected status 202 for a repeated request, got %d", w.Code)
+	}
+	if b, _ := os.ReadFile(mailFile); strings.Count(string(b), "Reset token: ") != 1 {
+		t.Errorf("Expected no second email within the interval, got %q", b)
+	}
+	// age moves the stored tokens out of the interval
+	age := func() {
+		db.Model(&model.PasswordResetToken{}).Where("user_id IS NOT NULL").Update("created_at", time.Now().Add(-2*time.Hour))
+	}
+
+	// A weak password and an unknown token are rejected
+	if w := send("/password/reset", map[string]string{"token": token, "password": "password"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for weak password, got %d", w.Code)
+	}
+	if w := send("/password/reset", map[string]string{"token": "unknown", "password": "NewPassword1!"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for unknown token, got %d", w.Code)
+	}
+
+	// Resetting sets the password and ends all sessions
+	if w := send("/password/reset", map[string]string{"token": token, "password": "NewPassword1!"}); w.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204 for reset, got %d: %s", w.Code, w.Body.String())
+	}
+	req, _ := http.NewRequest("GET", "/me", nil)
+	req.Header.Set("Authorization", "Bearer "+login["access_token"])
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected status 401 for a session from before the reset, got %d", w.Code)
+	}
+	if w := send("/login", credentials); w.Code != http.StatusUnauthorized {
+		t.Errorf("Expected the old password to be rejected, got %d", w.Code)
+	}
+	if w := send("/login", map[string]string{"email": "forgot@example.com", "password": "NewPassword1!"}); w.Code != http.StatusOK {
+		t.Errorf("Expected login with the new password, got %d", w.Code)
+	}
+
+	// The token can be used once
+	if w := send("/password/reset", map[string]string{"token": token, "password": "OtherPassword1!"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a used token, got %d", w.Code)
+	}
+
+	// A new email replaces the earlier token, and expired tokens are rejected
+	age()
+	send("/password/forgot", map[string]string{"email": "forgot@example.com"})
+	first := resetToken(2)
+	age()
+	send("/password/forgot", map[string]string{"email": "forgot@example.com"})
+	last := resetToken(3)
+	if w := send("/password/reset", map[string]string{"token": first, "password": "OtherPassword1!"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a replaced token, got %d", w.Code)
+	}
+	db.Model(&model.PasswordResetToken{}).Where("used_at IS NULL").Update("expires_at", time.Now().Add(-time.Minute))
+	if w := send("/password/reset", map[string]string{"token": last, "password": "OtherPassword1!"}); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an expired token, got %d", w.Code)
+	}
+}
+
+func setupVerificationRouter(t *testing.T, db *gorm.DB, logger *logrus.Logger, mailFile string) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	cfg := &config.Config{JWTSecret: "test_secret"}
+	userRepo := repository.NewUserRepository(db)
+	authService := service.NewAuthService(userRepo, cfg.JWTSecret, logger)
+	mailer, err := mail.NewFile(mailFile, "Service <no-reply@example.com>")
+	if err != nil {
+		t.Fatalf("Failed to open mail file: %v", err)
+	}
+	verificationService := service.NewVerificationService(userRepo, repository.NewVerificationRepository(db), mailer, cfg.JWTSecret, time.Hour, time.Minute, "https://api.example.com/verify-email", logger)
+	authService.SetVerification(verificationService)
+	authHandler := handler.NewAuthHandler(authService, logger)
+	verificationHandler := handler.NewVerificationHandler(verificationService, logger)
+
+	authMiddleware := middleware.JWTAuth(cfg.JWTSecret, authService)
+	verified := middleware.VerificationPolicy(verificationService, []string{middleware.FeatureExport})
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+
+	r := gin.Default()
+	r.POST("/register", authHandler.Register)
+	r.POST("/login", authHandler.Login)
+	r.GET("/me", authMiddleware, authHandler.GetProfile)
+	r.GET("/verify-email", verificationHandler.Verify)
+	r.POST("/verify-email/resend", authMiddleware, verificationHandler.Resend)
+	r.GET("/export", authMiddleware, verified(middleware.FeatureExport), ok)
+	r.GET("/import", authMiddleware, verified(middleware.FeatureImport), ok)
+	return r
+}
+
+func TestEmailVerification(t *testing.T) {
+	db := setupTestDB(t)
+	logger := setupTestLogger(t)
+	mailFile := filepath.Join(t.TempDir(), "mail.log")
+	router := setupVerificationRouter(t, db, logger, mailFile)
+
+	send := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			json.NewEncoder(&buf).Encode(body)
+		}
+		req, _ := http.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	// verifyLink returns the path of the link in the last email
+	verifyLink := func() string {
+		b, _ := os.ReadFile(mailFile)
+		i := strings.LastIndex(string(b), "https://api.example.com")
+		if i < 0 {
+			t.Fatalf("Expected a verification email, got %q", b)
+		}
+		return strings.Fields(string(b)[i+len("https://api.example.com"):])[0]
+	}
+	emailVerified := func(token string) interface{} {
+		var profile map[string]interface{}
+		json.Unmarshal(send("GET", "/me", token, nil).Body.Bytes(), &profile)
+		return profile["email_verified"]
+	}
+
+	// Registration emails a link; the account works but is not verified
+	credentials := map[string]string{"email": "verify@example.com", "password": "Password123!"}
+	if w := send("POST", "/register", "", credentials); w.Code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", w.Code)
+	}
+	first := verifyLink()
+	var login map[string]string
+	json.Unmarshal(send("POST", "/login", "", credentials).Body.Bytes(), &login)
+	access := login["access_token"]
+	if v := emailVerified(access); v != false {
+		t.Errorf("Expected email_verified false, got %v", v)
+	}
+
+	// Restricted features need a verified email, the others do not
+	if w := send("GET", "/export", access, nil); w.Code != http.StatusForbidden {
+		t.Errorf("Expected status 403 for export, got %d", w.Code)
+	}
+	if w := send("GET", "/import", access, nil); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 for an unrestricted feature, got %d", w.Code)
+	}
+
+	// Resending is throttled, then replaces the earlier link
+	if w := send("POST", "/verify-email/resend", access, nil); w.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected status 429 for an immediate resend, got %d", w.Code)
+	}
+	db.Model(&model.EmailVerificationToken{}).Where("1 = 1").Update("created_at", time.Now().Add(-2*time.Minute))
+	if w := send("POST", "/verify-email/resend", access, nil); w.Code != http.StatusAccepted {
+		t.Fatalf("Expected status 202 for resend, got %d", w.Code)
+	}
+	link := verifyLink()
+	if w := send("GET", first, "", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a replaced link, got %d", w.Code)
+	}
+	if w := send("GET", "/verify-email?token=unknown", "", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for an unknown token, got %d", w.Code)
+	}
+
+	// The link verifies the email once
+	if w := send("GET", link, "", nil); w.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for verification, got %d: %s", w.Code, w.Body.String())
+	}
+	if v := emailVerified(access); v != true {
+		t.Errorf("Expected email_verified true, got %v", v)
+	}
+	if w := send("GET", "/export", access, nil); w.Code != http.StatusOK {
+		t.Errorf("Expected status 200 for export after verification, got %d", w.Code)
+	}
+	if w := send("GET", link, "", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 for a used link, got %d", w.Code)
+	}
+	if w := send("POST", "/verify-email/resend", access, nil); w.Code != http.StatusConflict {
+		t.Errorf("Expected status 409 for resend after verification, got %d", w.Code)
+	}
+}
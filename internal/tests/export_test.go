@@ -0,0 +1,88 @@
+package tests
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+
+	"statistic_service/pkg/export"
+)
+
+var exportColumns = []export.Column{{Name: "name"}, {Name: "amount", Numeric: true}}
+
+func writeExport(t *testing.T, format string, rows [][]string) []byte {
+	var buf bytes.Buffer
+	w, err := export.NewWriter(format, &buf, exportColumns)
+	if err != nil {
+		t.Fatalf("NewWriter(%s): %v", format, err)
+	}
+	for _, row := range rows {
+		if err := w.WriteRow(row); err != nil {
+			t.Fatalf("WriteRow: %v", err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestExport_CSVAndNDJSON(t *testing.T) {
+	rows := [][]string{{"Rent, flat", "1200.00"}, {"Coffee", "3.50"}, {"=HYPERLINK(\"x\")", "-2.00"}}
+
+	got := string(writeExport(t, export.CSV, rows))
+	want := "name,amount\n\"Rent, flat\",1200.00\nCoffee,3.50\n\"'=HYPERLINK(\"\"x\"\")\",-2.00\n"
+	if got != want {
+		t.Errorf("csv = %q; want %q", got, want)
+	}
+
+	lines := strings.Split(strings.TrimSpace(string(writeExport(t, export.NDJSON, rows))), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("want 3 ndjson lines; got %d", len(lines))
+	}
+	var obj map[string]string
+	if err := json.Unmarshal([]byte(lines[0]), &obj); err != nil || obj["name"] != "Rent, flat" || obj["amount"] != "1200.00" {
+		t.Errorf("unexpected ndjson line %q: %v", lines[0], err)
+	}
+
+	if _, err := export.NewWriter("pdf", io.Discard, exportColumns); err != export.ErrUnknownFormat {
+		t.Errorf("want ErrUnknownFormat; got %v", err)
+	}
+}
+
+func TestExport_XLSX(t *testing.T) {
+	data := writeExport(t, export.XLSX, [][]string{{"Books & <More>", "9.99"}, {"@SUM(A1)", "-1"}})
+
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("xlsx is not a zip: %v", err)
+	}
+	parts := map[string]string{}
+	for _, f := range zr.File {
+		rc, _ := f.Open()
+		b, _ := io.ReadAll(rc)
+		rc.Close()
+		parts[f.Name] = string(b)
+	}
+	for _, name := range []string{"[Content_Types].xml", "_rels/.rels", "xl/workbook.xml", "xl/_rels/workbook.xml.rels", "xl/worksheets/sheet1.xml"} {
+		if _, ok := parts[name]; !ok {
+			t.Errorf("missing part %s", name)
+		}
+	}
+	sheet := parts["xl/worksheets/sheet1.xml"]
+	if !strings.Contains(sheet, "<t xml:space=\"preserve\">Books &amp; &lt;More&gt;</t>") {
+		t.Errorf("want escaped inline string in sheet; got %s", sheet)
+	}
+	if !strings.Contains(sheet, "<c><v>9.99</v></c>") {
+		t.Errorf("want numeric amount cell in sheet; got %s", sheet)
+	}
+	if !strings.Contains(sheet, "<t xml:space=\"preserve\">@SUM(A1)</t>") || !strings.Contains(sheet, "<c><v>-1</v></c>") {
+		t.Errorf("want formula-like text and negative amount kept as is; got %s", sheet)
+	}
+	if !strings.Contains(sheet, "<t xml:space=\"preserve\">amount</t>") {
+		t.Errorf("want header row in sheet; got %s", sheet)
+	}
+}
@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+
+	"statistic_service/pkg/fuzzy"
+)
+
+func TestFuzzy_Normalize(t *testing.T) {
+	if got := fuzzy.Normalize("  Coffee,  at STARBUCKS #12! "); got != "coffee at starbucks 12" {
+		t.Errorf("unexpected normalized text %q", got)
+	}
+}
+
+func TestFuzzy_Match(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want bool
+	}{
+		{"", "", true},
+		{"Lunch", "lunch.", true},
+		{"Supermarket", "Supermarkt", true},
+		{"Card payment REWE 1234", "REWE", true},
+		{"Rent", "Taxi", false},
+		{"Coffee", "", false},
+		{"a", "pizza", false},
+		{"1", "coffee 1", false},
+		{"ewe", "REWE Berlin", false},
+		{"REWE Berlin", "Card payment rewe berlin 12", true},
+	}
+	for _, tt := range tests {
+		if got := fuzzy.Match(tt.a, tt.b, 0.8); got != tt.want {
+			t.Errorf("Match(%q, %q) = %v; want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+	if s := fuzzy.Similarity("kitten", "sitting"); s < 0.57 || s > 0.58 {
+		t.Errorf("want similarity 4/7; got %f", s)
+	}
+	long := strings.Repeat("a", 300)
+	if s := fuzzy.Similarity(long, long+"b"); s != 1 {
+		t.Errorf("want inputs compared up to 256 characters; got similarity %f", s)
+	}
+}
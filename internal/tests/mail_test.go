@@ -0,0 +1,144 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"statistic_service/pkg/mail"
+)
+
+func TestMail_File(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail", "sent.log")
+	m, err := mail.NewFile(path, "Service <no-reply@example.com>")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	if err := m.Send(ctx, mail.Message{To: "a@example.com", Subject: "Сброс пароля", Body: "first\nline"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Send(ctx, mail.Message{To: "b@example.com", Subject: "Second", Body: "second"}); err != nil {
+		t.Fatal(err)
+	}
+
+	// Письма дописываются в файл с заголовками и CRLF
+	b, _ := os.ReadFile(path)
+	out := string(b)
+	for _, want := range []string{"From: Service <no-reply@example.com>\r\n", "To: a@example.com\r\n", "Subject: =?utf-8?q?", "first\r\nline\r\n", "To: b@example.com\r\n"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("want %q in %q", want, out)
+		}
+	}
+
+	// Переводы строк в заголовках отклоняются
+	if err := m.Send(ctx, mail.Message{To: "a@example.com\r\nBcc: x@example.com", Subject: "x"}); err == nil {
+		t.Error("want error for header injection")
+	}
+}
+
+// fakeSMTP accepts one message and sends it to received.
+func fakeSMTP(t *testing.T, received chan<- string) string {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { ln.Close() })
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+		reply("220 fake ESMTP")
+		var envelope []string
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.TrimSpace(line)
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"):
+				reply("250-fake")
+				reply("250 8BITMIME")
+			case strings.HasPrefix(cmd, "MAIL FROM:"), strings.HasPrefix(cmd, "RCPT TO:"):
+				envelope = append(envelope, cmd)
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil || l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				reply("250 queued")
+				received <- strings.Join(envelope, "\n") + "\n" + data.String()
+			case cmd == "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("502 unknown")
+			}
+		}
+	}()
+	return ln.Addr().String()
+}
+
+func TestMail_SMTP(t *testing.T) {
+	received := make(chan string, 1)
+	host, port, _ := net.SplitHostPort(fakeSMTP(t, received))
+	p, _ := strconv.Atoi(port)
+	m, err := mail.NewSMTP(mail.SMTPConfig{Host: host, Port: p, From: "Service <no-reply@example.com>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Send(context.Background(), mail.Message{To: "user@example.com", Subject: "Reset", Body: "token"}); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+
+	// Конверт содержит голые адреса, письмо — заголовки и текст
+	got := <-received
+	for _, want := range []string{"MAIL FROM:<no-reply@example.com>", "RCPT TO:<user@example.com>", "Subject: Reset\r\n", "\r\n\r\ntoken\r\n"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("want %q in %q", want, got)
+		}
+	}
+
+	if _, err := mail.NewSMTP(mail.SMTPConfig{Host: host, From: "not an address"}); err == nil {
+		t.Error("want error for invalid sender")
+	}
+	if err := m.Send(context.Background(), mail.Message{To: "nobody", Subject: "x"}); err == nil {
+		t.Error("want error for invalid recipient")
+	}
+}
+
+// TestMail_MailHog sends through a real SMTP server, such as a local MailHog,
+// when SMTP_TEST_HOST is set.
+func TestMail_MailHog(t *testing.T) {
+	host := os.Getenv("SMTP_TEST_HOST")
+	if host == "" {
+		t.Skip("SMTP_TEST_HOST is not set")
+	}
+	port, err := strconv.Atoi(os.Getenv("SMTP_TEST_PORT"))
+	if err != nil {
+		port = 1025
+	}
+	m, err := mail.NewSMTP(mail.SMTPConfig{Host: host, Port: port, From: "Statistic Service <no-reply@statistic.local>"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Send(context.Background(), mail.Message{To: "test@example.com", Subject: "Test", Body: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+}
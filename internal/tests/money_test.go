@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"statistic_service/pkg/money"
+)
+
+func TestMoney_Parse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr error
+	}{
+		{in: "12", want: "12.00"},
+		{in: "12.3", want: "12.30"},
+		{in: "-0.05", want: "-0.05"},
+		{in: ".5", want: "0.50"},
+		{in: "1.230", want: "1.23"},
+		{in: "999999999999.99", want: "999999999999.99"},
+		{in: "1.234", wantErr: money.ErrPrecision},
+		{in: "1e3", wantErr: money.ErrSyntax},
+		{in: "abc", wantErr: money.ErrSyntax},
+		{in: "", wantErr: money.ErrSyntax},
+		{in: "1000000000000", wantErr: money.ErrRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := money.Parse(tt.in)
+			if err != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, want %v", tt.in, err, tt.wantErr)
+			}
+			if err == nil && got.String() != tt.want {
+				t.Errorf("Parse(%q) = %s, want %s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMoney_Sum(t *testing.T) {
+	// 0.1 + 0.2 не дрейфует, в отличие от float64
+	var sum money.Amount
+	for i := 0; i < 10; i++ {
+		sum = sum.Add(money.MustParse("0.10"))
+	}
+	if sum != money.MustParse("1") {
+		t.Errorf("want 1.00; got %s", sum)
+	}
+}
+
+func TestMoney_MulFrac(t *testing.T) {
+	tests := []struct {
+		a        string
+		num, den int64
+		want     string
+	}{
+		{"10.00", 1, 3, "3.33"},
+		{"0.05", 1, 2, "0.03"},
+		{"-0.05", 1, 2, "-0.03"},
+		{"0.05", -1, 2, "-0.03"},
+		{"100.00", 31, 15, "206.67"},
+	}
+	for _, tt := range tests {
+		got := money.MustParse(tt.a).MulFrac(tt.num, tt.den)
+		if got.String() != tt.want {
+			t.Errorf("%s * %d/%d = %s, want %s", tt.a, tt.num, tt.den, got, tt.want)
+		}
+	}
+}
+
+func TestMoney_JSONAndScan(t *testing.T) {
+	var v struct {
+		Amount money.Amount `json:"amount"`
+	}
+	for _, in := range []string{`{"amount": 42.5}`, `{"amount": "42.50"}`} {
+		if err := json.Unmarshal([]byte(in), &v); err != nil || v.Amount != money.FromMinor(4250) {
+			t.Errorf("unmarshal %s: got %s, err %v", in, v.Amount, err)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"amount": 0.001}`), &v); err == nil {
+		t.Errorf("want precision error for 0.001")
+	}
+	b, _ := json.Marshal(v)
+	if string(b) != `{"amount":"42.50"}` {
+		t.Errorf("unexpected JSON %s", b)
+	}
+
+	var a money.Amount
+	if err := a.Scan("14.4000000000000000"); err != nil || a.String() != "14.40" {
+		t.Errorf("scan: got %s, err %v", a, err)
+	}
+	if err := a.Scan([]byte("2.345")); err != nil || a.String() != "2.35" {
+		t.Errorf("scan rounding: got %s, err %v", a, err)
+	}
+}
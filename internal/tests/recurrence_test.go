@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"statistic_service/pkg/recurrence"
+)
+
+func TestRecurrence_At(t *testing.T) {
+	day := func(s string) time.Time {
+		d, _ := time.Parse("2006-01-02 15:04", s)
+		return d
+	}
+	tests := []struct {
+		name string
+		rule recurrence.Rule
+		want []string
+	}{
+		{
+			name: "daily every 2 days",
+			rule: recurrence.Rule{Frequency: recurrence.Daily, Interval: 2, Start: day("2026-01-30 09:00")},
+			want: []string{"2026-01-30 09:00", "2026-02-01 09:00", "2026-02-03 09:00"},
+		},
+		{
+			name: "weekly",
+			rule: recurrence.Rule{Frequency: recurrence.Weekly, Interval: 1, Start: day("2026-03-02 08:30")},
+			want: []string{"2026-03-02 08:30", "2026-03-09 08:30", "2026-03-16 08:30"},
+		},
+		{
+			name: "monthly on the 31st clamps short months",
+			rule: recurrence.Rule{Frequency: recurrence.Monthly, Interval: 1, Start: day("2026-01-31 00:00")},
+			want: []string{"2026-01-31 00:00", "2026-02-28 00:00", "2026-03-31 00:00", "2026-04-30 00:00"},
+		},
+		{
+			name: "monthly on day N before the start day begins next month",
+			rule: recurrence.Rule{Frequency: recurrence.Monthly, Interval: 1, DayOfMonth: 5, Start: day("2026-01-20 12:00")},
+			want: []string{"2026-02-05 12:00", "2026-03-05 12:00"},
+		},
+		{
+			name: "quarterly on day N",
+			rule: recurrence.Rule{Frequency: recurrence.Monthly, Interval: 3, DayOfMonth: 25, Start: day("2026-01-10 00:00")},
+			want: []string{"2026-01-25 00:00", "2026-04-25 00:00", "2026-07-25 00:00"},
+		},
+		{
+			name: "yearly on leap day",
+			rule: recurrence.Rule{Frequency: recurrence.Yearly, Interval: 1, Start: day("2028-02-29 00:00")},
+			want: []string{"2028-02-29 00:00", "2029-02-28 00:00", "2030-02-28 00:00"},
+		},
+		{
+			name: "every 4 years on leap day",
+			rule: recurrence.Rule{Frequency: recurrence.Yearly, Interval: 4, Start: day("2028-02-29 00:00")},
+			want: []string{"2028-02-29 00:00", "2032-02-29 00:00"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.rule.Validate(); err != nil {
+				t.Fatalf("Validate: %v", err)
+			}
+			for n, want := range tt.want {
+				if got := tt.rule.At(n); !got.Equal(day(want)) {
+					t.Errorf("At(%d) = %s; want %s", n, got.Format("2006-01-02 15:04"), want)
+				}
+			}
+		})
+	}
+}
+
+func TestRecurrence_Validate(t *testing.T) {
+	start := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		rule recurrence.Rule
+		want error
+	}{
+		{recurrence.Rule{Frequency: "hourly", Interval: 1, Start: start}, recurrence.ErrFrequency},
+		{recurrence.Rule{Frequency: recurrence.Daily, Interval: 0, Start: start}, recurrence.ErrInterval},
+		{recurrence.Rule{Frequency: recurrence.Monthly, Interval: 1, DayOfMonth: 32, Start: start}, recurrence.ErrDayOfMonth},
+		{recurrence.Rule{Frequency: recurrence.Weekly, Interval: 1, DayOfMonth: 3, Start: start}, recurrence.ErrDayOfMonth},
+	}
+	for _, tt := range tests {
+		if err := tt.rule.Validate(); err != tt.want {
+			t.Errorf("Validate(%+v) = %v; want %v", tt.rule, err, tt.want)
+		}
+	}
+}
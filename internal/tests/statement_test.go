@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"statistic_service/pkg/money"
+	"statistic_service/pkg/statement"
+)
+
+const ofxSGML = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>USD
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20260131120000.000[-5:EST]
+<TRNAMT>-42.10
+<FITID>2026013101
+<NAME>GROCERY STORE
+<MEMO>Card 1234
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20260201
+<TRNAMT>1500.00
+<FITID>2026020101
+<NAME>ACME PAYROLL
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const ofxXML = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220"?>
+<OFX><BANKMSGSRSV1><STMTTRNRS><STMTRS><CURDEF>EUR</CURDEF><BANKTRANLIST>
+<STMTTRN><TRNTYPE>DEBIT</TRNTYPE><DTPOSTED>20260305</DTPOSTED><TRNAMT>-9.99</TRNAMT><FITID>X1</FITID><NAME>Books &amp; More</NAME></STMTTRN>
+</BANKTRANLIST></STMTRS></STMTTRNRS></BANKMSGSRSV1></OFX>`
+
+const qifBank = `!Type:Bank
+D1/31'26
+T-1,234.50
+PLandlord
+MJanuary rent
+^
+D02/01/2026
+T2500
+PACME
+^
+D02/01/2026
+T2500
+PACME
+^
+`
+
+const camt053 = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.08">
+ <BkToCstmrStmt><Stmt>
+  <Ntry>
+   <NtryRef>1</NtryRef>
+   <Amt Ccy="EUR">12.30</Amt>
+   <CdtDbtInd>DBIT</CdtDbtInd>
+   <Sts><Cd>BOOK</Cd></Sts>
+   <BookgDt><Dt>2026-02-10</Dt></BookgDt>
+   <AcctSvcrRef>REF-001</AcctSvcrRef>
+   <NtryDtls><TxDtls><RmtInf><Ustrd>Coffee   shop</Ustrd></RmtInf></TxDtls></NtryDtls>
+  </Ntry>
+  <Ntry>
+   <Amt Ccy="EUR">100.00</Amt>
+   <CdtDbtInd>CRDT</CdtDbtInd>
+   <Sts><Cd>PDNG</Cd></Sts>
+   <BookgDt><Dt>2026-02-11</Dt></BookgDt>
+  </Ntry>
+  <Ntry>
+   <Amt Ccy="EUR">50.00</Amt>
+   <CdtDbtInd>CRDT</CdtDbtInd>
+   <Sts><Cd>BOOK</Cd></Sts>
+   <BookgDt><DtTm>2026-02-12T08:00:00+01:00</DtTm></BookgDt>
+   <AcctSvcrRef>NOTPROVIDED</AcctSvcrRef>
+   <NtryDtls><TxDtls><Refs><EndToEndId>E2E-9</EndToEndId></Refs></TxDtls></NtryDtls>
+   <AddtlNtryInf>Refund</AddtlNtryInf>
+  </Ntry>
+ </Stmt></BkToCstmrStmt>
+</Document>`
+
+func TestStatement_Detect(t *testing.T) {
+	for want, data := range map[string]string{
+		statement.OFX:     ofxSGML,
+		statement.QIF:     qifBank,
+		statement.CAMT053: camt053,
+	} {
+		if got := statement.Detect([]byte(data)); got != want {
+			t.Errorf("Detect = %q; want %q", got, want)
+		}
+	}
+	if got := statement.Detect([]byte(ofxXML)); got != statement.OFX {
+		t.Errorf("Detect OFX 2 = %q; want ofx", got)
+	}
+}
+
+func TestStatement_OFX(t *testing.T) {
+	entries, err := statement.Parse("", strings.NewReader(ofxSGML))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("want 2 entries; got %d", len(entries))
+	}
+	e := entries[0]
+	if e.ExternalID != "2026013101" || e.Amount != money.MustParse("-42.10") || e.Currency != "USD" || e.Memo != "GROCERY STORE Card 1234" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if want := time.Date(2026, 1, 31, 17, 0, 0, 0, time.UTC); !e.Date.Equal(want) {
+		t.Errorf("want date %s; got %s", want, e.Date)
+	}
+
+	entries, err = statement.Parse(statement.OFX, strings.NewReader(ofxXML))
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Parse OFX 2: %v, %d entries", err, len(entries))
+	}
+	if entries[0].Memo != "Books & More" || entries[0].Currency != "EUR" {
+		t.Errorf("unexpected OFX 2 entry: %+v", entries[0])
+	}
+}
+
+func TestStatement_QIF(t *testing.T) {
+	entries, err := statement.Parse(statement.QIF, strings.NewReader(qifBank))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(entries) != 3 {
+		t.Fatalf("want 3 entries; got %d", len(entries))
+	}
+	if e := entries[0]; e.Amount != money.MustParse("-1234.50") || e.Memo != "Landlord January rent" || !e.Date.Equal(time.Date(2026, 1, 31, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if entries[1].ExternalID == "" || entries[1].ExternalID == entries[2].ExternalID {
+		t.Errorf("want distinct synthetic IDs for identical entries; got %q and %q", entries[1].ExternalID, entries[2].ExternalID)
+	}
+}
+
+func TestStatement_CAMT053(t *testing.T) {
+	entries, err := statement.Parse("", strings.NewReader(camt053))
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("want 2 booked entries; got %d", len(entries))
+	}
+	if e := entries[0]; e.ExternalID != "REF-001" || e.Amount != money.MustParse("-12.30") || e.Memo != "Coffee shop" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+	if e := entries[1]; e.ExternalID != "E2E-9" || e.Amount != money.MustParse("50") || e.Memo != "Refund" {
+		t.Errorf("unexpected entry: %+v", e)
+	}
+}
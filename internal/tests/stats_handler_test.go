@@ -7,7 +7,9 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"statistic_service/internal/config"
 	"statistic_service/internal/handler"
@@ -16,6 +18,7 @@ import (
 	"statistic_service/internal/model"
 	"statistic_service/internal/repository"
 	"statistic_service/internal/service"
+	"statistic_service/pkg/money"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -29,10 +32,10 @@ func setupStatsDB(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("connect stats db: %v", err)
 	}
-	if err := db.AutoMigrate(&model.User{}, &model.Transaction{}); err != nil {
+	if err := db.AutoMigrate(&model.User{}, &model.RefreshToken{}, &model.RevokedSession{}, &model.Category{}, &model.Account{}, &model.RecurringTransaction{}, &model.Tag{}, &model.Transaction{}, &model.TransactionSplit{}, &model.Attachment{}, &model.TransactionVersion{}, &model.ExchangeRate{}, &model.Rule{}); err != nil {
 		t.Fatalf("migrate stats db: %v", err)
 	}
-	db.Exec("DELETE FROM transactions; DELETE FROM users;")
+	db.Exec("DELETE FROM transactions; DELETE FROM recurring_transactions; DELETE FROM accounts; DELETE FROM categories; DELETE FROM users; DELETE FROM exchange_rates;")
 	return db
 }
 
@@ -50,22 +53,33 @@ func setupStatsRouter(t *testing.T, db *gorm.DB, lg *logrus.Logger) *gin.Engine
 
 	userRepo := repository.NewUserRepository(db)
 	txRepo := repository.NewTransactionRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
 	authSvc := service.NewAuthService(userRepo, cfg.JWTSecret, lg)
-	txSvc := service.NewTransactionService(txRepo)
+	currencySvc := service.NewCurrencyService(repository.NewRateRepository(db), userRepo, txRepo)
+	txSvc := service.NewTransactionService(txRepo, categoryRepo, userRepo, currencySvc, repository.NewAccountRepository(db), repository.NewRuleRepository(db), repository.NewTagRepository(db), testStorage(t), 24*time.Hour)
+	categorySvc := service.NewCategoryService(categoryRepo)
 
 	authH := handler.NewAuthHandler(authSvc, lg)
 	txH := handler.NewTransactionHandler(txSvc, lg)
 	statsH := handler.NewStatsHandler(txSvc, lg)
+	categoryH := handler.NewCategoryHandler(categorySvc, lg)
+	currencyH := handler.NewCurrencyHandler(currencySvc, lg)
+	timelineH := handler.NewTimelineHandler(txSvc, lg)
 
 	r := gin.Default()
 	r.POST("/register", authH.Register)
 	r.POST("/login", authH.Login)
 
 	grp := r.Group("/")
-	grp.Use(middleware.JWTAuth(cfg.JWTSecret))
+	grp.Use(middleware.JWTAuth(cfg.JWTSecret, authSvc))
+	grp.PUT("/me/currency", currencyH.SetBaseCurrency)
+	grp.POST("/categories", categoryH.Create)
 	grp.POST("/transactions", txH.Create)
+	grp.GET("/transactions", txH.List)
+	grp.PUT("/transactions/:id", txH.Update)
 	grp.GET("/stats/summary", statsH.Summary)
 	grp.GET("/stats/categories", statsH.ByCategory)
+	grp.GET("/stats/timeline", timelineH.Timeline)
 
 	return r
 }
@@ -85,10 +99,22 @@ func TestStats_SummaryAndByCategory(t *testing.T) {
 	json.Unmarshal(w.Body.Bytes(), &lr)
 	token := lr["access_token"]
 
-	// 2) Создаём две транзакции
+	// 2) Создаём две категории и две транзакции
+	categoryIDs := map[string]string{}
+	for name, catType := range map[string]string{"X": "income", "Y": "expense"} {
+		b, _ := json.Marshal(map[string]string{"name": name, "type": catType})
+		req := httptest.NewRequest("POST", "/categories", bytes.NewBuffer(b))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var created model.Category
+		json.Unmarshal(w.Body.Bytes(), &created)
+		categoryIDs[name] = created.ID
+	}
 	for _, tx := range []map[string]interface{}{
-		{"amount": 10.0, "type": "income", "category": "X"},
-		{"amount": 3.0, "type": "expense", "category": "Y"},
+		{"amount": 10.0, "type": "income", "category_id": categoryIDs["X"]},
+		{"amount": 3.0, "type": "expense", "category_id": categoryIDs["Y"]},
 	} {
 		b, _ := json.Marshal(tx)
 		req := httptest.NewRequest("POST", "/transactions", bytes.NewBuffer(b))
@@ -105,9 +131,9 @@ func TestStats_SummaryAndByCategory(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Fatalf("want 200 summary; got %d", w.Code)
 	}
-	var sum map[string]float64
+	var sum map[string]money.Amount
 	json.Unmarshal(w.Body.Bytes(), &sum)
-	if sum["income"] != 10.0 || sum["expense"] != 3.0 {
+	if sum["income"] != money.MustParse("10") || sum["expense"] != money.MustParse("3") {
 		t.Errorf("unexpected summary: %+v", sum)
 	}
 
@@ -119,9 +145,165 @@ func TestStats_SummaryAndByCategory(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Fatalf("want 200 categories; got %d", w.Code)
 	}
-	var cats map[string]float64
-	json.Unmarshal(w.Body.Bytes(), &cats)
-	if cats["X"] == 10.0 || cats["Y"] == 3.0 {
-		t.Errorf("unexpected categories: %+v", cats)
+	var list []model.CategoryTotal
+	json.Unmarshal(w.Body.Bytes(), &list)
+	cats := map[string]money.Amount{}
+	for _, ct := range list {
+		cats[ct.Name] = ct.Total
+	}
+	if len(list) != 2 || cats["X"] != money.MustParse("10") || cats["Y"] != money.MustParse("3") {
+		t.Errorf("unexpected categories: %+v", list)
+	}
+}
+
+func TestStats_BaseCurrencyConversion(t *testing.T) {
+	db := setupStatsDB(t)
+	lg := setupStatsLogger(t)
+	router := setupStatsRouter(t, db, lg)
+
+	// Курсы ЕЦБ: единиц валюты за 1 EUR, действует последний
+	rates := `<?xml version="1.0" encoding="UTF-8"?>
+<gesmes:Envelope xmlns:gesmes="http://www.gesmes.org/xml/2002-08-01" xmlns="http://www.ecb.int/vocabulary/2002-08-01/eurofxref">
+	<Cube>
+		<Cube time="2024-01-03"><Cube currency="USD" rate="1.20"/></Cube>
+		<Cube time="2024-01-02"><Cube currency="USD" rate="1.10"/></Cube>
+	</Cube>
+</gesmes:Envelope>`
+	currencySvc := service.NewCurrencyService(repository.NewRateRepository(db), repository.NewUserRepository(db), repository.NewTransactionRepository(db))
+	if n, err := currencySvc.ImportRates(strings.NewReader(rates)); err != nil || n != 2 {
+		t.Fatalf("import rates: n=%d err=%v", n, err)
+	}
+
+	creds := map[string]string{"email": "fx@t.c", "password": "Password1!"}
+	jb, _ := json.Marshal(creds)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/register", bytes.NewBuffer(jb)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/login", bytes.NewBuffer(jb)))
+	var lr map[string]string
+	json.Unmarshal(w.Body.Bytes(), &lr)
+	token := lr["access_token"]
+
+	do := func(method, path string, body interface{}) *httptest.ResponseRecorder {
+		var buf bytes.Buffer
+		if body != nil {
+			b, _ := json.Marshal(body)
+			buf.Write(b)
+		}
+		req := httptest.NewRequest(method, path, &buf)
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	summary := func() map[string]money.Amount {
+		var sum map[string]money.Amount
+		json.Unmarshal(do("GET", "/stats/summary", nil).Body.Bytes(), &sum)
+		return sum
+	}
+
+	// 12 EUR + 5 USD при базовой валюте USD
+	do("POST", "/transactions", map[string]interface{}{"amount": 12.0, "currency": "EUR", "type": "expense"})
+	do("POST", "/transactions", map[string]interface{}{"amount": 5.0, "type": "expense"})
+	if w := do("POST", "/transactions", map[string]interface{}{"amount": 1.0, "currency": "GBP", "type": "expense"}); w.Code != http.StatusBadRequest {
+		t.Errorf("want 400 for currency without rates; got %d", w.Code)
+	}
+	if got := summary()["expense"]; got != money.MustParse("19.40") {
+		t.Errorf("want 19.40 USD expense; got %s", got)
+	}
+
+	// Смена базовой валюты на EUR: 12 + 5/1.2, каждая конвертация округляется до центов
+	if w := do("PUT", "/me/currency", map[string]string{"base_currency": "eur"}); w.Code != http.StatusOK {
+		t.Fatalf("want 200 set base currency; got %d", w.Code)
+	}
+	if got := summary()["expense"]; got != money.MustParse("16.17") {
+		t.Errorf("want 16.17 EUR expense; got %s", got)
+	}
+}
+
+func TestStats_SplitTransactions(t *testing.T) {
+	db := setupStatsDB(t)
+	lg := setupStatsLogger(t)
+	router := setupStatsRouter(t, db, lg)
+	do := categoryClient(router, "split@t.c")
+
+	var food, home, salary model.Category
+	json.Unmarshal(do("POST", "/categories", map[string]string{"name": "Food", "type": "expense"}).Body.Bytes(), &food)
+	json.Unmarshal(do("POST", "/categories", map[string]string{"name": "Home", "type": "expense"}).Body.Bytes(), &home)
+	json.Unmarshal(do("POST", "/categories", map[string]string{"name": "Salary", "type": "income"}).Body.Bytes(), &salary)
+
+	// 1. Неверные разбиения
+	bad := []map[string]interface{}{
+		{"amount": "10", "type": "expense", "splits": []map[string]interface{}{{"amount": "10", "category_id": food.ID}}},
+		{"amount": "10", "type": "expense", "splits": []map[string]interface{}{{"amount": "6", "category_id": food.ID}, {"amount": "3"}}},
+		{"amount": "10", "type": "expense", "category_id": food.ID, "splits": []map[string]interface{}{{"amount": "6"}, {"amount": "4"}}},
+		{"amount": "10", "type": "expense", "splits": []map[string]interface{}{{"amount": "6", "category_id": salary.ID}, {"amount": "4"}}},
+	}
+	for i, body := range bad {
+		if w := do("POST", "/transactions", body); w.Code != http.StatusBadRequest {
+			t.Errorf("case %d: want 400; got %d %s", i, w.Code, w.Body.String())
+		}
+	}
+
+	// 2. Чек из супермаркета: 60 еда, 40 дом
+	w := do("POST", "/transactions", map[string]interface{}{"amount": "100", "type": "expense", "comment": "supermarket", "splits": []map[string]interface{}{
+		{"amount": "60", "category_id": food.ID, "note": "groceries"},
+		{"amount": "40", "category_id": home.ID, "note": "detergent"},
+	}})
+	if w.Code != http.StatusCreated {
+		t.Fatalf("want 201; got %d %s", w.Code, w.Body.String())
+	}
+	var created map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &created)
+	id := created["id"].(string)
+	do("POST", "/transactions", map[string]interface{}{"amount": "15", "type": "expense", "category_id": food.ID})
+
+	totals := func() map[string]money.Amount {
+		var list []model.CategoryTotal
+		json.Unmarshal(do("GET", "/stats/categories", nil).Body.Bytes(), &list)
+		m := map[string]money.Amount{}
+		for _, c := range list {
+			m[c.Name] = c.Total
+		}
+		return m
+	}
+	if got := totals(); got["Food"] != money.MustParse("75") || got["Home"] != money.MustParse("40") || len(got) != 2 {
+		t.Errorf("unexpected category totals: %v", got)
+	}
+
+	// 3. Фильтр по категории находит разбитую транзакцию, график учитывает только долю категории
+	var page service.TransactionPage
+	json.Unmarshal(do("GET", "/transactions?category_id="+home.ID, nil).Body.Bytes(), &page)
+	if len(page.Items) != 1 || len(page.Items[0].Splits) != 2 || page.Items[0].Splits[0].Note != "groceries" {
+		t.Fatalf("want split transaction with lines in order; got %+v", page.Items)
+	}
+	var timeline map[string]money.Amount
+	json.Unmarshal(do("GET", "/stats/timeline?range=week&category_id="+home.ID, nil).Body.Bytes(), &timeline)
+	var sum money.Amount
+	for _, v := range timeline {
+		sum = sum.Add(v)
+	}
+	if sum != money.MustParse("40") {
+		t.Errorf("want 40 on Home timeline; got %s", sum)
+	}
+
+	// 4. Изменение суммы без новых строк нарушает разбиение; новые строки его меняют
+	if w := do("PUT", "/transactions/"+id, map[string]interface{}{"amount": "90", "type": "expense"}); w.Code != http.StatusBadRequest {
+		t.Errorf("want 400 for amount not matching splits; got %d", w.Code)
+	}
+	w = do("PUT", "/transactions/"+id, map[string]interface{}{"amount": "90", "type": "expense", "splits": []map[string]interface{}{
+		{"amount": "50", "category_id": food.ID}, {"amount": "40", "category_id": home.ID},
+	}})
+	if w.Code != http.StatusOK {
+		t.Fatalf("want 200 update; got %d %s", w.Code, w.Body.String())
+	}
+	if got := totals(); got["Food"] != money.MustParse("65") || got["Home"] != money.MustParse("40") {
+		t.Errorf("unexpected totals after update: %v", got)
+	}
+
+	// 5. Пустой список убирает разбиение
+	do("PUT", "/transactions/"+id, map[string]interface{}{"amount": "90", "type": "expense", "category_id": home.ID, "splits": []interface{}{}})
+	if got := totals(); got["Food"] != money.MustParse("15") || got["Home"] != money.MustParse("90") {
+		t.Errorf("unexpected totals after removing splits: %v", got)
 	}
 }
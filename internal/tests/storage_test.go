@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"statistic_service/pkg/storage"
+)
+
+// checkStorage stores, reads and deletes an object.
+func checkStorage(t *testing.T, s storage.Storage, key string) {
+	ctx := context.Background()
+	if err := s.Put(ctx, key, strings.NewReader("receipt"), 7, "image/png"); err != nil {
+		t.Fatalf("put: %v", err)
+	}
+	r, err := s.Get(ctx, key)
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	b, _ := io.ReadAll(r)
+	r.Close()
+	if string(b) != "receipt" {
+		t.Errorf("want receipt; got %q", b)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := s.Get(ctx, key); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("want ErrNotFound after delete; got %v", err)
+	}
+	if err := s.Delete(ctx, key); err != nil {
+		t.Errorf("want deleting a missing key to succeed; got %v", err)
+	}
+}
+
+func TestStorage_Local(t *testing.T) {
+	s, err := storage.NewLocal(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkStorage(t, s, "user/file")
+
+	// Ключи не выходят за пределы каталога, размер проверяется
+	for _, key := range []string{"../x", "/abs", "a//b", "a/./b", ""} {
+		if err := s.Put(context.Background(), key, strings.NewReader("x"), 1, ""); err == nil {
+			t.Errorf("want error for key %q", key)
+		}
+	}
+	if err := s.Put(context.Background(), "short", strings.NewReader("x"), 2, ""); err == nil {
+		t.Error("want error for size mismatch")
+	}
+	if _, err := s.Get(context.Background(), "short"); !errors.Is(err, storage.ErrNotFound) {
+		t.Errorf("want no partial object; got %v", err)
+	}
+}
+
+// fakeS3 is a minimal path-style S3 stand-in that requires signed requests.
+type fakeS3 struct {
+	mu      sync.Mutex
+	objects map[string]string
+}
+
+func (f *fakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "AWS4-HMAC-SHA256 Credential=key/") || !strings.Contains(auth, "/eu-central-1/s3/aws4_request") ||
+		!strings.Contains(auth, "SignedHeaders=host;x-amz-content-sha256;x-amz-date") || r.Header.Get("X-Amz-Date") == "" {
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, "<Error><Code>SignatureDoesNotMatch</Code></Error>")
+		return
+	}
+	if !strings.HasPrefix(r.URL.Path, "/bucket/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.Method {
+	case http.MethodPut:
+		if r.Header.Get("X-Amz-Content-Sha256") != "UNSIGNED-PAYLOAD" || r.ContentLength < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b, _ := io.ReadAll(r.Body)
+		f.objects[r.URL.Path] = string(b)
+	case http.MethodGet:
+		v, ok := f.objects[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			io.WriteString(w, "<Error><Code>NoSuchKey</Code></Error>")
+			return
+		}
+		io.WriteString(w, v)
+	case http.MethodDelete:
+		delete(f.objects, r.URL.Path)
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+func TestStorage_S3(t *testing.T) {
+	srv := httptest.NewServer(&fakeS3{objects: map[string]string{}})
+	defer srv.Close()
+
+	s, err := storage.NewS3(storage.S3Config{Endpoint: srv.URL, Bucket: "bucket", Region: "eu-central-1", AccessKey: "key", SecretKey: "secret"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkStorage(t, s, "user/file")
+
+	// Ошибка сервиса возвращается вместе с кодом
+	bad, _ := storage.NewS3(storage.S3Config{Endpoint: srv.URL, Bucket: "bucket", Region: "us-west-2", AccessKey: "other", SecretKey: "secret"})
+	err = bad.Put(context.Background(), "user/file", strings.NewReader("x"), 1, "")
+	if err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Errorf("want signature error; got %v", err)
+	}
+	if _, err := storage.NewS3(storage.S3Config{Endpoint: "localhost:9000", Bucket: "b", AccessKey: "k", SecretKey: "s"}); err == nil {
+		t.Error("want error for endpoint without scheme")
+	}
+}
+
+// TestStorage_MinIO runs against a real S3-compatible service, such as a
+// local MinIO, when S3_TEST_ENDPOINT is set. The bucket must exist.
+func TestStorage_MinIO(t *testing.T) {
+	endpoint := os.Getenv("S3_TEST_ENDPOINT")
+	if endpoint == "" {
+		t.Skip("S3_TEST_ENDPOINT is not set")
+	}
+	env := func(key, def string) string {
+		if v := os.Getenv(key); v != "" {
+			return v
+		}
+		return def
+	}
+	s, err := storage.NewS3(storage.S3Config{
+		Endpoint:  endpoint,
+		Bucket:    env("S3_TEST_BUCKET", "attachments"),
+		Region:    os.Getenv("S3_TEST_REGION"),
+		AccessKey: env("S3_TEST_ACCESS_KEY", "minioadmin"),
+		SecretKey: env("S3_TEST_SECRET_KEY", "minioadmin"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	checkStorage(t, s, "tests/receipt with spaces+plus.png")
+}
@@ -7,7 +7,9 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"statistic_service/internal/config"
 	"statistic_service/internal/handler"
@@ -16,6 +18,7 @@ import (
 	"statistic_service/internal/model"
 	"statistic_service/internal/repository"
 	"statistic_service/internal/service"
+	"statistic_service/pkg/money"
 
 	"github.com/gin-gonic/gin"
 	"github.com/sirupsen/logrus"
@@ -29,10 +32,10 @@ func setupTxDB(t *testing.T) *gorm.DB {
 	if err != nil {
 		t.Fatalf("connect tx test db: %v", err)
 	}
-	if err := db.AutoMigrate(&model.User{}, &model.Transaction{}); err != nil {
+	if err := db.AutoMigrate(&model.User{}, &model.RefreshToken{}, &model.RevokedSession{}, &model.Category{}, &model.Account{}, &model.RecurringTransaction{}, &model.Tag{}, &model.Transaction{}, &model.TransactionSplit{}, &model.Attachment{}, &model.TransactionVersion{}, &model.IdempotencyKey{}, &model.ExchangeRate{}, &model.Rule{}); err != nil {
 		t.Fatalf("migrate tx db: %v", err)
 	}
-	db.Exec("DELETE FROM transactions; DELETE FROM users;")
+	db.Exec("DELETE FROM idempotency_keys; DELETE FROM transactions; DELETE FROM recurring_transactions; DELETE FROM accounts; DELETE FROM categories; DELETE FROM users;")
 	return db
 }
 
@@ -50,21 +53,33 @@ func setupTxRouter(t *testing.T, db *gorm.DB, lg *logrus.Logger) *gin.Engine {
 
 	userRepo := repository.NewUserRepository(db)
 	txRepo := repository.NewTransactionRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
 	authSvc := service.NewAuthService(userRepo, cfg.JWTSecret, lg)
-	txSvc := service.NewTransactionService(txRepo)
+	currencySvc := service.NewCurrencyService(repository.NewRateRepository(db), userRepo, txRepo)
+	txSvc := service.NewTransactionService(txRepo, categoryRepo, userRepo, currencySvc, repository.NewAccountRepository(db), repository.NewRuleRepository(db), repository.NewTagRepository(db), testStorage(t), 24*time.Hour)
+
+	idempotencySvc := service.NewIdempotencyService(repository.NewIdempotencyRepository(db), time.Hour, lg)
 
 	authH := handler.NewAuthHandler(authSvc, lg)
 	txH := handler.NewTransactionHandler(txSvc, lg)
 
 	r := gin.Default()
+	r.Use(middleware.RequestID())
 	r.POST("/register", authH.Register)
 	r.POST("/login", authH.Login)
 
 	grp := r.Group("/")
-	grp.Use(middleware.JWTAuth(cfg.JWTSecret))
-	grp.POST("/transactions", txH.Create)
+	grp.Use(middleware.JWTAuth(cfg.JWTSecret, authSvc))
+	grp.POST("/transactions", middleware.Idempotency(idempotencySvc, lg), txH.Create)
 	grp.GET("/transactions", txH.List)
+	grp.PUT("/transactions/:id", txH.Update)
 	grp.DELETE("/transactions/:id", txH.Delete)
+	grp.GET("/transactions/:id/history", txH.History)
+	grp.GET("/transactions/trash", txH.Trash)
+	grp.POST("/transactions/:id/restore", txH.Restore)
+	grp.GET("/transactions/duplicates", txH.Duplicates)
+	grp.POST("/transactions/:id/merge", txH.Merge)
+	grp.DELETE("/transactions/:id/duplicate", txH.Dismiss)
 
 	return r
 }
@@ -117,10 +132,11 @@ func TestTransaction_CRUD(t *testing.T) {
 	if w.Code != http.StatusOK {
 		t.Fatalf("want 200 list; got %d", w.Code)
 	}
-	var list []model.Transaction
-	json.Unmarshal(w.Body.Bytes(), &list)
-	if len(list) != 1 || list[0].Amount != 42.5 {
-		t.Errorf("unexpected list: %+v", list)
+	var page service.TransactionPage
+	json.Unmarshal(w.Body.Bytes(), &page)
+	list := page.Items
+	if len(list) != 1 || list[0].Amount != money.MustParse("42.50") {
+		t.Fatalf("unexpected list: %+v", list)
 	}
 
 	// 5. Удаление
@@ -133,3 +149,369 @@ func TestTransaction_CRUD(t *testing.T) {
 		t.Errorf("want 204 delete; got %d", w.Code)
 	}
 }
+
+func TestTransaction_Pagination(t *testing.T) {
+	db := setupTxDB(t)
+	lg := setupTxLogger(t)
+	router := setupTxRouter(t, db, lg)
+
+	creds := map[string]string{"email": "p@g.n", "password": "Password1!"}
+	jb, _ := json.Marshal(creds)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/register", bytes.NewBuffer(jb)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/login", bytes.NewBuffer(jb)))
+	var lr map[string]string
+	json.Unmarshal(w.Body.Bytes(), &lr)
+	token := lr["access_token"]
+
+	// 5 транзакций, у двух в комментарии "coffee"
+	for i, comment := range []string{"coffee", "rent", "coffee beans", "taxi", "book"} {
+		tx := map[string]interface{}{"amount": float64(10 * (i + 1)), "type": "expense", "comment": comment}
+		tb, _ := json.Marshal(tx)
+		req := httptest.NewRequest("POST", "/transactions", bytes.NewBuffer(tb))
+		req.Header.Set("Authorization", "Bearer "+token)
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	list := func(query string) (int, service.TransactionPage) {
+		req := httptest.NewRequest("GET", "/transactions?"+query, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		var page service.TransactionPage
+		json.Unmarshal(w.Body.Bytes(), &page)
+		return w.Code, page
+	}
+
+	// Обходим все страницы по 2 элемента
+	seen := map[string]bool{}
+	cursor := ""
+	pages := 0
+	for {
+		code, page := list("limit=2&sort=asc&cursor=" + cursor)
+		if code != http.StatusOK {
+			t.Fatalf("want 200 page; got %d", code)
+		}
+		for _, tx := range page.Items {
+			if seen[tx.ID] {
+				t.Errorf("transaction %s returned twice", tx.ID)
+			}
+			seen[tx.ID] = true
+		}
+		pages++
+		if page.NextCursor == "" {
+			break
+		}
+		cursor = page.NextCursor
+	}
+	if len(seen) != 5 || pages != 3 {
+		t.Errorf("want 5 transactions on 3 pages; got %d on %d", len(seen), pages)
+	}
+
+	// Фильтры по сумме и тексту комментария
+	if _, page := list("amount_min=20&amount_max=40"); len(page.Items) != 3 {
+		t.Errorf("want 3 by amount; got %d", len(page.Items))
+	}
+	if _, page := list("q=COFFEE"); len(page.Items) != 2 {
+		t.Errorf("want 2 by comment; got %d", len(page.Items))
+	}
+
+	// Курсор от другого направления сортировки и превышение лимита
+	_, first := list("limit=2&sort=asc")
+	if code, _ := list("sort=desc&cursor=" + first.NextCursor); code != http.StatusBadRequest {
+		t.Errorf("want 400 for cursor of other direction; got %d", code)
+	}
+	if code, _ := list("limit=100000"); code != http.StatusBadRequest {
+		t.Errorf("want 400 for too large limit; got %d", code)
+	}
+}
+
+func TestTransaction_Duplicates(t *testing.T) {
+	db := setupTxDB(t)
+	lg := setupTxLogger(t)
+	router := setupTxRouter(t, db, lg)
+	do := categoryClient(router, "d@u.p")
+
+	create := func(query string, body map[string]interface{}) (int, map[string]interface{}) {
+		w := do("POST", "/transactions"+query, body)
+		var resp map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &resp)
+		return w.Code, resp
+	}
+
+	// 1. Исходная транзакция и похожая через час
+	at := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	code, first := create("", map[string]interface{}{"amount": "12.50", "type": "expense", "comment": "Lunch", "created_at": at})
+	if code != http.StatusCreated || first["duplicate_of"] != nil {
+		t.Fatalf("want 201 without flag; got %d %v", code, first)
+	}
+	dup := map[string]interface{}{"amount": "12.50", "type": "expense", "comment": "lunch!", "created_at": at.Add(time.Hour)}
+
+	// 2. reject → 409 с кандидатами
+	w := do("POST", "/transactions?on_duplicate=reject", dup)
+	var rejected struct {
+		Candidates []model.Transaction `json:"candidates"`
+	}
+	json.Unmarshal(w.Body.Bytes(), &rejected)
+	if w.Code != http.StatusConflict || len(rejected.Candidates) != 1 || rejected.Candidates[0].ID != first["id"] {
+		t.Fatalf("want 409 with the first transaction as candidate; got %d %s", w.Code, w.Body.String())
+	}
+
+	// 3. По умолчанию транзакция помечается
+	code, second := create("", dup)
+	if code != http.StatusCreated || second["duplicate_of"] != first["id"] {
+		t.Fatalf("want 201 flagged as duplicate; got %d %v", code, second)
+	}
+
+	// 4. Другая сумма, другой комментарий или вне окна — не дубликат
+	for _, body := range []map[string]interface{}{
+		{"amount": "13.00", "type": "expense", "comment": "Lunch", "created_at": at},
+		{"amount": "12.50", "type": "expense", "comment": "Taxi", "created_at": at},
+		{"amount": "12.50", "type": "expense", "comment": "Lunch", "created_at": at.Add(48 * time.Hour)},
+	} {
+		if code, resp := create("?on_duplicate=reject", body); code != http.StatusCreated {
+			t.Errorf("want 201 for %v; got %d %v", body, code, resp)
+		}
+	}
+	long := map[string]interface{}{"amount": "1", "type": "expense", "comment": strings.Repeat("a", service.MaxCommentLength+1)}
+	if code, _ := create("", long); code != http.StatusBadRequest {
+		t.Errorf("want 400 for too long comment; got %d", code)
+	}
+
+	// 5. Список помеченных пар
+	w = do("GET", "/transactions/duplicates", nil)
+	var pairs []service.DuplicatePair
+	json.Unmarshal(w.Body.Bytes(), &pairs)
+	if w.Code != http.StatusOK || len(pairs) != 1 || pairs[0].Original.ID != first["id"] {
+		t.Fatalf("unexpected duplicate pairs: %d %s", w.Code, w.Body.String())
+	}
+
+	// 6. Слияние переносит дубликат в корзину и оставляет исходную транзакцию
+	if w = do("POST", "/transactions/"+second["id"].(string)+"/merge", nil); w.Code != http.StatusOK {
+		t.Fatalf("want 200 merge; got %d %s", w.Code, w.Body.String())
+	}
+	if w = do("DELETE", "/transactions/"+second["id"].(string), nil); w.Code != http.StatusNotFound {
+		t.Errorf("want merged duplicate deleted; got %d", w.Code)
+	}
+	var trash []model.Transaction
+	json.Unmarshal(do("GET", "/transactions/trash", nil).Body.Bytes(), &trash)
+	if len(trash) != 1 || trash[0].ID != second["id"] {
+		t.Errorf("want merged duplicate in the trash; got %+v", trash)
+	}
+	if w = do("POST", "/transactions/"+first["id"].(string)+"/merge", nil); w.Code != http.StatusBadRequest {
+		t.Errorf("want 400 merge of unflagged transaction; got %d", w.Code)
+	}
+
+	// 7. Снятие пометки
+	_, third := create("", dup)
+	if w = do("DELETE", "/transactions/"+third["id"].(string)+"/duplicate", nil); w.Code != http.StatusNoContent {
+		t.Errorf("want 204 dismiss; got %d", w.Code)
+	}
+	w = do("GET", "/transactions/duplicates", nil)
+	json.Unmarshal(w.Body.Bytes(), &pairs)
+	if len(pairs) != 0 {
+		t.Errorf("want no duplicate pairs after dismiss; got %d", len(pairs))
+	}
+}
+
+func TestTransaction_Trash(t *testing.T) {
+	db := setupTxDB(t)
+	lg := setupTxLogger(t)
+	router := setupTxRouter(t, db, lg)
+	do := categoryClient(router, "trash@t.c")
+
+	listed := func(path string) []model.Transaction {
+		w := do("GET", path, nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("want 200 %s; got %d", path, w.Code)
+		}
+		if path == "/transactions/trash" {
+			var list []model.Transaction
+			json.Unmarshal(w.Body.Bytes(), &list)
+			return list
+		}
+		var page service.TransactionPage
+		json.Unmarshal(w.Body.Bytes(), &page)
+		return page.Items
+	}
+
+	// 1. Удалённая транзакция пропадает из списка и попадает в корзину
+	w := do("POST", "/transactions", map[string]interface{}{"amount": "10", "type": "expense", "comment": "coffee", "deleted_at": "2026-01-01T00:00:00Z"})
+	var tx model.Transaction
+	json.Unmarshal(w.Body.Bytes(), &tx)
+	if list := listed("/transactions"); len(list) != 1 {
+		t.Fatalf("want deleted_at ignored on create; got %d transactions", len(list))
+	}
+	if w = do("DELETE", "/transactions/"+tx.ID, nil); w.Code != http.StatusNoContent {
+		t.Fatalf("want 204 delete; got %d", w.Code)
+	}
+	if list := listed("/transactions"); len(list) != 0 {
+		t.Errorf("want deleted transaction hidden; got %d", len(list))
+	}
+	trash := listed("/transactions/trash")
+	if len(trash) != 1 || trash[0].ID != tx.ID || !trash[0].DeletedAt.Valid {
+		t.Fatalf("unexpected trash: %+v", trash)
+	}
+	if w = do("DELETE", "/transactions/"+tx.ID, nil); w.Code != http.StatusNotFound {
+		t.Errorf("want 404 deleting twice; got %d", w.Code)
+	}
+
+	// 2. Восстановление возвращает транзакцию в список
+	if w = do("POST", "/transactions/"+tx.ID+"/restore", nil); w.Code != http.StatusNoContent {
+		t.Fatalf("want 204 restore; got %d %s", w.Code, w.Body.String())
+	}
+	if list := listed("/transactions"); len(list) != 1 || list[0].ID != tx.ID {
+		t.Errorf("want restored transaction listed; got %+v", list)
+	}
+	if w = do("POST", "/transactions/"+tx.ID+"/restore", nil); w.Code != http.StatusNotFound {
+		t.Errorf("want 404 restoring a transaction not in the trash; got %d", w.Code)
+	}
+
+	// 3. Чужую транзакцию восстановить нельзя
+	do("DELETE", "/transactions/"+tx.ID, nil)
+	other := categoryClient(router, "other-trash@t.c")
+	if w = other("POST", "/transactions/"+tx.ID+"/restore", nil); w.Code != http.StatusNotFound {
+		t.Errorf("want 404 restoring another user's transaction; got %d", w.Code)
+	}
+	if list := listed("/transactions/trash"); len(list) != 1 {
+		t.Errorf("want transaction still in the trash; got %d", len(list))
+	}
+
+	// 4. Очистка удаляет транзакции из корзины навсегда
+	txRepo, userRepo := repository.NewTransactionRepository(db), repository.NewUserRepository(db)
+	currencySvc := service.NewCurrencyService(repository.NewRateRepository(db), userRepo, txRepo)
+	txSvc := service.NewTransactionService(txRepo, repository.NewCategoryRepository(db), userRepo, currencySvc, repository.NewAccountRepository(db), repository.NewRuleRepository(db), repository.NewTagRepository(db), testStorage(t), 24*time.Hour)
+	if n, err := txSvc.Purge(time.Now().Add(-time.Hour)); err != nil || n != 0 {
+		t.Errorf("want nothing purged before the retention; got %d, %v", n, err)
+	}
+	if n, err := txSvc.Purge(time.Now().Add(time.Minute)); err != nil || n != 1 {
+		t.Fatalf("want 1 purged; got %d, %v", n, err)
+	}
+	if list := listed("/transactions/trash"); len(list) != 0 {
+		t.Errorf("want empty trash after purge; got %d", len(list))
+	}
+}
+
+func TestTransaction_History(t *testing.T) {
+	db := setupTxDB(t)
+	lg := setupTxLogger(t)
+	router := setupTxRouter(t, db, lg)
+	do := categoryClient(router, "history@t.c")
+
+	// 1. Создание, изменение суммы и удаление
+	w := do("POST", "/transactions", map[string]interface{}{"amount": "10", "type": "expense", "comment": "coffee"})
+	createRequest := w.Header().Get("X-Request-ID")
+	var tx model.Transaction
+	json.Unmarshal(w.Body.Bytes(), &tx)
+	if w.Code != http.StatusCreated || createRequest == "" {
+		t.Fatalf("want 201 with request ID; got %d %q", w.Code, createRequest)
+	}
+	if w = do("PUT", "/transactions/"+tx.ID, map[string]interface{}{"amount": "12.50", "type": "expense", "comment": "coffee"}); w.Code != http.StatusOK {
+		t.Fatalf("want 200 update; got %d %s", w.Code, w.Body.String())
+	}
+	if w = do("DELETE", "/transactions/"+tx.ID, nil); w.Code != http.StatusNoContent {
+		t.Fatalf("want 204 delete; got %d", w.Code)
+	}
+
+	// 2. История хранит все версии с автором, запросом и изменениями
+	w = do("GET", "/transactions/"+tx.ID+"/history", nil)
+	var versions []model.TransactionVersion
+	json.Unmarshal(w.Body.Bytes(), &versions)
+	if w.Code != http.StatusOK || len(versions) != 3 {
+		t.Fatalf("want 3 versions; got %d %s", w.Code, w.Body.String())
+	}
+	created, updated, deleted := versions[0], versions[1], versions[2]
+	if created.Action != model.HistoryCreate || created.ActorID != tx.UserID || created.RequestID != createRequest {
+		t.Errorf("unexpected create version: %+v", created)
+	}
+	if string(created.Changes["amount"].Before) != "null" || string(created.Changes["amount"].After) != `"10.00"` {
+		t.Errorf("unexpected created amount: %+v", created.Changes["amount"])
+	}
+	if updated.Action != model.HistoryUpdate || len(updated.Changes) != 1 ||
+		string(updated.Changes["amount"].Before) != `"10.00"` || string(updated.Changes["amount"].After) != `"12.50"` {
+		t.Errorf("want only the amount changed; got %+v", updated)
+	}
+	if updated.RequestID == "" || updated.RequestID == createRequest {
+		t.Errorf("want a request ID per request; got %q", updated.RequestID)
+	}
+	if _, ok := deleted.Changes["deleted_at"]; deleted.Action != model.HistoryDelete || !ok {
+		t.Errorf("unexpected delete version: %+v", deleted)
+	}
+
+	// 3. Чужая история недоступна
+	other := categoryClient(router, "other-history@t.c")
+	if w = other("GET", "/transactions/"+tx.ID+"/history", nil); w.Code != http.StatusNotFound {
+		t.Errorf("want 404 for another user's history; got %d", w.Code)
+	}
+	if w = do("GET", "/transactions/not-a-uuid/history", nil); w.Code != http.StatusNotFound {
+		t.Errorf("want 404 for invalid id; got %d", w.Code)
+	}
+}
+
+func TestTransaction_IdempotencyKey(t *testing.T) {
+	db := setupTxDB(t)
+	lg := setupTxLogger(t)
+	router := setupTxRouter(t, db, lg)
+	do := categoryClient(router, "idem@t.c")
+
+	// Клиент с Idempotency-Key для пользователя email
+	client := func(email string) func(key string, body map[string]interface{}) *httptest.ResponseRecorder {
+		jb, _ := json.Marshal(map[string]string{"email": email, "password": "Password1!"})
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/register", bytes.NewBuffer(jb)))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest("POST", "/login", bytes.NewBuffer(jb)))
+		var lr map[string]string
+		json.Unmarshal(w.Body.Bytes(), &lr)
+		return func(key string, body map[string]interface{}) *httptest.ResponseRecorder {
+			b, _ := json.Marshal(body)
+			req := httptest.NewRequest("POST", "/transactions", bytes.NewBuffer(b))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("Authorization", "Bearer "+lr["access_token"])
+			req.Header.Set("Idempotency-Key", key)
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			return w
+		}
+	}
+	create := client("idem@t.c")
+	body := map[string]interface{}{"amount": "10", "type": "expense", "comment": "coffee"}
+
+	// 1. Повтор с тем же ключом возвращает исходный ответ и не создаёт дубль
+	first := create("retry-1", body)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("want 201; got %d %s", first.Code, first.Body.String())
+	}
+	retry := create("retry-1", body)
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() || retry.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("want replayed response; got %d %s", retry.Code, retry.Body.String())
+	}
+	w := do("GET", "/transactions", nil)
+	var page service.TransactionPage
+	json.Unmarshal(w.Body.Bytes(), &page)
+	if len(page.Items) != 1 {
+		t.Errorf("want one transaction after retry; got %d", len(page.Items))
+	}
+
+	// 2. Тот же ключ с другим телом — 422
+	if w := create("retry-1", map[string]interface{}{"amount": "20", "type": "expense"}); w.Code != http.StatusUnprocessableEntity {
+		t.Errorf("want 422 for a different payload; got %d", w.Code)
+	}
+
+	// 3. Ответы с ошибкой тоже сохраняются, новый ключ создаёт новую транзакцию
+	if w := create("invalid-1", map[string]interface{}{"amount": "-1", "type": "expense"}); w.Code != http.StatusBadRequest {
+		t.Fatalf("want 400; got %d", w.Code)
+	}
+	if w := create("invalid-1", map[string]interface{}{"amount": "-1", "type": "expense"}); w.Code != http.StatusBadRequest || w.Header().Get("Idempotent-Replayed") != "true" {
+		t.Errorf("want replayed 400; got %d", w.Code)
+	}
+	if w := create("retry-2", body); w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("want a new transaction for a new key; got %d", w.Code)
+	}
+
+	// 4. Ключи хранятся отдельно для каждого пользователя
+	other := client("other-idem@t.c")
+	if w := other("retry-1", map[string]interface{}{"amount": "20", "type": "expense"}); w.Code != http.StatusCreated || w.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("want another user's key to be independent; got %d", w.Code)
+	}
+}
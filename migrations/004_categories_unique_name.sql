@@ -0,0 +1,12 @@
+-- Categories created before names had to be unique keep their data: every
+-- repeated name but the oldest gets a number appended, e.g. "Food (2)".
+UPDATE categories c
+SET name = c.name || ' (' || d.n || ')'
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY user_id, lower(name) ORDER BY created_at, id) AS n
+    FROM categories
+) d
+WHERE c.id = d.id AND d.n > 1;
+
+CREATE UNIQUE INDEX IF NOT EXISTS categories_user_id_name_key
+    ON categories (user_id, lower(name));
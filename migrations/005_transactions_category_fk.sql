@@ -0,0 +1,57 @@
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS category_id UUID REFERENCES categories(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_category_id ON transactions (category_id);
+
+-- One category per user, case-insensitive trimmed name and type. Names stay
+-- unique per user, so when a name is used with both types only one type keeps
+-- it: the type of an existing category with that name, or else the more
+-- frequent one. The other type gets it with the type appended, e.g.
+-- "Food (income)".
+WITH used AS (
+    SELECT t.user_id, lower(btrim(t.category)) AS key, min(btrim(t.category)) AS name, t.type, count(*) AS n
+    FROM transactions t
+    WHERE btrim(coalesce(t.category, '')) <> ''
+    GROUP BY t.user_id, lower(btrim(t.category)), t.type
+), ranked AS (
+    SELECT u.*,
+           row_number() OVER (
+               PARTITION BY u.user_id, u.key
+               ORDER BY EXISTS (
+                   SELECT 1 FROM categories c
+                   WHERE c.user_id = u.user_id AND lower(c.name) = u.key AND c.type = u.type
+               ) DESC, u.n DESC, u.type
+           ) AS rank,
+           EXISTS (
+               SELECT 1 FROM categories c
+               WHERE c.user_id = u.user_id AND lower(c.name) = u.key AND c.type <> u.type
+           ) AS taken
+    FROM used u
+)
+INSERT INTO categories (user_id, name, type)
+SELECT r.user_id,
+       CASE WHEN r.rank = 1 AND NOT r.taken THEN r.name ELSE r.name || ' (' || r.type || ')' END,
+       r.type
+FROM ranked r
+WHERE NOT EXISTS (
+    SELECT 1 FROM categories c
+    WHERE c.user_id = r.user_id AND c.type = r.type
+      AND lower(c.name) IN (r.key, r.key || ' (' || r.type || ')')
+);
+
+UPDATE transactions t
+SET category_id = c.id
+FROM categories c
+WHERE t.category_id IS NULL
+  AND c.user_id = t.user_id
+  AND c.type = t.type
+  AND lower(c.name) IN (lower(btrim(t.category)), lower(btrim(t.category)) || ' (' || t.type || ')')
+  AND NOT EXISTS (
+      -- Prefer the plain name when the user has both.
+      SELECT 1 FROM categories p
+      WHERE p.user_id = t.user_id AND p.type = t.type
+        AND lower(p.name) = lower(btrim(t.category)) AND p.id <> c.id
+        AND lower(c.name) <> lower(btrim(t.category))
+  );
+
+ALTER TABLE transactions DROP COLUMN IF EXISTS category;
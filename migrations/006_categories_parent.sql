@@ -0,0 +1,9 @@
+ALTER TABLE categories
+    ADD COLUMN IF NOT EXISTS parent_id UUID REFERENCES categories(id);
+
+CREATE INDEX IF NOT EXISTS idx_categories_parent_id ON categories (parent_id);
+
+-- Names only need to be unique among siblings now.
+DROP INDEX IF EXISTS categories_user_id_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS categories_user_id_parent_id_name_key
+    ON categories (user_id, COALESCE(parent_id, '00000000-0000-0000-0000-000000000000'), lower(name));
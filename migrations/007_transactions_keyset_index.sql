@@ -0,0 +1,2 @@
+CREATE INDEX IF NOT EXISTS idx_transactions_user_created_id
+    ON transactions (user_id, created_at, id);
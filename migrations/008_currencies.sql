@@ -0,0 +1,13 @@
+ALTER TABLE users
+    ADD COLUMN IF NOT EXISTS base_currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS currency CHAR(3) NOT NULL DEFAULT 'USD';
+
+-- Units of currency per 1 EUR, as in the ECB reference rates.
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    currency CHAR(3) NOT NULL,
+    date DATE NOT NULL,
+    rate NUMERIC(20,10) NOT NULL CHECK (rate > 0),
+    PRIMARY KEY (currency, date)
+);
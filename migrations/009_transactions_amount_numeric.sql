@@ -0,0 +1,3 @@
+-- Databases created through AutoMigrate got a floating point amount column.
+ALTER TABLE transactions
+    ALTER COLUMN amount TYPE NUMERIC(14,2) USING round(amount::numeric, 2);
@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS accounts (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('cash', 'card', 'savings', 'credit')),
+    currency CHAR(3) NOT NULL,
+    opening_balance NUMERIC(14,2) NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_accounts_user_id ON accounts (user_id);
+
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS account_id UUID REFERENCES accounts(id),
+    ADD COLUMN IF NOT EXISTS transfer_id UUID;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_account_id ON transactions (account_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_transactions_transfer_id ON transactions (transfer_id);
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS recurring_transactions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    amount NUMERIC(14,2) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    type TEXT NOT NULL CHECK (type IN ('income', 'expense')),
+    category_id UUID REFERENCES categories(id) ON DELETE SET NULL,
+    account_id UUID REFERENCES accounts(id) ON DELETE SET NULL,
+    comment TEXT,
+    frequency TEXT NOT NULL CHECK (frequency IN ('daily', 'weekly', 'monthly', 'yearly')),
+    interval INTEGER NOT NULL DEFAULT 1 CHECK (interval >= 1),
+    day_of_month INTEGER NOT NULL DEFAULT 0 CHECK (day_of_month BETWEEN 0 AND 31),
+    start_at TIMESTAMPTZ NOT NULL,
+    end_at TIMESTAMPTZ,
+    count INTEGER NOT NULL DEFAULT 0,
+    occurrences INTEGER NOT NULL DEFAULT 0,
+    next_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_recurring_transactions_user_id ON recurring_transactions (user_id);
+CREATE INDEX IF NOT EXISTS idx_recurring_transactions_next_at ON recurring_transactions (next_at) WHERE next_at IS NOT NULL;
+
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS recurring_id UUID REFERENCES recurring_transactions(id) ON DELETE SET NULL;
+
+-- One transaction per occurrence, so the scheduler never materializes an
+-- occurrence twice, even when two instances race.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_recurring_occurrence
+    ON transactions (recurring_id, created_at) WHERE recurring_id IS NOT NULL;
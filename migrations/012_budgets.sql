@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS budgets (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    category_id UUID NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    period TEXT NOT NULL CHECK (period IN ('weekly', 'monthly')),
+    limit_amount NUMERIC(14,2) NOT NULL CHECK (limit_amount > 0),
+    rollover BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_budgets_user_id ON budgets (user_id);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_budgets_category_period ON budgets (category_id, period);
@@ -0,0 +1,6 @@
+ALTER TABLE transactions ADD COLUMN IF NOT EXISTS external_id TEXT;
+
+-- A bank reference is imported once per user and account.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_transactions_external_id
+    ON transactions (user_id, COALESCE(account_id, '00000000-0000-0000-0000-000000000000'::uuid), external_id)
+    WHERE external_id IS NOT NULL;
@@ -0,0 +1,7 @@
+ALTER TABLE transactions
+    ADD COLUMN IF NOT EXISTS duplicate_of UUID REFERENCES transactions(id) ON DELETE SET NULL;
+
+CREATE INDEX IF NOT EXISTS idx_transactions_duplicate_of ON transactions (duplicate_of) WHERE duplicate_of IS NOT NULL;
+
+-- Candidate lookup compares transactions of a user with the same amount close in time.
+CREATE INDEX IF NOT EXISTS idx_transactions_user_amount_created ON transactions (user_id, amount, created_at);
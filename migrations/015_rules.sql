@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS rules (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    comment_contains TEXT,
+    comment_pattern TEXT,
+    min_amount NUMERIC(14,2),
+    max_amount NUMERIC(14,2),
+    type TEXT CHECK (type IN ('', 'income', 'expense')),
+    account_id UUID REFERENCES accounts(id) ON DELETE CASCADE,
+    category_id UUID REFERENCES categories(id) ON DELETE CASCADE,
+    set_comment TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_rules_user_position ON rules (user_id, position);
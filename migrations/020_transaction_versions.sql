@@ -0,0 +1,25 @@
+CREATE TABLE IF NOT EXISTS transaction_versions (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    transaction_id UUID NOT NULL,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    actor_id UUID NOT NULL,
+    action TEXT NOT NULL,
+    changes JSONB NOT NULL,
+    request_id TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_versions_transaction_id ON transaction_versions (transaction_id, created_at);
+CREATE INDEX IF NOT EXISTS idx_transaction_versions_user_id ON transaction_versions (user_id);
+
+-- History is append-only: versions are never rewritten.
+CREATE OR REPLACE FUNCTION transaction_versions_immutable() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'transaction history is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS transaction_versions_no_update ON transaction_versions;
+CREATE TRIGGER transaction_versions_no_update
+    BEFORE UPDATE ON transaction_versions
+    FOR EACH ROW EXECUTE FUNCTION transaction_versions_immutable();
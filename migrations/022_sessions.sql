@@ -0,0 +1,12 @@
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS user_agent TEXT;
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS ip TEXT;
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens (user_id);
+
+-- Deny-list of logged out sessions, kept until their access tokens expire.
+CREATE TABLE IF NOT EXISTS revoked_sessions (
+    session_id TEXT PRIMARY KEY,
+    expires_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_revoked_sessions_expires_at ON revoked_sessions (expires_at);
@@ -0,0 +1,19 @@
+-- Refresh tokens rotate within a family; each session is one family.
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS family_id TEXT;
+UPDATE refresh_tokens SET family_id = id WHERE family_id IS NULL;
+ALTER TABLE refresh_tokens ALTER COLUMN family_id SET NOT NULL;
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS rotated_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens (family_id);
+
+CREATE TABLE IF NOT EXISTS security_events (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type TEXT NOT NULL,
+    session_id TEXT,
+    user_agent TEXT,
+    ip TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_security_events_user_id ON security_events (user_id);
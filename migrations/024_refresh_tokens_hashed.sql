@@ -0,0 +1,9 @@
+-- Refresh tokens are stored as a keyed hash. Stored plaintext tokens cannot
+-- be converted without the application key, so existing sessions are ended
+-- and their users log in again.
+DELETE FROM refresh_tokens;
+
+ALTER TABLE refresh_tokens DROP COLUMN IF EXISTS token;
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS token_hash TEXT NOT NULL;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens (token_hash);
@@ -0,0 +1,22 @@
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified BOOLEAN;
+
+CREATE TABLE IF NOT EXISTS email_verification_tokens (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash TEXT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email_verification_tokens_token_hash ON email_verification_tokens (token_hash);
+CREATE INDEX IF NOT EXISTS idx_email_verification_tokens_user_id ON email_verification_tokens (user_id);
+
+-- Accounts created before verification was required keep working as verified.
+-- They are the ones without a verification token: the column may already have
+-- been added as false by AutoMigrate when the service started first, so NULL
+-- does not identify them.
+UPDATE users u SET email_verified = true
+WHERE email_verified IS NOT TRUE
+  AND NOT EXISTS (SELECT 1 FROM email_verification_tokens t WHERE t.user_id = u.id);
+ALTER TABLE users ALTER COLUMN email_verified SET DEFAULT false;
+ALTER TABLE users ALTER COLUMN email_verified SET NOT NULL;
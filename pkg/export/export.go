@@ -0,0 +1,130 @@
+// Package export writes tabular reports as CSV, XLSX or newline-delimited
+// JSON, one row at a time, so large reports can be streamed.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Formats.
+const (
+	CSV    = "csv"
+	XLSX   = "xlsx"
+	NDJSON = "ndjson"
+)
+
+var ErrUnknownFormat = errors.New("format must be csv, xlsx or ndjson")
+
+// Column describes one column of a report. Numeric columns are written as
+// numbers where the format supports it.
+type Column struct {
+	Name    string
+	Numeric bool
+}
+
+// Writer writes the rows of one report. Close must be called to complete
+// the output.
+type Writer interface {
+	WriteRow(values []string) error
+	Close() error
+}
+
+// NewWriter returns a writer for the format. CSV and XLSX start with a
+// header row; NDJSON writes one object per row keyed by column name.
+func NewWriter(format string, w io.Writer, columns []Column) (Writer, error) {
+	switch format {
+	case CSV:
+		return newCSVWriter(w, columns)
+	case XLSX:
+		return newXLSXWriter(w, columns)
+	case NDJSON:
+		return &ndjsonWriter{enc: json.NewEncoder(w), columns: columns}, nil
+	}
+	return nil, ErrUnknownFormat
+}
+
+// ContentType returns the MIME type of the format.
+func ContentType(format string) string {
+	switch format {
+	case XLSX:
+		return "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	case NDJSON:
+		return "application/x-ndjson"
+	}
+	return "text/csv; charset=utf-8"
+}
+
+type csvWriter struct {
+	w       *csv.Writer
+	columns []Column
+}
+
+func newCSVWriter(w io.Writer, columns []Column) (*csvWriter, error) {
+	cw := &csvWriter{w: csv.NewWriter(w), columns: columns}
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.Name
+	}
+	return cw, cw.WriteRow(header)
+}
+
+func (w *csvWriter) WriteRow(values []string) error {
+	cells := make([]string, len(values))
+	for i, v := range values {
+		if isNumber(w.columns, i, v) {
+			cells[i] = v
+		} else {
+			cells[i] = escapeFormula(v)
+		}
+	}
+	return w.w.Write(cells)
+}
+
+func (w *csvWriter) Close() error {
+	w.w.Flush()
+	return w.w.Error()
+}
+
+type ndjsonWriter struct {
+	enc     *json.Encoder
+	columns []Column
+}
+
+// WriteRow writes numeric values as JSON strings, like amounts in the API.
+func (w *ndjsonWriter) WriteRow(values []string) error {
+	obj := make(map[string]string, len(w.columns))
+	for i, c := range w.columns {
+		if i < len(values) {
+			obj[c.Name] = values[i]
+		}
+	}
+	return w.enc.Encode(obj)
+}
+
+func (w *ndjsonWriter) Close() error {
+	return nil
+}
+
+// isNumber reports whether v is a number in a numeric column.
+func isNumber(columns []Column, i int, v string) bool {
+	if i >= len(columns) || !columns[i].Numeric {
+		return false
+	}
+	_, err := strconv.ParseFloat(v, 64)
+	return err == nil
+}
+
+// escapeFormula prefixes text that a spreadsheet would run as a formula when
+// opening a CSV file, such as a comment starting with "=", with an apostrophe.
+// XLSX text cells are never evaluated, so they are written unchanged.
+func escapeFormula(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
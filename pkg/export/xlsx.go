@@ -0,0 +1,107 @@
+package export
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// The static parts of a workbook with a single worksheet.
+const (
+	xlsxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types"><Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/><Default Extension="xml" ContentType="application/xml"/><Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/><Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/></Types>`
+	xlsxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/></Relationships>`
+	xlsxWorkbook = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Sheet1" sheetId="1" r:id="rId1"/></sheets></workbook>`
+	xlsxWorkbookRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/></Relationships>`
+	xlsxSheetStart = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`
+	xlsxSheetEnd = `</sheetData></worksheet>`
+)
+
+// xlsxWriter streams a minimal SpreadsheetML workbook. Rows are written
+// straight into the compressed worksheet, so memory use does not grow with
+// the number of rows. Text uses inline strings, so no shared string table
+// has to be kept.
+type xlsxWriter struct {
+	zw      *zip.Writer
+	sheet   *bufio.Writer
+	columns []Column
+}
+
+func newXLSXWriter(w io.Writer, columns []Column) (*xlsxWriter, error) {
+	zw := zip.NewWriter(w)
+	for _, part := range []struct{ name, body string }{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRels},
+		{"xl/workbook.xml", xlsxWorkbook},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+	} {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := io.WriteString(f, part.body); err != nil {
+			return nil, err
+		}
+	}
+	f, err := zw.Create("xl/worksheets/sheet1.xml")
+	if err != nil {
+		return nil, err
+	}
+	xw := &xlsxWriter{zw: zw, sheet: bufio.NewWriter(f), columns: columns}
+	xw.sheet.WriteString(xlsxSheetStart)
+
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.Name
+	}
+	return xw, xw.writeRow(header, false)
+}
+
+func (w *xlsxWriter) WriteRow(values []string) error {
+	return w.writeRow(values, true)
+}
+
+func (w *xlsxWriter) writeRow(values []string, typed bool) error {
+	w.sheet.WriteString("<row>")
+	for i, v := range values {
+		if typed && isNumber(w.columns, i, v) {
+			w.sheet.WriteString("<c><v>" + v + "</v></c>")
+			continue
+		}
+		if v == "" {
+			w.sheet.WriteString("<c/>")
+			continue
+		}
+		w.sheet.WriteString(`<c t="inlineStr"><is><t xml:space="preserve">`)
+		if err := xml.EscapeText(w.sheet, []byte(stripControl(v))); err != nil {
+			return err
+		}
+		w.sheet.WriteString("</t></is></c>")
+	}
+	_, err := w.sheet.WriteString("</row>")
+	return err
+}
+
+func (w *xlsxWriter) Close() error {
+	w.sheet.WriteString(xlsxSheetEnd)
+	if err := w.sheet.Flush(); err != nil {
+		return err
+	}
+	return w.zw.Close()
+}
+
+// stripControl drops characters XML 1.0 does not allow.
+func stripControl(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r < 0x20 && r != '\t' && r != '\n' && r != '\r' {
+			return -1
+		}
+		return r
+	}, s)
+}
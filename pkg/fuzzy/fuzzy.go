@@ -0,0 +1,86 @@
+// Package fuzzy compares short free-text strings such as transaction comments.
+package fuzzy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// maxRunes is how much of each normalized string Similarity compares, which
+// bounds the quadratic cost of the edit distance.
+const maxRunes = 256
+
+// minContained is the least length of a text Match finds inside another one,
+// so that a single letter or digit does not match every text containing it.
+const minContained = 3
+
+// Normalize lower-cases s and reduces it to words of letters and digits
+// separated by single spaces.
+func Normalize(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// Similarity returns how alike the normalized forms of a and b are, from 0
+// for nothing in common to 1 for equal. It is one minus the edit distance
+// divided by the length of the longer string. Only the first 256 characters
+// of each normalized string are compared.
+func Similarity(a, b string) float64 {
+	ra, rb := truncate([]rune(Normalize(a))), truncate([]rune(Normalize(b)))
+	longest := len(ra)
+	if len(rb) > longest {
+		longest = len(rb)
+	}
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+// Match reports whether a and b are likely the same text: their similarity
+// is at least threshold, or the words of one appear in a row in the other
+// and it has at least three characters.
+func Match(a, b string, threshold float64) bool {
+	na, nb := Normalize(a), Normalize(b)
+	if containsWords(na, nb) || containsWords(nb, na) {
+		return true
+	}
+	return Similarity(a, b) >= threshold
+}
+
+// containsWords reports whether the normalized text s contains the words of
+// the normalized text sub in a row.
+func containsWords(s, sub string) bool {
+	if len([]rune(sub)) < minContained {
+		return false
+	}
+	return strings.Contains(" "+s+" ", " "+sub+" ")
+}
+
+func truncate(r []rune) []rune {
+	if len(r) > maxRunes {
+		return r[:maxRunes]
+	}
+	return r
+}
+
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -1,23 +1,58 @@
 package jwt
 
 import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
-	"github.com/google/uuid"
 )
 
-// GenerateToken создает JWT с полем user_id в claims
-func GenerateToken(userID, secret string) (string, error) {
+// opaqueTokenSize is the number of random bytes in refresh and one-time
+// tokens.
+const opaqueTokenSize = 32
+
+// AccessTokenTTL is how long an access token is valid.
+const AccessTokenTTL = 24 * time.Hour
+
+// GenerateToken создает JWT с полями user_id и sid (ID сессии) в claims
+func GenerateToken(userID, sessionID, secret string) (string, error) {
 	claims := jwt.MapClaims{
 		"user_id": userID,
-		"exp":     time.Now().Add(24 * time.Hour).Unix(),
+		"sid":     sessionID,
+		"exp":     time.Now().Add(AccessTokenTTL).Unix(),
 	}
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 	return token.SignedString([]byte(secret))
 }
 
-// GenerateRefreshToken возвращает UUID для использования в качестве refresh token
+// GenerateRefreshToken возвращает случайный refresh token
 func GenerateRefreshToken() (string, error) {
-	return uuid.NewString(), nil
+	return randomToken()
+}
+
+// GenerateOneTimeToken возвращает случайный одноразовый токен для ссылок
+// из писем: сброса пароля и подтверждения email
+func GenerateOneTimeToken() (string, error) {
+	return randomToken()
+}
+
+// HashToken возвращает HMAC-SHA256 токена в hex; в базе хранится только
+// хеш, поэтому её дамп не содержит действующих токенов
+func HashToken(token, secret string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// randomToken возвращает 256 случайных бит в base64url
+func randomToken() (string, error) {
+	b := make([]byte, opaqueTokenSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
@@ -0,0 +1,43 @@
+package mail
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// File appends emails to a file instead of sending them, separated by a
+// line of dashes.
+type File struct {
+	mu   sync.Mutex
+	path string
+	from string
+}
+
+// NewFile creates the directory of path if needed.
+func NewFile(path, from string) (*File, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return nil, err
+	}
+	return &File{path: path, from: from}, nil
+}
+
+func (f *File) Send(_ context.Context, msg Message) error {
+	b, err := format(f.from, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		return err
+	}
+	_, err = file.Write(append(b, "----------\r\n"...))
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
@@ -0,0 +1,106 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTPConfig describes an SMTP server, e.g. a provider's relay or a local
+// MailHog at localhost:1025.
+type SMTPConfig struct {
+	Host string
+	Port int
+	// Username and Password enable PLAIN authentication, which net/smtp
+	// only allows over TLS or to localhost.
+	Username string
+	Password string
+	// From is the sender address of all emails.
+	From string
+}
+
+// SMTP sends emails through an SMTP server, using STARTTLS when the server
+// offers it.
+type SMTP struct {
+	cfg  SMTPConfig
+	from string
+}
+
+// NewSMTP checks the configuration. Port defaults to 587.
+func NewSMTP(cfg SMTPConfig) (*SMTP, error) {
+	if cfg.Host == "" {
+		return nil, errors.New("mail: SMTP host is required")
+	}
+	from, err := mail.ParseAddress(cfg.From)
+	if err != nil {
+		return nil, fmt.Errorf("mail: invalid sender %q", cfg.From)
+	}
+	if cfg.Port == 0 {
+		cfg.Port = 587
+	}
+	return &SMTP{cfg: cfg, from: from.Address}, nil
+}
+
+// Send delivers msg, giving up when ctx is done.
+func (s *SMTP) Send(ctx context.Context, msg Message) error {
+	to, err := mail.ParseAddress(msg.To)
+	if err != nil {
+		return fmt.Errorf("mail: invalid recipient %q", msg.To)
+	}
+	b, err := format(s.cfg.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(s.cfg.Host, strconv.Itoa(s.cfg.Port))
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	} else {
+		conn.SetDeadline(time.Now().Add(time.Minute))
+	}
+
+	c, err := smtp.NewClient(conn, s.cfg.Host)
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+	if ok, _ := c.Extension("STARTTLS"); ok {
+		if err := c.StartTLS(&tls.Config{ServerName: s.cfg.Host}); err != nil {
+			return err
+		}
+	}
+	if s.cfg.Username != "" {
+		if err := c.Auth(smtp.PlainAuth("", s.cfg.Username, s.cfg.Password, s.cfg.Host)); err != nil {
+			return err
+		}
+	}
+	if err := c.Mail(s.from); err != nil {
+		return err
+	}
+	if err := c.Rcpt(to.Address); err != nil {
+		return err
+	}
+	w, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(b); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return c.Quit()
+}
@@ -0,0 +1,220 @@
+// Package money provides an exact decimal amount with two fractional digits.
+//
+// Amounts are stored as integer minor units (cents), matching the NUMERIC(14,2)
+// columns in the database. Input is rejected if it has more than two decimal
+// places or does not fit into NUMERIC(14,2). Results of multiplication and
+// division are rounded half away from zero, the same rule PostgreSQL applies
+// in ROUND(numeric, 2), so values computed in Go and in SQL agree.
+package money
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Amount is a decimal value in minor units (1/100).
+type Amount int64
+
+// Scale is the number of fractional digits of an Amount.
+const Scale = 2
+
+// maxMinor is the largest magnitude NUMERIC(14,2) can hold, in minor units.
+const maxMinor = 99999999999999
+
+var (
+	ErrSyntax    = errors.New("amount must be a decimal number")
+	ErrPrecision = errors.New("amount must not have more than 2 decimal places")
+	ErrRange     = errors.New("amount is out of range")
+)
+
+// FromMinor returns the amount of the given number of minor units.
+func FromMinor(minor int64) Amount {
+	return Amount(minor)
+}
+
+// Minor returns the amount in minor units.
+func (a Amount) Minor() int64 {
+	return int64(a)
+}
+
+// Parse reads a plain decimal such as "12", "-0.5" or "1234.56".
+func Parse(s string) (Amount, error) {
+	return parse(s, false)
+}
+
+// MustParse is like Parse but panics on error. Intended for constants and tests.
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// parseRounded reads a decimal of any precision, rounding it to Scale digits.
+// It is used for values computed by the database.
+func parseRounded(s string) (Amount, error) {
+	return parse(s, true)
+}
+
+func parse(s string, round bool) (Amount, error) {
+	s = strings.TrimSpace(s)
+	neg := false
+	if strings.HasPrefix(s, "-") || strings.HasPrefix(s, "+") {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+	intPart, frac, _ := strings.Cut(s, ".")
+	if intPart == "" && frac == "" || !digitsOnly(intPart) || !digitsOnly(frac) {
+		return 0, ErrSyntax
+	}
+
+	roundUp := false
+	if len(frac) > Scale {
+		if !round {
+			if strings.Trim(frac[Scale:], "0") != "" {
+				return 0, ErrPrecision
+			}
+		} else {
+			roundUp = frac[Scale] >= '5'
+		}
+		frac = frac[:Scale]
+	}
+	frac += strings.Repeat("0", Scale-len(frac))
+
+	intPart = strings.TrimLeft(intPart, "0")
+	if len(intPart) > 12 {
+		return 0, ErrRange
+	}
+	minor, err := strconv.ParseInt(intPart+frac, 10, 64)
+	if err != nil {
+		return 0, ErrSyntax
+	}
+	if roundUp {
+		minor++
+	}
+	if minor > maxMinor {
+		return 0, ErrRange
+	}
+	if neg {
+		minor = -minor
+	}
+	return Amount(minor), nil
+}
+
+func digitsOnly(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formats the amount with exactly two decimal places.
+func (a Amount) String() string {
+	minor := int64(a)
+	sign := ""
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, minor/100, minor%100)
+}
+
+func (a Amount) Add(b Amount) Amount { return a + b }
+func (a Amount) Sub(b Amount) Amount { return a - b }
+func (a Amount) Neg() Amount         { return -a }
+func (a Amount) IsZero() bool        { return a == 0 }
+func (a Amount) IsNegative() bool    { return a < 0 }
+func (a Amount) IsPositive() bool    { return a > 0 }
+
+// MulFrac returns a * num / den rounded half away from zero.
+// It panics if den is zero.
+func (a Amount) MulFrac(num, den int64) Amount {
+	if den == 0 {
+		panic("money: division by zero")
+	}
+	n := new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(num))
+	d := big.NewInt(den)
+	if d.Sign() < 0 {
+		n.Neg(n)
+		d.Neg(d)
+	}
+	q, r := new(big.Int).QuoRem(n, d, new(big.Int))
+	// |2r| >= d means the remainder is at least one half.
+	if new(big.Int).Abs(new(big.Int).Lsh(r, 1)).Cmp(d) >= 0 {
+		if n.Sign() < 0 {
+			q.Sub(q, big.NewInt(1))
+		} else {
+			q.Add(q, big.NewInt(1))
+		}
+	}
+	return Amount(q.Int64())
+}
+
+// MarshalJSON encodes the amount as a decimal string, e.g. "12.30".
+func (a Amount) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + a.String() + `"`), nil
+}
+
+// UnmarshalJSON accepts a decimal string or a JSON number. The literal is
+// parsed as text, so numbers never pass through float64.
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	s := string(b)
+	if s == "null" {
+		return nil
+	}
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = s[1 : len(s)-1]
+	}
+	if strings.ContainsAny(s, "eE") {
+		return ErrSyntax
+	}
+	v, err := Parse(s)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// Value stores the amount as a decimal string for NUMERIC columns.
+func (a Amount) Value() (driver.Value, error) {
+	return a.String(), nil
+}
+
+// Scan reads NUMERIC values. Values with more than two decimals, such as
+// aggregates of converted amounts, are rounded half away from zero.
+func (a *Amount) Scan(src interface{}) error {
+	var v Amount
+	var err error
+	switch x := src.(type) {
+	case nil:
+		v = 0
+	case string:
+		v, err = parseRounded(x)
+	case []byte:
+		v, err = parseRounded(string(x))
+	case int64:
+		v = Amount(x * 100)
+	case float64:
+		v, err = parseRounded(strconv.FormatFloat(x, 'f', -1, 64))
+	default:
+		return fmt.Errorf("money: cannot scan %T", src)
+	}
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// GormDataType makes gorm create amount columns as NUMERIC(14,2).
+func (Amount) GormDataType() string {
+	return "numeric(14,2)"
+}
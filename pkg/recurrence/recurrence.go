@@ -0,0 +1,86 @@
+// Package recurrence computes the occurrences of repeating schedules such as
+// "every month on day 25" or "every 2 weeks".
+package recurrence
+
+import (
+	"errors"
+	"time"
+)
+
+// Frequencies.
+const (
+	Daily   = "daily"
+	Weekly  = "weekly"
+	Monthly = "monthly"
+	Yearly  = "yearly"
+)
+
+var (
+	ErrFrequency  = errors.New("frequency must be daily, weekly, monthly or yearly")
+	ErrInterval   = errors.New("interval must be at least 1")
+	ErrDayOfMonth = errors.New("day_of_month must be between 1 and 31 and is only allowed for monthly schedules")
+)
+
+// Rule is a repeating schedule. Occurrences are evaluated in UTC and keep the
+// time of day of Start.
+type Rule struct {
+	Frequency string
+	// Interval repeats the rule every Interval days, weeks, months or years.
+	Interval int
+	// DayOfMonth pins monthly occurrences to a day of the month. Zero uses the
+	// day of Start. Months that are too short use their last day.
+	DayOfMonth int
+	Start      time.Time
+}
+
+// Validate checks the rule is well-formed.
+func (r Rule) Validate() error {
+	switch r.Frequency {
+	case Daily, Weekly, Monthly, Yearly:
+	default:
+		return ErrFrequency
+	}
+	if r.Interval < 1 {
+		return ErrInterval
+	}
+	if r.DayOfMonth < 0 || r.DayOfMonth > 31 || (r.DayOfMonth != 0 && r.Frequency != Monthly) {
+		return ErrDayOfMonth
+	}
+	return nil
+}
+
+// At returns the n-th occurrence of the rule, counting from zero. The first
+// occurrence is the earliest one at or after Start. Each occurrence is
+// computed from Start, so clamped short months do not drift later ones.
+func (r Rule) At(n int) time.Time {
+	start := r.Start.UTC()
+	step := n * r.Interval
+	switch r.Frequency {
+	case Daily:
+		return start.AddDate(0, 0, step)
+	case Weekly:
+		return start.AddDate(0, 0, 7*step)
+	case Yearly:
+		return monthDay(start, start.Year(), start.Month()+time.Month(12*step), start.Day())
+	default:
+		day := r.DayOfMonth
+		if day == 0 {
+			day = start.Day()
+		}
+		offset := 0
+		if monthDay(start, start.Year(), start.Month(), day).Before(start) {
+			offset = 1
+		}
+		return monthDay(start, start.Year(), start.Month()+time.Month(offset+step), day)
+	}
+}
+
+// monthDay returns the given day of the month, or the last day of a shorter
+// month, at the time of day of clock.
+func monthDay(clock time.Time, year int, month time.Month, day int) time.Time {
+	first := time.Date(year, month, 1, 0, 0, 0, 0, time.UTC)
+	if last := first.AddDate(0, 1, -1).Day(); day > last {
+		day = last
+	}
+	return time.Date(first.Year(), first.Month(), day, clock.Hour(), clock.Minute(), clock.Second(), clock.Nanosecond(), time.UTC)
+}
@@ -0,0 +1,18 @@
+// Package requestid carries the ID of an API request through a context, so
+// changes made while serving the request can be traced back to it.
+package requestid
+
+import "context"
+
+type contextKey struct{}
+
+// NewContext returns a copy of ctx carrying the request ID id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext returns the request ID carried by ctx, or "" outside a request.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
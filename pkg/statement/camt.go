@@ -0,0 +1,126 @@
+package statement
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// camtDocument is the part of an ISO 20022 camt.053 bank-to-customer
+// statement that is imported. Element names match any namespace version.
+type camtDocument struct {
+	Statements []struct {
+		Entries []camtEntry `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtEntry struct {
+	Amount struct {
+		Value    string `xml:",chardata"`
+		Currency string `xml:"Ccy,attr"`
+	} `xml:"Amt"`
+	CreditDebit string `xml:"CdtDbtInd"`
+	Status      struct {
+		Value string `xml:",chardata"`
+		Code  string `xml:"Cd"`
+	} `xml:"Sts"`
+	BookingDate struct {
+		Date     string `xml:"Dt"`
+		DateTime string `xml:"DtTm"`
+	} `xml:"BookgDt"`
+	AcctSvcrRef string `xml:"AcctSvcrRef"`
+	NtryRef     string `xml:"NtryRef"`
+	Details     []struct {
+		Refs struct {
+			AcctSvcrRef string `xml:"AcctSvcrRef"`
+			EndToEndID  string `xml:"EndToEndId"`
+		} `xml:"Refs"`
+		Unstructured []string `xml:"RmtInf>Ustrd"`
+	} `xml:"NtryDtls>TxDtls"`
+	AdditionalInfo string `xml:"AddtlNtryInf"`
+}
+
+// parseCAMT053 reads booked entries of camt.053 statements. Pending entries
+// are skipped.
+func parseCAMT053(data []byte) ([]Entry, error) {
+	var doc camtDocument
+	if err := xml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("camt.053: %v", err)
+	}
+	var entries []Entry
+	for _, stmt := range doc.Statements {
+		for _, n := range stmt.Entries {
+			status := strings.TrimSpace(n.Status.Value)
+			if n.Status.Code != "" {
+				status = n.Status.Code
+			}
+			if status != "" && status != "BOOK" {
+				continue
+			}
+			e, err := camtToEntry(n)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, e)
+		}
+	}
+	syntheticIDs(entries)
+	return entries, nil
+}
+
+func camtToEntry(n camtEntry) (Entry, error) {
+	amount, err := parseAmount(n.Amount.Value)
+	if err != nil {
+		return Entry{}, fmt.Errorf("camt.053: invalid amount %q", n.Amount.Value)
+	}
+	switch n.CreditDebit {
+	case "DBIT":
+		amount = amount.Neg()
+	case "CRDT":
+	default:
+		return Entry{}, fmt.Errorf("camt.053: invalid CdtDbtInd %q", n.CreditDebit)
+	}
+
+	var date time.Time
+	if n.BookingDate.DateTime != "" {
+		date, err = time.Parse(time.RFC3339, n.BookingDate.DateTime)
+		if err != nil {
+			date, err = time.Parse("2006-01-02T15:04:05", n.BookingDate.DateTime)
+		}
+	} else {
+		date, err = time.Parse("2006-01-02", n.BookingDate.Date)
+	}
+	if err != nil {
+		return Entry{}, fmt.Errorf("camt.053: invalid booking date")
+	}
+
+	ref := firstRef(n.AcctSvcrRef, n.NtryRef)
+	var memo []string
+	for _, d := range n.Details {
+		if ref == "" {
+			ref = firstRef(d.Refs.AcctSvcrRef, d.Refs.EndToEndID)
+		}
+		memo = append(memo, d.Unstructured...)
+	}
+	if len(memo) == 0 && n.AdditionalInfo != "" {
+		memo = append(memo, n.AdditionalInfo)
+	}
+	return Entry{
+		ExternalID: ref,
+		Date:       date,
+		Amount:     amount,
+		Currency:   strings.ToUpper(n.Amount.Currency),
+		Memo:       strings.Join(strings.Fields(strings.Join(memo, " ")), " "),
+	}, nil
+}
+
+// firstRef returns the first usable reference; NOTPROVIDED is a placeholder.
+func firstRef(refs ...string) string {
+	for _, r := range refs {
+		if r = strings.TrimSpace(r); r != "" && r != "NOTPROVIDED" {
+			return r
+		}
+	}
+	return ""
+}
@@ -0,0 +1,127 @@
+package statement
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseOFX reads OFX 1.x (SGML, leaf elements without end tags) and OFX 2.x
+// (XML) statements. Every STMTTRN aggregate becomes an entry in the currency
+// of the enclosing statement.
+func parseOFX(data []byte) ([]Entry, error) {
+	s := string(data)
+	start := strings.Index(strings.ToUpper(s), "<OFX>")
+	if start < 0 {
+		return nil, errors.New("ofx: missing OFX element")
+	}
+	s = s[start:]
+
+	var (
+		entries  []Entry
+		current  map[string]string
+		currency string
+	)
+	for len(s) > 0 {
+		lt := strings.IndexByte(s, '<')
+		if lt < 0 {
+			break
+		}
+		gt := strings.IndexByte(s[lt:], '>')
+		if gt < 0 {
+			return nil, errors.New("ofx: unterminated tag")
+		}
+		tag := strings.ToUpper(strings.TrimSpace(s[lt+1 : lt+gt]))
+		s = s[lt+gt+1:]
+		value := s
+		if next := strings.IndexByte(s, '<'); next >= 0 {
+			value = s[:next]
+		}
+		value = strings.TrimSpace(value)
+
+		switch {
+		case strings.HasPrefix(tag, "?") || strings.HasPrefix(tag, "!"):
+		case tag == "STMTTRN":
+			current = map[string]string{}
+		case tag == "/STMTTRN":
+			if current == nil {
+				continue
+			}
+			e, err := ofxEntry(current, currency)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, e)
+			current = nil
+		case tag == "CURDEF":
+			currency = strings.ToUpper(value)
+		case current != nil && !strings.HasPrefix(tag, "/") && value != "":
+			current[tag] = value
+		}
+	}
+	syntheticIDs(entries)
+	return entries, nil
+}
+
+func ofxEntry(fields map[string]string, currency string) (Entry, error) {
+	date, err := parseOFXDate(fields["DTPOSTED"])
+	if err != nil {
+		return Entry{}, fmt.Errorf("ofx: transaction %s: invalid DTPOSTED %q", fields["FITID"], fields["DTPOSTED"])
+	}
+	amount, err := parseAmount(fields["TRNAMT"])
+	if err != nil {
+		return Entry{}, fmt.Errorf("ofx: transaction %s: invalid TRNAMT %q", fields["FITID"], fields["TRNAMT"])
+	}
+	if c := fields["CURRENCY"]; c != "" {
+		currency = c
+	}
+	memo := fields["MEMO"]
+	if memo == "" {
+		memo = fields["NAME"]
+	} else if name := fields["NAME"]; name != "" && !strings.Contains(memo, name) {
+		memo = name + " " + memo
+	}
+	return Entry{
+		ExternalID: fields["FITID"],
+		Date:       date,
+		Amount:     amount,
+		Currency:   strings.ToUpper(currency),
+		Memo:       unescapeSGML(memo),
+	}, nil
+}
+
+// parseOFXDate parses YYYYMMDD[HHMMSS[.XXX]][[offset:TZ]], for example
+// 20260131120000.000[-5:EST]. Without an offset the time is UTC.
+func parseOFXDate(s string) (time.Time, error) {
+	loc := time.UTC
+	if i := strings.IndexByte(s, '['); i >= 0 {
+		tz := strings.TrimSuffix(s[i+1:], "]")
+		if j := strings.IndexByte(tz, ':'); j >= 0 {
+			tz = tz[:j]
+		}
+		hours, err := strconv.ParseFloat(tz, 64)
+		if err != nil {
+			return time.Time{}, err
+		}
+		loc = time.FixedZone("", int(hours*3600))
+		s = s[:i]
+	}
+	if i := strings.IndexByte(s, '.'); i >= 0 {
+		s = s[:i]
+	}
+	switch len(s) {
+	case 8:
+		return time.ParseInLocation("20060102", s, loc)
+	case 12:
+		return time.ParseInLocation("200601021504", s, loc)
+	case 14:
+		return time.ParseInLocation("20060102150405", s, loc)
+	}
+	return time.Time{}, errors.New("invalid date")
+}
+
+func unescapeSGML(s string) string {
+	return strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">", "&quot;", `"`, "&apos;", "'").Replace(s)
+}
@@ -0,0 +1,119 @@
+package statement
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseQIF reads the bank and cash sections of a QIF file. QIF has no entry
+// references, so entries get synthetic IDs, and no currency.
+func parseQIF(data []byte) ([]Entry, error) {
+	var (
+		entries []Entry
+		fields  = map[byte]string{}
+		line    int
+		inBank  = true
+	)
+	sc := bufio.NewScanner(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	for sc.Scan() {
+		line++
+		text := strings.TrimRight(sc.Text(), "\r")
+		if text == "" {
+			continue
+		}
+		if strings.HasPrefix(text, "!") {
+			kind := strings.ToLower(strings.TrimSpace(text))
+			inBank = kind == "!type:bank" || kind == "!type:cash" || kind == "!type:ccard"
+			continue
+		}
+		if text[0] == '^' {
+			if inBank && len(fields) > 0 {
+				e, err := qifEntry(fields)
+				if err != nil {
+					return nil, fmt.Errorf("qif: record ending on line %d: %v", line, err)
+				}
+				entries = append(entries, e)
+			}
+			fields = map[byte]string{}
+			continue
+		}
+		fields[text[0]] = strings.TrimSpace(text[1:])
+	}
+	if err := sc.Err(); err != nil {
+		return nil, err
+	}
+	syntheticIDs(entries)
+	return entries, nil
+}
+
+func qifEntry(fields map[byte]string) (Entry, error) {
+	date, err := parseQIFDate(fields['D'])
+	if err != nil {
+		return Entry{}, fmt.Errorf("invalid date %q", fields['D'])
+	}
+	raw := fields['T']
+	if raw == "" {
+		raw = fields['U']
+	}
+	amount, err := parseAmount(raw)
+	if err != nil {
+		return Entry{}, fmt.Errorf("invalid amount %q", raw)
+	}
+	memo := fields['M']
+	if payee := fields['P']; payee != "" {
+		if memo == "" {
+			memo = payee
+		} else if !strings.Contains(memo, payee) {
+			memo = payee + " " + memo
+		}
+	}
+	return Entry{Date: date, Amount: amount, Memo: memo}, nil
+}
+
+// parseQIFDate parses the usual QIF date spellings: M/D/YY, M/D'YY, M/D/YYYY
+// and, with dots, D.M.YYYY.
+func parseQIFDate(s string) (time.Time, error) {
+	s = strings.ReplaceAll(strings.TrimSpace(s), "'", "/")
+	sep := "/"
+	dayFirst := false
+	switch {
+	case strings.Contains(s, "."):
+		sep, dayFirst = ".", true
+	case strings.Contains(s, "-"):
+		sep = "-"
+	}
+	parts := strings.Split(s, sep)
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("invalid date")
+	}
+	n := make([]int, 3)
+	for i, p := range parts {
+		v, err := strconv.Atoi(strings.TrimSpace(p))
+		if err != nil {
+			return time.Time{}, err
+		}
+		n[i] = v
+	}
+	month, day, year := n[0], n[1], n[2]
+	if dayFirst {
+		day, month = n[0], n[1]
+	}
+	if sep == "-" && n[0] > 31 {
+		year, month, day = n[0], n[1], n[2]
+	}
+	if year < 100 {
+		year += 2000
+		if year > time.Now().Year()+1 {
+			year -= 100
+		}
+	}
+	t := time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+	if t.Day() != day || int(t.Month()) != month {
+		return time.Time{}, fmt.Errorf("invalid date")
+	}
+	return t, nil
+}
@@ -0,0 +1,37 @@
+// Package storage keeps binary objects such as uploaded receipts outside the
+// database, on the local filesystem or in an S3-compatible bucket.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrNotFound is returned by Get for a key that does not exist.
+var ErrNotFound = errors.New("object not found")
+
+// Storage stores objects under slash-separated keys. Deleting a key that
+// does not exist is not an error.
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// validKey reports whether key is a relative path without empty, "." or
+// ".." segments, so it cannot escape the storage root.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, `\`) {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+var errInvalidKey = errors.New("invalid storage key")
@@ -10,8 +10,12 @@ type ErrorType string
 
 const (
 	ErrValidation          ErrorType = "validation_error"
+	ErrNotFound            ErrorType = "not_found"
+	ErrConflict            ErrorType = "conflict"
+	ErrTooManyRequests     ErrorType = "too_many_requests"
 	ErrRefreshTokenExpired ErrorType = "refresh_token_expired"
 	ErrInvalidRefreshToken ErrorType = "invalid_refresh_token"
+	ErrRefreshTokenReused  ErrorType = "refresh_token_reused"
 )
 
 type AppError struct {
@@ -23,6 +27,34 @@ func (e *AppError) Error() string {
 	return e.Message
 }
 
+func NewValidation(message string) *AppError {
+	return &AppError{
+		Type:    ErrValidation,
+		Message: message,
+	}
+}
+
+func NewNotFound(message string) *AppError {
+	return &AppError{
+		Type:    ErrNotFound,
+		Message: message,
+	}
+}
+
+func NewConflict(message string) *AppError {
+	return &AppError{
+		Type:    ErrConflict,
+		Message: message,
+	}
+}
+
+func NewTooManyRequests(message string) *AppError {
+	return &AppError{
+		Type:    ErrTooManyRequests,
+		Message: message,
+	}
+}
+
 func NewRefreshTokenExpired() *AppError {
 	return &AppError{
 		Type:    ErrRefreshTokenExpired,
@@ -37,6 +69,13 @@ func NewInvalidRefreshToken() *AppError {
 	}
 }
 
+func NewRefreshTokenReused() *AppError {
+	return &AppError{
+		Type:    ErrRefreshTokenReused,
+		Message: "refresh token was already used, session revoked",
+	}
+}
+
 func CustomValidationErrors(errs validator.ValidationErrors) []string {
 	var messages []string
 	for _, err := range errs {
@@ -47,9 +86,13 @@ func CustomValidationErrors(errs validator.ValidationErrors) []string {
 			messages = append(messages, fmt.Sprintf("%s must be a valid email address", err.Field()))
 		case "min":
 			messages = append(messages, fmt.Sprintf("%s must be at least %s characters long", err.Field(), err.Param()))
+		case "max":
+			messages = append(messages, fmt.Sprintf("%s must be at most %s characters long", err.Field(), err.Param()))
+		case "oneof":
+			messages = append(messages, fmt.Sprintf("%s must be one of: %s", err.Field(), err.Param()))
 		default:
 			messages = append(messages, fmt.Sprintf("%s is not valid", err.Field()))
 		}
 	}
 	return messages
-}
\ No newline at end of file
+}